@@ -1,19 +1,137 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/ecetinerdem/starthub-backend/internal/app"
 	"github.com/ecetinerdem/starthub-backend/internal/database"
+	"github.com/ecetinerdem/starthub-backend/internal/images"
+	"github.com/ecetinerdem/starthub-backend/internal/mail"
+	"github.com/ecetinerdem/starthub-backend/internal/payments"
+	"github.com/ecetinerdem/starthub-backend/internal/ratelimit"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+	"github.com/ecetinerdem/starthub-backend/internal/storage"
 )
 
 func main() {
+	// `main migrate ...` manages the schema instead of starting the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrateCommand(os.Args[2:])
+		return
+	}
+
 	db := database.ConnectDB()
 	database.RunMigrations(db)
-	app := app.Init(db)
+
+	blobs, err := storage.FromEnv()
+	if err != nil {
+		log.Fatalf("❌ Could not set up upload storage: %v", err)
+	}
+
+	mailer, err := mail.FromEnv()
+	if err != nil {
+		log.Fatalf("❌ Could not set up email: %v", err)
+	}
+
+	limits, err := ratelimit.FromEnv(db)
+	if err != nil {
+		log.Fatalf("❌ Could not set up rate limiting: %v", err)
+	}
+
+	app := app.Init(app.Services{
+		Repos:      repository.NewPostgres(db),
+		Images:     images.FromEnv(),
+		Blobs:      blobs,
+		Payments:   payments.FromEnv(),
+		Mailer:     mailer,
+		RateLimits: limits,
+	})
 
 	PORT := os.Getenv("PORT")
 
 	log.Fatal(app.Listen(":" + PORT))
 }
+
+const migrateUsage = `usage: main migrate <command>
+
+commands:
+  up              apply all pending migrations
+  down [N]        revert the last N applied migrations (default 1)
+  status          list migrations and whether they are applied
+  create <name>   write a new empty up/down migration pair`
+
+func runMigrateCommand(args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	// Creating files doesn't need a database connection
+	if args[0] == "create" {
+		if len(args) != 2 {
+			log.Fatal(migrateUsage)
+		}
+
+		upPath, downPath, err := database.CreateMigration(database.MigrationsDir, args[1])
+		if err != nil {
+			log.Fatalf("❌ Could not create migration: %v", err)
+		}
+
+		fmt.Printf("✅ Created %s\n✅ Created %s\n", upPath, downPath)
+		return
+	}
+
+	db := database.ConnectDB()
+	defer db.Close()
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateUp(ctx, db)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		for _, m := range applied {
+			fmt.Printf("⬆️  %04d_%s\n", m.Version, m.Name)
+		}
+		fmt.Printf("✅ Applied %d migration(s)\n", len(applied))
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				log.Fatal("down takes a positive number of migrations to revert")
+			}
+			steps = n
+		}
+
+		reverted, err := database.MigrateDown(ctx, db, steps)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		for _, m := range reverted {
+			fmt.Printf("⬇️  %04d_%s\n", m.Version, m.Name)
+		}
+		fmt.Printf("✅ Reverted %d migration(s)\n", len(reverted))
+
+	case "status":
+		statuses, err := database.MigrationStatuses(ctx, db)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", s.Version, s.Name, state)
+		}
+
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
@@ -1,33 +1,66 @@
 package app
 
 import (
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/images"
+	"github.com/ecetinerdem/starthub-backend/internal/mail"
+	"github.com/ecetinerdem/starthub-backend/internal/payments"
+	"github.com/ecetinerdem/starthub-backend/internal/ratelimit"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+	"github.com/ecetinerdem/starthub-backend/internal/storage"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 )
 
-func Init(db *pgxpool.Pool) *fiber.App {
+// Services are what the app is built on. Tests swap them for the in-memory
+// store and fakes.
+type Services struct {
+	Repos    *repository.Repositories
+	Images   images.Provider
+	Blobs    storage.BlobStore
+	Payments payments.Provider
+	Mailer   mail.Mailer
 
-	app := fiber.New()
+	// RateLimits keeps the buckets of the auth routes, nothing is rate limited without it
+	RateLimits ratelimit.Store
+}
+
+// Init builds the app on top of the given services, so it can run against
+// Postgres or the in-memory store
+func Init(services Services) *fiber.App {
+
+	// Every error returned by a handler is rendered as a problem+json response.
+	// Bodies may hold an image upload plus the multipart overhead.
+	app := fiber.New(fiber.Config{
+		ErrorHandler: apperrors.Handler,
+		BodyLimit:    images.MaxUploadBytes + 1<<20,
+	})
 
 	// 1. CORS MUST come FIRST (before any routes)
 	// This tells the browser: "Hey, it's okay for websites to call my API"
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     "*", // Allow requests from ANY website (good for development)
 		AllowMethods:     "GET,POST,HEAD,PUT,DELETE,PATCH,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-Requested-With",
+		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-Requested-With,If-Match,If-None-Match",
 		AllowCredentials: false, // We don't need cookies for now
+		ExposeHeaders:    "X-Request-ID,ETag,Retry-After",
 	}))
 
+	// Tag every request with an ID, it is sent back in X-Request-ID and in error bodies
+	app.Use(requestid.New())
+
 	// 2. Logger is optional but helpful - shows you what requests are coming in
-	app.Use(logger.New())
+	app.Use(logger.New(logger.Config{
+		Format: "[${time}] ${locals:requestid} ${status} - ${latency} ${method} ${path}\n",
+	}))
 
 	app.Get("/", func(c *fiber.Ctx) error {
 		return c.SendString("Hello World")
 	})
 
-	setupRoutes(app, db)
+	setupRoutes(app, services)
 
 	return app
 }
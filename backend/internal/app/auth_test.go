@@ -0,0 +1,156 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+)
+
+func TestSignUp(t *testing.T) {
+	s := newTestServer(t)
+	taken := s.signUp(models.RoleInvestor).User.Email
+
+	tests := []struct {
+		name      string
+		body      map[string]string
+		status    int
+		wantField string
+	}{
+		{
+			name:   "valid",
+			body:   map[string]string{"email": uniqueEmail("new"), "password": "password123", "role": "starthub"},
+			status: http.StatusCreated,
+		},
+		{
+			name:   "duplicate email",
+			body:   map[string]string{"email": taken, "password": "password123", "role": "starthub"},
+			status: http.StatusConflict,
+		},
+		{
+			name:      "missing email",
+			body:      map[string]string{"password": "password123", "role": "starthub"},
+			status:    http.StatusBadRequest,
+			wantField: "email",
+		},
+		{
+			name:      "invalid email",
+			body:      map[string]string{"email": "not-an-email", "password": "password123", "role": "starthub"},
+			status:    http.StatusBadRequest,
+			wantField: "email",
+		},
+		{
+			name:      "short password",
+			body:      map[string]string{"email": uniqueEmail("short"), "password": "123", "role": "starthub"},
+			status:    http.StatusBadRequest,
+			wantField: "password",
+		},
+		{
+			name:      "admin can't be picked",
+			body:      map[string]string{"email": uniqueEmail("admin"), "password": "password123", "role": "admin"},
+			status:    http.StatusBadRequest,
+			wantField: "role",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := s.sub(t)
+
+			if tt.status == http.StatusCreated {
+				var auth models.AuthResponse
+				s.expect(tt.status, "POST", "/sign-up", tt.body, "", &auth)
+
+				if auth.Token == "" || auth.RefreshToken == "" {
+					t.Errorf("sign-up returned no tokens: %+v", auth)
+				}
+				if auth.User.Email != tt.body["email"] || auth.User.Role != tt.body["role"] {
+					t.Errorf("sign-up returned user %+v", auth.User)
+				}
+				return
+			}
+
+			var p problem
+			s.expect(tt.status, "POST", "/sign-up", tt.body, "", &p)
+
+			if tt.wantField != "" && (len(p.Errors) != 1 || p.Errors[0].Field != tt.wantField) {
+				t.Errorf("got field errors %+v, want one for %q", p.Errors, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestSignIn(t *testing.T) {
+	s := newTestServer(t)
+	email := s.signUp(models.RoleStartHub).User.Email
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		status   int
+	}{
+		{"valid", email, "password123", http.StatusOK},
+		{"wrong password", email, "wrong-password", http.StatusUnauthorized},
+		{"unknown email", uniqueEmail("nobody"), "password123", http.StatusUnauthorized},
+		{"invalid email", "nope", "password123", http.StatusBadRequest},
+		{"missing password", email, "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := s.sub(t)
+
+			body := models.LoginRequest{Email: tt.email, Password: tt.password}
+			s.expect(tt.status, "POST", "/sign-in", body, "", nil)
+		})
+	}
+
+	t.Run("token opens protected routes", func(t *testing.T) {
+		s := s.sub(t)
+
+		token := s.signIn(email, "password123")
+		s.createStartHub(token, models.CreateStartHubRequest{Name: "Signed In"})
+	})
+}
+
+func TestProtectedRoutesRequireToken(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{"no header", ""},
+		{"not bearer", "Basic abc"},
+		{"bad token", "Bearer not-a-jwt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/starthubs", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+
+			resp, err := s.app.Test(req, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("got status %d, want 401", resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestLogoutRevokesToken(t *testing.T) {
+	s := newTestServer(t)
+	token := s.signUp(models.RoleStartHub).Token
+
+	s.expect(http.StatusOK, "POST", "/auth/logout", nil, token, nil)
+	s.expect(http.StatusUnauthorized, "POST", "/api/starthubs", models.CreateStartHubRequest{Name: "Late"}, token, nil)
+}
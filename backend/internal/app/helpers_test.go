@@ -0,0 +1,270 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ecetinerdem/starthub-backend/internal/database"
+	"github.com/ecetinerdem/starthub-backend/internal/images"
+	"github.com/ecetinerdem/starthub-backend/internal/mail/mailtest"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/internal/payments"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+	"github.com/ecetinerdem/starthub-backend/internal/storage"
+	"github.com/ecetinerdem/starthub-backend/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestMain(m *testing.M) {
+	utils.JWT_SECRET = "test-secret"
+
+	os.Exit(m.Run())
+}
+
+// testServer is the app under test along with helpers to call it
+type testServer struct {
+	t    *testing.T
+	app  *fiber.App
+	mail *mailtest.Recorder // the emails the app sent
+}
+
+// newTestServer boots the app on a fresh in-memory store. When TEST_DATABASE_URL
+// is set it runs against Postgres instead, in a throwaway schema that is dropped
+// when the test ends. Images are placeholders, tests never call out.
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	return newTestServerWith(t, Services{Images: images.Placeholder{}})
+}
+
+// testUploadsURL is the public address of uploads in tests
+const testUploadsURL = "http://starthub.test/uploads"
+
+// newTestServerWith boots the app on the given services, filling in the
+// repositories, blob store, a fake payment provider and a recording mailer when
+// they are not set
+func newTestServerWith(t *testing.T, services Services) *testServer {
+	t.Helper()
+
+	if services.Blobs == nil {
+		services.Blobs = &storage.Local{Dir: t.TempDir(), BaseURL: testUploadsURL}
+	}
+	if services.Payments == nil {
+		services.Payments = &payments.Fake{}
+	}
+	recorder := &mailtest.Recorder{}
+	if services.Mailer == nil {
+		services.Mailer = recorder
+	}
+	if services.Repos == nil {
+		services.Repos = repository.NewMemory()
+		if url := os.Getenv("TEST_DATABASE_URL"); url != "" {
+			services.Repos = repository.NewPostgres(newTestDatabase(t, url))
+		}
+	}
+
+	return &testServer{t: t, app: Init(services), mail: recorder}
+}
+
+// sub returns the server for use inside the subtest t
+func (s *testServer) sub(t *testing.T) *testServer {
+	return &testServer{t: t, app: s.app, mail: s.mail}
+}
+
+// newTestDatabase creates an empty schema, migrates it and returns a pool that
+// only sees that schema
+func newTestDatabase(t *testing.T, url string) *pgxpool.Pool {
+	t.Helper()
+	ctx := context.Background()
+
+	suffix := make([]byte, 6)
+	rand.Read(suffix)
+	schema := "test_" + hex.EncodeToString(suffix)
+
+	admin, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatalf("could not connect to test database: %v", err)
+	}
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("could not create schema: %v", err)
+	}
+
+	config, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		t.Fatalf("invalid TEST_DATABASE_URL: %v", err)
+	}
+	config.ConnConfig.RuntimeParams["search_path"] = schema + ",public"
+
+	db, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		t.Fatalf("could not connect to test schema: %v", err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+		if _, err := admin.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			t.Logf("could not drop schema %s: %v", schema, err)
+		}
+		admin.Close()
+	})
+
+	if _, err := database.MigrateUp(ctx, db); err != nil {
+		t.Fatalf("could not migrate test schema: %v", err)
+	}
+
+	return db
+}
+
+// do sends a request with an optional JSON body and bearer token
+func (s *testServer) do(method, path string, body any, token string) *http.Response {
+	s.t.Helper()
+	return s.doWithHeaders(method, path, body, token, nil)
+}
+
+// doWithHeaders is do with extra request headers
+func (s *testServer) doWithHeaders(method, path string, body any, token string, header http.Header) *http.Response {
+	s.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatalf("could not encode request body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if token != "" {
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := s.app.Test(req, -1)
+	if err != nil {
+		s.t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	s.t.Cleanup(func() { resp.Body.Close() })
+
+	return resp
+}
+
+// expect sends a request, fails the test unless it gets the wanted status and
+// decodes the response body into out when it is not nil
+func (s *testServer) expect(status int, method, path string, body any, token string, out any) {
+	s.t.Helper()
+
+	resp := s.do(method, path, body, token)
+	data, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != status {
+		s.t.Fatalf("%s %s: got status %d, want %d\n%s", method, path, resp.StatusCode, status, data)
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			s.t.Fatalf("%s %s: could not decode response: %v\n%s", method, path, err, data)
+		}
+	}
+}
+
+// problem is the error body rendered by apperrors.Handler
+type problem struct {
+	Status int    `json:"status"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+	Errors []struct {
+		Field   string `json:"field"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// uniqueEmail returns an email no other test uses, so tests can share a database
+func uniqueEmail(name string) string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%s@example.com", name, hex.EncodeToString(suffix))
+}
+
+// signUp registers a user with the role, verifies their email and returns the
+// sign-up response
+func (s *testServer) signUp(role string) models.AuthResponse {
+	s.t.Helper()
+
+	auth := s.signUpUnverified(role)
+	s.expect(http.StatusOK, "POST", "/auth/verify-email", models.VerifyEmailRequest{Token: s.mailedCode(auth.User.Email, "Verification code: ")}, "", nil)
+
+	return auth
+}
+
+// signUpUnverified registers a user with the role and leaves the verification
+// email unopened
+func (s *testServer) signUpUnverified(role string) models.AuthResponse {
+	s.t.Helper()
+
+	var auth models.AuthResponse
+	s.expect(http.StatusCreated, "POST", "/sign-up", models.RegisterUserRequest{
+		Email:    uniqueEmail(role),
+		Password: "password123",
+		Role:     role,
+	}, "", &auth)
+
+	return auth
+}
+
+// mailedCode returns the code written after label in the latest email sent to
+// the address
+func (s *testServer) mailedCode(email, label string) string {
+	s.t.Helper()
+
+	msg, ok := s.mail.Last(email)
+	if !ok {
+		s.t.Fatalf("no email was sent to %s", email)
+	}
+	_, rest, found := strings.Cut(msg.Body, label)
+	if !found {
+		s.t.Fatalf("email to %s has no %q:\n%s", email, label, msg.Body)
+	}
+	code, _, _ := strings.Cut(rest, "\n")
+
+	return code
+}
+
+// signIn returns the access token of an existing user
+func (s *testServer) signIn(email, password string) string {
+	s.t.Helper()
+
+	var auth models.AuthResponse
+	s.expect(http.StatusOK, "POST", "/sign-in", models.LoginRequest{
+		Email:    email,
+		Password: password,
+	}, "", &auth)
+
+	return auth.Token
+}
+
+// createStartHub creates a starthub owned by the user of token
+func (s *testServer) createStartHub(token string, req models.CreateStartHubRequest) models.StartHub {
+	s.t.Helper()
+
+	if req.Email == "" {
+		req.Email = uniqueEmail("starthub")
+	}
+
+	var created models.StartHub
+	s.expect(http.StatusCreated, "POST", "/api/starthubs", req, token, &created)
+
+	return created
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/ecetinerdem/starthub-backend/internal/images"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/internal/ratelimit"
+)
+
+// expectRetryAfter sends a request that should be refused with 429 and returns
+// its Retry-After in seconds
+func (s *testServer) expectRetryAfter(method, path string, body any) int {
+	s.t.Helper()
+
+	resp := s.do(method, path, body, "")
+	if resp.StatusCode != http.StatusTooManyRequests {
+		s.t.Fatalf("%s %s: got status %d, want 429", method, path, resp.StatusCode)
+	}
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 1 {
+		s.t.Fatalf("%s %s: got Retry-After %q", method, path, resp.Header.Get("Retry-After"))
+	}
+
+	return seconds
+}
+
+func TestLockout(t *testing.T) {
+	s := newTestServer(t)
+	auth := s.signUp(models.RoleInvestor)
+	email := auth.User.Email
+	wrong := models.LoginRequest{Email: email, Password: "wrong-password"}
+
+	// Failures below the threshold are only counted, a success starts over
+	for range 4 {
+		s.expect(http.StatusUnauthorized, "POST", "/sign-in", wrong, "", nil)
+	}
+	s.signIn(email, "password123")
+	for range 4 {
+		s.expect(http.StatusUnauthorized, "POST", "/sign-in", wrong, "", nil)
+	}
+
+	// The next one locks the account, even the right password is refused
+	s.expect(http.StatusUnauthorized, "POST", "/sign-in", wrong, "", nil)
+	if seconds := s.expectRetryAfter("POST", "/sign-in", models.LoginRequest{Email: email, Password: "password123"}); seconds > 60 {
+		t.Errorf("got Retry-After %d for the first lockout, want at most a minute", seconds)
+	}
+
+	// Owners see the failed attempts, newest first
+	var attempts []models.LoginAttempt
+	s.expect(http.StatusOK, "GET", "/api/me/login-attempts?limit=6", nil, auth.Token, &attempts)
+	if len(attempts) != 6 || attempts[0].Reason != models.LoginLocked || attempts[1].Reason != models.LoginWrongPassword {
+		t.Fatalf("got attempts %+v", attempts)
+	}
+	s.expect(http.StatusOK, "GET", "/api/me/login-attempts", nil, auth.Token, &attempts)
+	if len(attempts) != 10 {
+		t.Errorf("got %d attempts, want 10", len(attempts))
+	}
+	s.expect(http.StatusBadRequest, "GET", "/api/me/login-attempts?limit=0", nil, auth.Token, nil)
+
+	// Resetting the password lifts the lockout
+	s.expect(http.StatusOK, "POST", "/auth/reset-password", models.ResetPasswordRequest{Token: s.forgotPassword(email), Password: "new-password"}, "", nil)
+	s.signIn(email, "new-password")
+}
+
+func TestRateLimits(t *testing.T) {
+	s := newTestServerWith(t, Services{Images: images.Placeholder{}, RateLimits: ratelimit.NewMemory()})
+
+	// Guesses at one account run out, whether it exists or not
+	guess := models.LoginRequest{Email: uniqueEmail("target"), Password: "guess"}
+	for range signInPerAccount.Burst {
+		s.expect(http.StatusUnauthorized, "POST", "/sign-in", guess, "", nil)
+	}
+	s.expectRetryAfter("POST", "/sign-in", guess)
+
+	// Another account still has its own bucket, until the address runs out too
+	for range signInPerIP.Burst - signInPerAccount.Burst - 1 {
+		s.expect(http.StatusUnauthorized, "POST", "/sign-in", models.LoginRequest{Email: uniqueEmail("other"), Password: "guess"}, "", nil)
+	}
+	s.expectRetryAfter("POST", "/sign-in", models.LoginRequest{Email: uniqueEmail("other"), Password: "guess"})
+
+	for range signUpPerIP.Burst {
+		s.signUpUnverified(models.RoleDonator)
+	}
+	s.expectRetryAfter("POST", "/sign-up", models.RegisterUserRequest{Email: uniqueEmail("late"), Password: "password123", Role: models.RoleDonator})
+}
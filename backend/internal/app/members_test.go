@@ -0,0 +1,171 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+)
+
+// invite has the owner invite a user with the role and returns the token
+// emailed to them. The inviter never sees it.
+func (s *testServer) invite(owner, starthubID, email, role string) string {
+	s.t.Helper()
+
+	var created map[string]any
+	s.expect(http.StatusCreated, "POST", "/api/starthubs/"+starthubID+"/invitations",
+		models.InvitationRequest{Email: email, Role: role}, owner, &created)
+	if _, ok := created["token"]; ok {
+		s.t.Fatalf("the inviter got the invitation token: %+v", created)
+	}
+
+	return s.mailedCode(email, "Invitation code: ")
+}
+
+func TestInvitations(t *testing.T) {
+	s := newTestServer(t)
+	owner := s.signUp(models.RoleStartHub).Token
+	invited := s.signUp(models.RoleInvestor)
+	other := s.signUp(models.RoleStartHub).Token
+	id := s.createStartHub(owner, models.CreateStartHubRequest{Name: "Team Co"}).ID
+	path := "/api/starthubs/" + id + "/invitations"
+
+	token := s.invite(owner, id, invited.User.Email, models.MemberEditor)
+	if msg, _ := s.mail.Last(invited.User.Email); msg.Subject != "Join Team Co on StartHub" || !strings.Contains(msg.Body, "as an editor") {
+		t.Errorf("got email %+v", msg)
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		body   models.InvitationRequest
+		status int
+	}{
+		{"not an owner", other, models.InvitationRequest{Email: uniqueEmail("x")}, http.StatusForbidden},
+		{"invalid email", owner, models.InvitationRequest{Email: "nope"}, http.StatusBadRequest},
+		{"unknown role", owner, models.InvitationRequest{Email: uniqueEmail("x"), Role: "admin"}, http.StatusBadRequest},
+		{"already invited", owner, models.InvitationRequest{Email: invited.User.Email}, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.sub(t).expect(tt.status, "POST", path, tt.body, tt.token, nil)
+		})
+	}
+
+	var pending []models.Invitation
+	s.expect(http.StatusOK, "GET", path, nil, owner, &pending)
+	if len(pending) != 1 || pending[0].Email != invited.User.Email || pending[0].Role != models.MemberEditor {
+		t.Fatalf("got pending invitations %+v", pending)
+	}
+
+	// Only the invited account can use the token, and only once
+	s.expect(http.StatusForbidden, "POST", "/api/invitations/accept", models.AcceptInvitationRequest{Token: token}, other, nil)
+	s.expect(http.StatusNotFound, "POST", "/api/invitations/accept", models.AcceptInvitationRequest{Token: "made-up"}, invited.Token, nil)
+
+	var accepted struct {
+		StartHubID string        `json:"starthub_id"`
+		Member     models.Member `json:"member"`
+	}
+	s.expect(http.StatusOK, "POST", "/api/invitations/accept", models.AcceptInvitationRequest{Token: token}, invited.Token, &accepted)
+	if accepted.StartHubID != id || accepted.Member.Role != models.MemberEditor || accepted.Member.UserID != invited.User.ID {
+		t.Errorf("got %+v", accepted)
+	}
+	s.expect(http.StatusNotFound, "POST", "/api/invitations/accept", models.AcceptInvitationRequest{Token: token}, invited.Token, nil)
+
+	// Members can't be invited again, revoked invitations can't be used
+	s.expect(http.StatusConflict, "POST", path, models.InvitationRequest{Email: invited.User.Email}, owner, nil)
+
+	late := s.signUp(models.RoleDonator)
+	var revoked models.Invitation
+	s.expect(http.StatusCreated, "POST", path, models.InvitationRequest{Email: late.User.Email}, owner, &revoked)
+	revokedToken := s.mailedCode(late.User.Email, "Invitation code: ")
+	s.expect(http.StatusOK, "DELETE", path+"/"+revoked.ID, nil, owner, nil)
+	s.expect(http.StatusNotFound, "POST", "/api/invitations/accept", models.AcceptInvitationRequest{Token: revokedToken}, late.Token, nil)
+}
+
+func TestInvitationNeedsVerifiedEmail(t *testing.T) {
+	s := newTestServer(t)
+	owner := s.signUp(models.RoleStartHub).Token
+	id := s.createStartHub(owner, models.CreateStartHubRequest{Name: "Verified Team Co"}).ID
+
+	// An account with the invited address only takes the seat once it proved it owns it
+	unverified := s.signUpUnverified(models.RoleInvestor)
+	verificationToken := s.mailedCode(unverified.User.Email, "Verification code: ")
+	token := s.invite(owner, id, unverified.User.Email, models.MemberViewer)
+
+	s.expect(http.StatusForbidden, "POST", "/api/invitations/accept", models.AcceptInvitationRequest{Token: token}, unverified.Token, nil)
+	s.expect(http.StatusOK, "POST", "/auth/verify-email", models.VerifyEmailRequest{Token: verificationToken}, "", nil)
+	s.expect(http.StatusOK, "POST", "/api/invitations/accept", models.AcceptInvitationRequest{Token: token}, unverified.Token, nil)
+}
+
+func TestTeamRoles(t *testing.T) {
+	s := newTestServer(t)
+	owner := s.signUp(models.RoleStartHub)
+	editor := s.signUp(models.RoleInvestor)
+	viewer := s.signUp(models.RoleCollaborator)
+	outsider := s.signUp(models.RoleStartHub)
+	id := s.createStartHub(owner.Token, models.CreateStartHubRequest{Name: "Roles Co"}).ID
+	path := "/api/starthubs/" + id
+
+	for _, member := range []struct {
+		auth models.AuthResponse
+		role string
+	}{{editor, models.MemberEditor}, {viewer, models.MemberViewer}} {
+		token := s.invite(owner.Token, id, member.auth.User.Email, member.role)
+		s.expect(http.StatusOK, "POST", "/api/invitations/accept", models.AcceptInvitationRequest{Token: token}, member.auth.Token, nil)
+	}
+
+	var team []models.Member
+	s.expect(http.StatusOK, "GET", path+"/members", nil, viewer.Token, &team)
+	if len(team) != 3 || team[0].UserID != owner.User.ID || team[0].Role != models.MemberOwner {
+		t.Fatalf("got team %+v", team)
+	}
+
+	patch := map[string]any{"description": "Edited by the team"}
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		path   string
+		body   any
+		status int
+	}{
+		{"editor edits, whatever their platform role", editor.Token, "PATCH", path, patch, http.StatusOK},
+		{"viewer can't edit", viewer.Token, "PATCH", path, patch, http.StatusForbidden},
+		{"outsider can't edit", outsider.Token, "PATCH", path, patch, http.StatusForbidden},
+		{"viewer sees collaborations", viewer.Token, "GET", path + "/collaborations", nil, http.StatusOK},
+		{"outsider can't see the team", outsider.Token, "GET", path + "/members", nil, http.StatusForbidden},
+		{"editor can't invite", editor.Token, "POST", path + "/invitations", models.InvitationRequest{Email: uniqueEmail("x")}, http.StatusForbidden},
+		{"editor can't change roles", editor.Token, "PUT", path + "/members/" + viewer.User.ID, models.MemberRoleRequest{Role: models.MemberEditor}, http.StatusForbidden},
+		{"editor can't delete", editor.Token, "DELETE", path, nil, http.StatusForbidden},
+		{"last owner can't step down", owner.Token, "PUT", path + "/members/" + owner.User.ID, models.MemberRoleRequest{Role: models.MemberEditor}, http.StatusConflict},
+		{"last owner can't leave", owner.Token, "DELETE", path + "/members/" + owner.User.ID, nil, http.StatusConflict},
+		{"unknown role", owner.Token, "PUT", path + "/members/" + viewer.User.ID, models.MemberRoleRequest{Role: "boss"}, http.StatusBadRequest},
+		{"transfer to someone off the team", owner.Token, "POST", path + "/transfer-ownership", models.TransferOwnershipRequest{UserID: outsider.User.ID}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.sub(t).expect(tt.status, tt.method, tt.path, tt.body, tt.token, nil)
+		})
+	}
+
+	// Handing the starthub over makes the old owner an editor
+	s.expect(http.StatusOK, "POST", path+"/transfer-ownership", models.TransferOwnershipRequest{UserID: editor.User.ID}, owner.Token, &team)
+	roles := map[string]string{}
+	for _, member := range team {
+		roles[member.UserID] = member.Role
+	}
+	if roles[editor.User.ID] != models.MemberOwner || roles[owner.User.ID] != models.MemberEditor {
+		t.Fatalf("got roles %v after the transfer", roles)
+	}
+	s.expect(http.StatusForbidden, "DELETE", path, nil, owner.Token, nil)
+
+	// Anyone can leave, only owners remove others
+	s.expect(http.StatusForbidden, "DELETE", path+"/members/"+owner.User.ID, nil, viewer.Token, nil)
+	s.expect(http.StatusOK, "DELETE", path+"/members/"+viewer.User.ID, nil, viewer.Token, nil)
+	s.expect(http.StatusForbidden, "GET", path+"/members", nil, viewer.Token, nil)
+
+	s.expect(http.StatusOK, "DELETE", path, nil, editor.Token, nil)
+	s.expect(http.StatusNotFound, "GET", "/starthubs/"+id, nil, "", nil)
+}
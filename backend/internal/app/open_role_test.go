@@ -0,0 +1,162 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+)
+
+func TestOpenRoles(t *testing.T) {
+	s := newTestServer(t)
+	owner := s.signUp(models.RoleStartHub).Token
+	outsider := s.signUp(models.RoleStartHub).Token
+	id := s.createStartHub(owner, models.CreateStartHubRequest{Name: "Hiring Co"}).ID
+	path := "/api/starthubs/" + id + "/roles"
+
+	tests := []struct {
+		name   string
+		token  string
+		body   models.OpenRoleRequest
+		status int
+	}{
+		{"not on the team", outsider, models.OpenRoleRequest{Title: "Backend", Remote: true, Commitment: models.CommitmentPartTime}, http.StatusForbidden},
+		{"no title", owner, models.OpenRoleRequest{Remote: true, Commitment: models.CommitmentPartTime}, http.StatusBadRequest},
+		{"unknown commitment", owner, models.OpenRoleRequest{Title: "Backend", Remote: true, Commitment: "sometimes"}, http.StatusBadRequest},
+		{"on site without location", owner, models.OpenRoleRequest{Title: "Backend", Commitment: models.CommitmentFullTime}, http.StatusBadRequest},
+		{"remote", owner, models.OpenRoleRequest{Title: "Backend", Skills: []string{" Go ", "go", "SQL"}, Remote: true, Commitment: models.CommitmentPartTime}, http.StatusCreated},
+		{"on site", owner, models.OpenRoleRequest{Title: "Designer", Skills: []string{"figma"}, Location: "Berlin", Commitment: models.CommitmentFullTime}, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.sub(t).expect(tt.status, "POST", path, tt.body, tt.token, nil)
+		})
+	}
+
+	// Skills are normalized so filters match them exactly
+	var roles []models.OpenRole
+	s.expect(http.StatusOK, "GET", "/roles?skill=GO&remote=true", nil, "", &roles)
+	if len(roles) != 1 || roles[0].Title != "Backend" || len(roles[0].Skills) != 2 || roles[0].StartHub.Name != "Hiring Co" {
+		t.Fatalf("got roles %+v", roles)
+	}
+
+	s.expect(http.StatusOK, "GET", "/roles?commitment=full_time", nil, "", &roles)
+	if len(roles) != 1 || roles[0].Title != "Designer" {
+		t.Fatalf("got full time roles %+v", roles)
+	}
+	designer := roles[0].ID
+	s.expect(http.StatusBadRequest, "GET", "/roles?remote=maybe", nil, "", nil)
+
+	s.expect(http.StatusOK, "GET", "/starthubs/"+id+"/roles", nil, "", &roles)
+	if len(roles) != 2 {
+		t.Errorf("got %d roles of the starthub, want 2", len(roles))
+	}
+
+	// Closed roles drop out of the lists and take no applications
+	s.expect(http.StatusForbidden, "POST", path+"/"+designer+"/close", nil, outsider, nil)
+	s.expect(http.StatusOK, "POST", path+"/"+designer+"/close", nil, owner, nil)
+	s.expect(http.StatusOK, "GET", "/roles", nil, "", &roles)
+	if len(roles) != 1 {
+		t.Errorf("got %d open roles after closing one, want 1", len(roles))
+	}
+
+	var role models.OpenRole
+	s.expect(http.StatusOK, "GET", "/roles/"+designer, nil, "", &role)
+	if role.Status != models.OpenRoleClosed {
+		t.Errorf("got status %q for a closed role", role.Status)
+	}
+	s.expect(http.StatusNotFound, "GET", "/roles/00000000-0000-0000-0000-000000000000", nil, "", nil)
+}
+
+func TestApplications(t *testing.T) {
+	s := newTestServer(t)
+	owner := s.signUp(models.RoleStartHub).Token
+	applicant := s.signUp(models.RoleCollaborator)
+	other := s.signUp(models.RoleCollaborator).Token
+	id := s.createStartHub(owner, models.CreateStartHubRequest{Name: "Hiring Co"}).ID
+
+	var role models.OpenRole
+	s.expect(http.StatusCreated, "POST", "/api/starthubs/"+id+"/roles",
+		models.OpenRoleRequest{Title: "Backend", Remote: true, Commitment: models.CommitmentContract}, owner, &role)
+	apply := "/api/roles/" + role.ID + "/applications"
+
+	tests := []struct {
+		name   string
+		token  string
+		path   string
+		body   models.ApplicationRequest
+		status int
+	}{
+		{"not a collaborator", owner, apply, models.ApplicationRequest{Name: "Owner"}, http.StatusForbidden},
+		{"no name", applicant.Token, apply, models.ApplicationRequest{Message: "Hi"}, http.StatusBadRequest},
+		{"bad url", applicant.Token, apply, models.ApplicationRequest{Name: "Ada", URL: "ftp://ada"}, http.StatusBadRequest},
+		{"unknown role", applicant.Token, "/api/roles/00000000-0000-0000-0000-000000000000/applications", models.ApplicationRequest{Name: "Ada"}, http.StatusNotFound},
+		{"applies", applicant.Token, apply, models.ApplicationRequest{Name: "Ada", URL: "https://ada.dev", Message: "I write Go"}, http.StatusCreated},
+		{"applies twice", applicant.Token, apply, models.ApplicationRequest{Name: "Ada"}, http.StatusConflict},
+		{"another applicant", other, apply, models.ApplicationRequest{Name: "Bob"}, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.sub(t).expect(tt.status, "POST", tt.path, tt.body, tt.token, nil)
+		})
+	}
+
+	// The team reviews pending applications with who sent them
+	var applications []models.Application
+	s.expect(http.StatusForbidden, "GET", "/api/starthubs/"+id+"/applications", nil, other, nil)
+	s.expect(http.StatusOK, "GET", "/api/starthubs/"+id+"/applications?status=pending", nil, owner, &applications)
+	if len(applications) != 2 {
+		t.Fatalf("got %d pending applications, want 2", len(applications))
+	}
+	byName := map[string]models.Application{}
+	for _, a := range applications {
+		byName[a.Name] = a
+	}
+	ada, bob := byName["Ada"], byName["Bob"]
+	if ada.ApplicantEmail != applicant.User.Email || ada.Role.Title != "Backend" {
+		t.Errorf("got application %+v", ada)
+	}
+
+	// Accepting adds the applicant as an external collaborator
+	decide := "/api/starthubs/" + id + "/applications/"
+	var accepted models.Application
+	s.expect(http.StatusOK, "POST", decide+ada.ID+"/accept", nil, owner, &accepted)
+	if accepted.Status != models.ApplicationAccepted || accepted.ExternalCollaboratorID == nil || accepted.DecidedAt == nil {
+		t.Fatalf("got accepted application %+v", accepted)
+	}
+	s.expect(http.StatusNotFound, "POST", decide+ada.ID+"/reject", nil, owner, nil)
+
+	var collaborators []models.ExternalCollaborator
+	s.expect(http.StatusOK, "GET", "/starthubs/"+id+"/external-collaborators", nil, "", &collaborators)
+	if len(collaborators) != 1 || collaborators[0].ID != *accepted.ExternalCollaboratorID ||
+		collaborators[0].Name != "Ada" || collaborators[0].URL != "https://ada.dev" || collaborators[0].Role != "Backend" {
+		t.Fatalf("got external collaborators %+v", collaborators)
+	}
+
+	// Applicants can withdraw while pending, and then apply again
+	s.expect(http.StatusNotFound, "DELETE", "/api/applications/"+bob.ID, nil, applicant.Token, nil)
+	s.expect(http.StatusOK, "DELETE", "/api/applications/"+bob.ID, nil, other, nil)
+	s.expect(http.StatusNotFound, "POST", decide+bob.ID+"/reject", nil, owner, nil)
+	s.expect(http.StatusCreated, "POST", apply, models.ApplicationRequest{Name: "Bob"}, other, nil)
+
+	s.expect(http.StatusOK, "GET", "/api/applications", nil, other, &applications)
+	byStatus := map[string]models.Application{}
+	for _, a := range applications {
+		byStatus[a.Status] = a
+	}
+	pending, ok := byStatus[models.ApplicationPending]
+	if len(applications) != 2 || !ok || byStatus[models.ApplicationWithdrawn].ID != bob.ID {
+		t.Fatalf("got my applications %+v", applications)
+	}
+
+	var rejected models.Application
+	s.expect(http.StatusOK, "POST", decide+pending.ID+"/reject", nil, owner, &rejected)
+	if rejected.Status != models.ApplicationRejected || rejected.ExternalCollaboratorID != nil {
+		t.Errorf("got rejected application %+v", rejected)
+	}
+
+	// Closed roles take no new applications
+	third := s.signUp(models.RoleCollaborator).Token
+	s.expect(http.StatusOK, "POST", "/api/starthubs/"+id+"/roles/"+role.ID+"/close", nil, owner, nil)
+	s.expect(http.StatusNotFound, "POST", apply, models.ApplicationRequest{Name: "Late"}, third, nil)
+}
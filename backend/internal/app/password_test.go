@@ -0,0 +1,100 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+)
+
+// forgotPassword asks for a reset email for the address and returns its token.
+// The email is sent after the request is answered, so this waits for it.
+func (s *testServer) forgotPassword(email string) string {
+	s.t.Helper()
+
+	sent := len(s.mail.Messages(email))
+	s.expect(http.StatusAccepted, "POST", "/auth/forgot-password", models.ForgotPasswordRequest{Email: email}, "", nil)
+	if len(s.mail.WaitFor(email, sent+1, 2*time.Second)) == sent {
+		s.t.Fatalf("no reset email was sent to %s", email)
+	}
+
+	return s.mailedCode(email, "Reset code: ")
+}
+
+func TestChangePassword(t *testing.T) {
+	s := newTestServer(t)
+	auth := s.signUp(models.RoleDonator)
+	otherDevice := s.signIn(auth.User.Email, "password123")
+
+	tests := []struct {
+		name   string
+		body   models.ChangePasswordRequest
+		status int
+	}{
+		{"wrong current password", models.ChangePasswordRequest{CurrentPassword: "wrong-password", NewPassword: "new-password"}, http.StatusBadRequest},
+		{"too short", models.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "abc"}, http.StatusBadRequest},
+		{"too long", models.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: strings.Repeat("a", 73)}, http.StatusBadRequest},
+		{"changed", models.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "new-password"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.sub(t).expect(tt.status, "POST", "/api/me/password", tt.body, auth.Token, nil)
+		})
+	}
+
+	// This device stays signed in, the others are signed out
+	s.expect(http.StatusOK, "GET", "/api/me", nil, auth.Token, nil)
+	s.expect(http.StatusUnauthorized, "GET", "/api/me", nil, otherDevice, nil)
+
+	s.expect(http.StatusUnauthorized, "POST", "/sign-in", models.LoginRequest{Email: auth.User.Email, Password: "password123"}, "", nil)
+	s.signIn(auth.User.Email, "new-password")
+
+	if msg, ok := s.mail.Last(auth.User.Email); !ok || !strings.Contains(msg.Subject, "password was changed") {
+		t.Errorf("got email %+v", msg)
+	}
+}
+
+func TestPasswordReset(t *testing.T) {
+	s := newTestServer(t)
+	auth := s.signUp(models.RoleInvestor)
+	email := auth.User.Email
+
+	// Unknown emails get the same answer, and no email
+	s.expect(http.StatusAccepted, "POST", "/auth/forgot-password", models.ForgotPasswordRequest{Email: "nobody@example.com"}, "", nil)
+	token := s.forgotPassword(email)
+	if len(s.mail.Messages("nobody@example.com")) != 0 {
+		t.Fatal("an email was sent to an address without an account")
+	}
+
+	// Asking again right away gets the same answer, but no second email
+	sent := len(s.mail.Messages(email))
+	s.expect(http.StatusAccepted, "POST", "/auth/forgot-password", models.ForgotPasswordRequest{Email: email}, "", nil)
+	if n := len(s.mail.WaitFor(email, sent+1, 200*time.Millisecond)); n != sent {
+		t.Fatalf("got %d emails, want %d, reset emails to one account are throttled", n, sent)
+	}
+
+	tests := []struct {
+		name   string
+		body   models.ResetPasswordRequest
+		status int
+	}{
+		{"no token", models.ResetPasswordRequest{Password: "new-password"}, http.StatusBadRequest},
+		{"unknown token", models.ResetPasswordRequest{Token: "not-a-token", Password: "new-password"}, http.StatusBadRequest},
+		{"too short", models.ResetPasswordRequest{Token: token, Password: "abc"}, http.StatusBadRequest},
+		{"reset", models.ResetPasswordRequest{Token: token, Password: "new-password"}, http.StatusOK},
+		{"used twice", models.ResetPasswordRequest{Token: token, Password: "other-password"}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.sub(t).expect(tt.status, "POST", "/auth/reset-password", tt.body, "", nil)
+		})
+	}
+
+	// Every session is signed out, whoever knew the old password included
+	s.expect(http.StatusUnauthorized, "GET", "/api/me", nil, auth.Token, nil)
+	s.expect(http.StatusUnauthorized, "POST", "/auth/refresh", models.RefreshRequest{RefreshToken: auth.RefreshToken}, "", nil)
+	s.expect(http.StatusUnauthorized, "POST", "/sign-in", models.LoginRequest{Email: email, Password: "password123"}, "", nil)
+	s.signIn(email, "new-password")
+}
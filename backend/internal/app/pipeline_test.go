@@ -0,0 +1,93 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+)
+
+// optional returns a pointer for the optional fields of a pipeline request
+func optional(text string) *string {
+	return &text
+}
+
+func TestInvestorPipeline(t *testing.T) {
+	s := newTestServer(t)
+	owner := s.signUp(models.RoleStartHub).Token
+	investor := s.signUp(models.RoleInvestor).Token
+	rival := s.signUp(models.RoleInvestor).Token
+	first := s.createStartHub(owner, models.CreateStartHubRequest{Name: "Deal One"}).ID
+	second := s.createStartHub(owner, models.CreateStartHubRequest{Name: "Deal Two"}).ID
+
+	tests := []struct {
+		name   string
+		token  string
+		path   string
+		body   models.PipelineRequest
+		status int
+	}{
+		{"not an investor", owner, first, models.PipelineRequest{}, http.StatusForbidden},
+		{"unknown starthub", investor, "00000000-0000-0000-0000-000000000000", models.PipelineRequest{}, http.StatusNotFound},
+		{"unknown stage", investor, first, models.PipelineRequest{Stage: optional("hot")}, http.StatusBadRequest},
+		{"new entry", investor, first, models.PipelineRequest{Notes: optional("Strong team")}, http.StatusCreated},
+		{"move along", investor, first, models.PipelineRequest{Stage: optional(models.StageDueDiligence), Notes: optional("Asked for the deck")}, http.StatusOK},
+		{"another starthub", investor, second, models.PipelineRequest{Stage: optional(models.StagePassed)}, http.StatusCreated},
+		{"another investor", rival, first, models.PipelineRequest{Stage: optional(models.StageContacted)}, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.sub(t).expect(tt.status, "PUT", "/api/pipeline/"+tt.path, tt.body, tt.token, nil)
+		})
+	}
+
+	// Each investor only sees their own pipeline
+	var pipeline []models.PipelineEntry
+	s.expect(http.StatusOK, "GET", "/api/pipeline", nil, investor, &pipeline)
+	if len(pipeline) != 2 || pipeline[0].StartHub.ID != second || pipeline[1].Stage != models.StageDueDiligence || pipeline[1].Notes != "Asked for the deck" {
+		t.Fatalf("got pipeline %+v", pipeline)
+	}
+
+	// Moving on without notes keeps them, empty notes clear them
+	var entry models.PipelineEntry
+	s.expect(http.StatusOK, "PUT", "/api/pipeline/"+second, models.PipelineRequest{Stage: optional(models.StageWatching), Notes: optional("Revisit next year")}, investor, nil)
+	s.expect(http.StatusOK, "PUT", "/api/pipeline/"+second, models.PipelineRequest{Stage: optional(models.StagePassed)}, investor, &entry)
+	if entry.Stage != models.StagePassed || entry.Notes != "Revisit next year" {
+		t.Errorf("got entry %+v after moving it without notes", entry)
+	}
+	s.expect(http.StatusOK, "PUT", "/api/pipeline/"+second, models.PipelineRequest{Stage: optional(models.StagePassed), Notes: optional("")}, investor, &entry)
+	if entry.Notes != "" {
+		t.Errorf("got notes %q after clearing them", entry.Notes)
+	}
+
+	// Updating only the notes keeps the stage
+	s.expect(http.StatusOK, "PUT", "/api/pipeline/"+second, models.PipelineRequest{Notes: optional("Met them again")}, investor, &entry)
+	if entry.Stage != models.StagePassed || entry.Notes != "Met them again" {
+		t.Errorf("got entry %+v after updating only the notes", entry)
+	}
+
+	s.expect(http.StatusOK, "GET", "/api/pipeline?stage=due_diligence,contacted", nil, investor, &pipeline)
+	if len(pipeline) != 1 || pipeline[0].StartHub.Name != "Deal One" {
+		t.Errorf("got filtered pipeline %+v", pipeline)
+	}
+	s.expect(http.StatusBadRequest, "GET", "/api/pipeline?stage=hot", nil, investor, nil)
+
+	// The team only sees a count, and passing doesn't count as interest
+	var summary models.InterestSummary
+	s.expect(http.StatusOK, "GET", "/api/starthubs/"+first+"/interest", nil, owner, &summary)
+	if summary.InterestedInvestors != 2 {
+		t.Errorf("got %d interested investors, want 2", summary.InterestedInvestors)
+	}
+	s.expect(http.StatusOK, "GET", "/api/starthubs/"+second+"/interest", nil, owner, &summary)
+	if summary.InterestedInvestors != 0 {
+		t.Errorf("got %d interested investors in a passed deal, want 0", summary.InterestedInvestors)
+	}
+	s.expect(http.StatusForbidden, "GET", "/api/starthubs/"+first+"/interest", nil, investor, nil)
+
+	s.expect(http.StatusOK, "DELETE", "/api/pipeline/"+first, nil, rival, nil)
+	s.expect(http.StatusNotFound, "DELETE", "/api/pipeline/"+first, nil, rival, nil)
+	s.expect(http.StatusOK, "GET", "/api/starthubs/"+first+"/interest", nil, owner, &summary)
+	if summary.InterestedInvestors != 1 {
+		t.Errorf("got %d interested investors after one left, want 1", summary.InterestedInvestors)
+	}
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/ecetinerdem/starthub-backend/internal/images"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/internal/payments"
+)
+
+func TestPledges(t *testing.T) {
+	provider := &payments.Fake{}
+	s := newTestServerWith(t, Services{Images: images.Placeholder{}, Payments: provider})
+	owner := s.signUp(models.RoleStartHub).Token
+	donator := s.signUp(models.RoleDonator).Token
+	other := s.signUp(models.RoleDonator).Token
+	id := s.createStartHub(owner, models.CreateStartHubRequest{Name: "Fund Co"}).ID
+	path := "/api/starthubs/" + id
+
+	// Only the team publishes goals
+	goalRequest := models.FundingGoalRequest{Title: "Prototype", Amount: 500000, Currency: "eur", Deadline: "2999-01-01"}
+	s.expect(http.StatusForbidden, "POST", path+"/goals", goalRequest, donator, nil)
+	s.expect(http.StatusBadRequest, "POST", path+"/goals", models.FundingGoalRequest{Title: "Past", Amount: 1, Currency: "EUR", Deadline: "2000-01-01"}, owner, nil)
+
+	var goal models.FundingGoal
+	s.expect(http.StatusCreated, "POST", path+"/goals", goalRequest, owner, &goal)
+	if goal.Currency != "EUR" || goal.Deadline == nil {
+		t.Fatalf("got goal %+v", goal)
+	}
+
+	otherGoal := 999
+	tests := []struct {
+		name   string
+		token  string
+		body   models.PledgeRequest
+		status int
+	}{
+		{"not a donator", owner, models.PledgeRequest{Amount: 1000, Currency: "EUR", PaymentMethod: "card"}, http.StatusForbidden},
+		{"zero amount", donator, models.PledgeRequest{Amount: 0, Currency: "EUR", PaymentMethod: "card"}, http.StatusBadRequest},
+		{"bad currency", donator, models.PledgeRequest{Amount: 1000, Currency: "euro", PaymentMethod: "card"}, http.StatusBadRequest},
+		{"no payment method", donator, models.PledgeRequest{Amount: 1000, Currency: "EUR"}, http.StatusBadRequest},
+		{"unknown goal", donator, models.PledgeRequest{Amount: 1000, Currency: "EUR", GoalID: &otherGoal, PaymentMethod: "card"}, http.StatusNotFound},
+		{"goal in another currency", donator, models.PledgeRequest{Amount: 1000, Currency: "USD", GoalID: &goal.ID, PaymentMethod: "card"}, http.StatusBadRequest},
+		{"declined", donator, models.PledgeRequest{Amount: 1000, Currency: "EUR", PaymentMethod: payments.DeclinedPrefix + "_card"}, http.StatusPaymentRequired},
+		{"to the goal", donator, models.PledgeRequest{Amount: 150000, Currency: "EUR", GoalID: &goal.ID, PaymentMethod: "card"}, http.StatusCreated},
+		{"in dollars", donator, models.PledgeRequest{Amount: 2500, Currency: "usd", PaymentMethod: "card"}, http.StatusCreated},
+		{"another donator", other, models.PledgeRequest{Amount: 50000, Currency: "EUR", GoalID: &goal.ID, PaymentMethod: "card"}, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.sub(t).expect(tt.status, "POST", path+"/pledges", tt.body, tt.token, nil)
+		})
+	}
+
+	if charges := provider.Charges(); len(charges) != 3 {
+		t.Errorf("got %d charges, want 3", len(charges))
+	}
+
+	// Declined pledges stay in the history but don't count
+	var history []models.Pledge
+	s.expect(http.StatusOK, "GET", "/api/pledges", nil, donator, &history)
+	statuses := map[string]int{}
+	for _, p := range history {
+		statuses[p.Status]++
+		if p.StartHub.Name != "Fund Co" {
+			t.Errorf("pledge %s has starthub %+v", p.ID, p.StartHub)
+		}
+	}
+	if len(history) != 3 || statuses[models.PledgePaid] != 2 || statuses[models.PledgeFailed] != 1 {
+		t.Fatalf("got history %+v", history)
+	}
+
+	var progress models.FundingProgress
+	s.expect(http.StatusOK, "GET", "/starthubs/"+id+"/funding", nil, "", &progress)
+	want := []models.FundingTotal{{Currency: "EUR", Amount: 200000, Pledges: 2}, {Currency: "USD", Amount: 2500, Pledges: 1}}
+	if progress.Donators != 2 || len(progress.Totals) != 2 || progress.Totals[0] != want[0] || progress.Totals[1] != want[1] {
+		t.Errorf("got progress %+v", progress)
+	}
+	if len(progress.Goals) != 1 || progress.Goals[0].Raised != 200000 {
+		t.Errorf("got goals %+v", progress.Goals)
+	}
+
+	// Taking a goal down keeps its pledges
+	s.expect(http.StatusOK, "DELETE", path+"/goals/"+strconv.Itoa(goal.ID), nil, owner, nil)
+	s.expect(http.StatusOK, "GET", "/starthubs/"+id+"/funding", nil, "", &progress)
+	if len(progress.Goals) != 0 || progress.Totals[0].Amount != 200000 {
+		t.Errorf("got progress %+v after deleting the goal", progress)
+	}
+	s.expect(http.StatusNotFound, "GET", "/starthubs/00000000-0000-0000-0000-000000000000/funding", nil, "", nil)
+
+	// Pledges are payment records, they stay in the history of the donator
+	s.expect(http.StatusOK, "DELETE", "/api/starthubs/"+id, nil, owner, nil)
+	s.expect(http.StatusOK, "GET", "/api/pledges", nil, donator, &history)
+	if len(history) != 3 || history[0].StartHub.ID != "" || history[0].StartHub.Name != "Fund Co" {
+		t.Errorf("got history %+v after deleting the starthub", history)
+	}
+}
+
+func TestPledgesWithoutPayments(t *testing.T) {
+	s := newTestServerWith(t, Services{Images: images.Placeholder{}, Payments: payments.Disabled{}})
+	owner := s.signUp(models.RoleStartHub).Token
+	donator := s.signUp(models.RoleDonator).Token
+	id := s.createStartHub(owner, models.CreateStartHubRequest{Name: "Unpaid Co"}).ID
+
+	s.expect(http.StatusServiceUnavailable, "POST", "/api/starthubs/"+id+"/pledges",
+		models.PledgeRequest{Amount: 1000, Currency: "EUR", PaymentMethod: "card"}, donator, nil)
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+)
+
+func TestMe(t *testing.T) {
+	s := newTestServer(t)
+	auth := s.signUp(models.RoleInvestor)
+
+	// New accounts start with an empty, public profile
+	var me models.UserResponse
+	s.expect(http.StatusOK, "GET", "/api/me", nil, auth.Token, &me)
+	if me.Email != auth.User.Email || !me.Settings.PublicProfile || me.Profile.Links == nil {
+		t.Fatalf("got %+v", me)
+	}
+	s.expect(http.StatusUnauthorized, "GET", "/api/me", nil, "", nil)
+
+	tests := []struct {
+		name   string
+		patch  map[string]any
+		status int
+	}{
+		{"unknown field", map[string]any{"nickname": "ada"}, http.StatusBadRequest},
+		{"read-only field", map[string]any{"email": "new@example.com"}, http.StatusBadRequest},
+		{"wrong type", map[string]any{"public_profile": "yes"}, http.StatusBadRequest},
+		{"bad avatar", map[string]any{"avatar_url": "not a url"}, http.StatusBadRequest},
+		{"bad link", map[string]any{"links": []string{"https://ada.dev", "javascript:alert(1)"}}, http.StatusBadRequest},
+		{"profile", map[string]any{"display_name": " Ada ", "bio": "Angel investor", "links": []string{"https://ada.dev", "https://ada.dev"}}, http.StatusOK},
+		{"location only", map[string]any{"location": "London"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.sub(t).expect(tt.status, "PATCH", "/api/me", tt.patch, auth.Token, nil)
+		})
+	}
+
+	// Fields left out of a patch keep their value
+	s.expect(http.StatusOK, "GET", "/api/me", nil, auth.Token, &me)
+	want := models.Profile{DisplayName: "Ada", Bio: "Angel investor", Location: "London", Links: []string{"https://ada.dev"}}
+	if me.Profile.DisplayName != want.DisplayName || me.Profile.Bio != want.Bio || me.Profile.Location != want.Location ||
+		!slices.Equal(me.Profile.Links, want.Links) {
+		t.Errorf("got profile %+v, want %+v", me.Profile, want)
+	}
+
+	// The refreshed session reports the same profile
+	var refreshed models.AuthResponse
+	s.expect(http.StatusOK, "POST", "/auth/refresh", models.RefreshRequest{RefreshToken: auth.RefreshToken}, "", &refreshed)
+	if refreshed.User.Profile.DisplayName != "Ada" {
+		t.Errorf("got refreshed user %+v", refreshed.User)
+	}
+}
+
+func TestPublicProfile(t *testing.T) {
+	s := newTestServer(t)
+	auth := s.signUp(models.RoleStartHub)
+	editor := s.signUp(models.RoleStartHub)
+	owned := s.createStartHub(auth.Token, models.CreateStartHubRequest{Name: "Owned Co"})
+	other := s.createStartHub(editor.Token, models.CreateStartHubRequest{Name: "Edited Co"})
+	s.expect(http.StatusOK, "POST", "/api/invitations/accept", models.AcceptInvitationRequest{Token: s.invite(editor.Token, other.ID, auth.User.Email, models.MemberEditor)}, auth.Token, nil)
+	s.expect(http.StatusOK, "PATCH", "/api/me", map[string]any{"display_name": "Founder"}, auth.Token, nil)
+
+	// My starthubs lists every team, the public profile only the owned ones
+	var mine []models.TeamStartHub
+	s.expect(http.StatusOK, "GET", "/api/me/starthubs", nil, auth.Token, &mine)
+	if len(mine) != 2 || mine[0].StartHub.Name != "Edited Co" || mine[0].Role != models.MemberEditor || mine[1].Role != models.MemberOwner {
+		t.Fatalf("got my starthubs %+v", mine)
+	}
+
+	var profile map[string]any
+	s.expect(http.StatusOK, "GET", "/users/"+auth.User.ID, nil, "", &profile)
+	if _, ok := profile["email"]; ok {
+		t.Errorf("public profile shows the email: %+v", profile)
+	}
+
+	var public models.PublicProfile
+	s.expect(http.StatusOK, "GET", "/users/"+auth.User.ID, nil, "", &public)
+	if public.Profile.DisplayName != "Founder" || len(public.StartHubs) != 1 || public.StartHubs[0].ID != owned.ID {
+		t.Errorf("got public profile %+v", public)
+	}
+
+	// Hidden profiles look like they don't exist
+	s.expect(http.StatusOK, "PATCH", "/api/me", map[string]any{"public_profile": false}, auth.Token, nil)
+	s.expect(http.StatusNotFound, "GET", "/users/"+auth.User.ID, nil, "", nil)
+	s.expect(http.StatusNotFound, "GET", "/users/00000000-0000-0000-0000-000000000000", nil, "", nil)
+}
@@ -1,30 +1,162 @@
 package app
 
 import (
+	"time"
+
 	"github.com/ecetinerdem/starthub-backend/internal/middleware"
+	"github.com/ecetinerdem/starthub-backend/internal/ratelimit"
 	"github.com/ecetinerdem/starthub-backend/internal/routes"
+	"github.com/ecetinerdem/starthub-backend/internal/storage"
 	"github.com/gofiber/fiber/v2"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func setupRoutes(app *fiber.App, db *pgxpool.Pool) {
-	// Auth routes (public)
-	app.Post("/sign-up", middleware.ValidateRegister, routes.RegisterUser(db))
-	app.Post("/sign-in", middleware.ValidateLogin, routes.LoginUser(db))
+// Rate limits of the public auth routes. Sign-in is limited per address and per
+// account, so spreading guesses over many addresses doesn't get around it.
+var (
+	signInPerIP       = ratelimit.Limit{Burst: 20, Every: 30 * time.Second}
+	signInPerAccount  = ratelimit.Limit{Burst: 10, Every: time.Minute}
+	signUpPerIP       = ratelimit.Limit{Burst: 10, Every: 6 * time.Minute}
+	passwordMailPerIP = ratelimit.Limit{Burst: 5, Every: 5 * time.Minute}
+)
+
+func setupRoutes(app *fiber.App, services Services) {
+	repos := services.Repos
+	starthubs, categories, collaborations, members, users := repos.StartHubs, repos.Categories, repos.Collaborations, repos.Members, repos.Users
+	pipeline, funding, openRoles := repos.Pipeline, repos.Funding, repos.OpenRoles
+
+	// Auth routes (public, rate limited)
+	limits := services.RateLimits
+	app.Post("/sign-up",
+		middleware.RateLimit(limits, "sign-up:ip", signUpPerIP, middleware.ByIP),
+		middleware.ValidateRegister,
+		routes.RegisterUser(users, services.Mailer),
+	)
+	app.Post("/sign-in",
+		middleware.RateLimit(limits, "sign-in:ip", signInPerIP, middleware.ByIP),
+		middleware.RateLimit(limits, "sign-in:account", signInPerAccount, middleware.ByEmail),
+		middleware.ValidateLogin,
+		routes.LoginUser(users),
+	)
+
+	// Second step of a sign-in to an account with two-factor authentication
+	app.Post("/auth/mfa",
+		middleware.RateLimit(limits, "mfa:ip", signInPerIP, middleware.ByIP),
+		routes.CompleteMFA(users),
+	)
+
+	// Session routes
+	app.Post("/auth/refresh", routes.RefreshSession(users))
+	app.Post("/auth/logout", middleware.RequireAuth(users), routes.Logout(users))
+	app.Post("/auth/logout-all", middleware.RequireAuth(users), routes.LogoutAll(users))
+
+	// Password resets (public, the emailed token is the proof)
+	app.Post("/auth/forgot-password",
+		middleware.RateLimit(limits, "forgot-password:ip", passwordMailPerIP, middleware.ByIP),
+		routes.ForgotPassword(users, services.Mailer),
+	)
+	app.Post("/auth/reset-password", routes.ResetPassword(users, services.Mailer))
+	app.Post("/auth/verify-email", routes.VerifyEmail(users))
 
 	// Protected routes - require authentication
-	api := app.Group("/api", middleware.RequireAuth)
+	api := app.Group("/api", middleware.RequireAuth(users))
+
+	// The signed-in user's own account
+	api.Get("/me", routes.GetMe(users))
+	api.Patch("/me", routes.PatchMe(users))
+	api.Get("/me/starthubs", routes.GetMyStartHubs(members))
+	api.Post("/me/password", routes.ChangePassword(users, services.Mailer))
+	api.Post("/me/resend-verification", routes.ResendVerification(users, services.Mailer))
+	api.Get("/me/login-attempts", routes.GetMyLoginAttempts(users))
+
+	// Two-factor authentication of the signed-in user
+	api.Post("/me/2fa/setup", routes.SetupTwoFactor(users))
+	api.Post("/me/2fa/confirm", routes.ConfirmTwoFactor(users, services.Mailer))
+	api.Post("/me/2fa/recovery-codes", routes.RegenerateRecoveryCodes(users))
+	api.Post("/me/2fa/disable", routes.DisableTwoFactor(users, services.Mailer))
+
+	// Who may call what is decided by the policy table in middleware/rbac.go,
+	// routes of one starthub check the team role of the user in their handler
+	canCreateStartHub := middleware.RequirePermission(middleware.PermCreateStartHub)
+	verified := middleware.RequireVerifiedEmail(users)
+
+	// Starthubs (protected, owners and editors, only owners can delete, creating one needs a verified email)
+	api.Post("/starthubs", canCreateStartHub, verified, routes.CreateStartHub(starthubs, services.Images))
+	api.Put("/starthubs/:id", routes.UpdateStartHub(starthubs))
+	api.Patch("/starthubs/:id", routes.PatchStartHub(starthubs))
+	api.Delete("/starthubs/:id", routes.DeleteStartHub(starthubs))
+	api.Post("/starthubs/:id/image", routes.UploadStartHubImage(starthubs, services.Blobs))
 
-	// Starthubs (protected)
-	api.Post("/starthubs", routes.CreateStartHub(db))
-	api.Put("/starthubs/:id", routes.UpdateStartHub(db))
-	api.Delete("/starthubs/:id", routes.DeleteStartHub(db))
-	// Add other protected routes here as needed
-	// api.Put("/starthubs/:id", routes.UpdateStartHub(db))
-	// api.Delete("/starthubs/:id", routes.DeleteStartHub(db))
+	// Team members and invitations (protected, owners manage the team)
+	api.Get("/starthubs/:id/members", routes.GetMembers(starthubs, members))
+	api.Put("/starthubs/:id/members/:userId", routes.UpdateMemberRole(starthubs, members))
+	api.Delete("/starthubs/:id/members/:userId", routes.RemoveMember(starthubs, members))
+	api.Post("/starthubs/:id/transfer-ownership", routes.TransferOwnership(starthubs, members))
+	api.Get("/starthubs/:id/invitations", routes.GetInvitations(starthubs, members))
+	api.Post("/starthubs/:id/invitations", routes.InviteMember(starthubs, members, services.Mailer))
+	api.Delete("/starthubs/:id/invitations/:invitationId", routes.RevokeInvitation(starthubs, members))
+	api.Post("/invitations/accept", verified, routes.AcceptInvitation(members))
+
+	// Collaborations between starthubs (protected, owners and editors, the whole team can list them)
+	api.Get("/starthubs/:id/collaborations", routes.GetCollaborations(starthubs, collaborations))
+	api.Post("/starthubs/:id/collaborations", routes.ProposeCollaboration(starthubs, collaborations))
+	api.Post("/starthubs/:id/collaborations/:partnerId/accept", routes.AcceptCollaboration(starthubs, collaborations))
+	api.Post("/starthubs/:id/collaborations/:partnerId/decline", routes.DeclineCollaboration(starthubs, collaborations))
+	api.Delete("/starthubs/:id/collaborations/:partnerId", routes.EndCollaboration(starthubs, collaborations))
+
+	// External collaborators (protected, owners and editors)
+	api.Post("/starthubs/:id/external-collaborators", routes.AddExternalCollaborator(starthubs))
+	api.Put("/starthubs/:id/external-collaborators/order", routes.ReorderExternalCollaborators(starthubs))
+	api.Delete("/starthubs/:id/external-collaborators/:collaboratorId", routes.DeleteExternalCollaborator(starthubs))
+
+	// Investor deal flow (protected, investors only, each pipeline is private)
+	deals := api.Group("/pipeline", middleware.RequirePermission(middleware.PermTrackDeals))
+	deals.Get("/", routes.GetPipeline(pipeline))
+	deals.Put("/:starthubId", routes.SavePipelineEntry(pipeline))
+	deals.Delete("/:starthubId", routes.DeletePipelineEntry(pipeline))
+	api.Get("/starthubs/:id/interest", routes.GetInterestSummary(starthubs, pipeline))
+
+	// Donations (protected, donators pledge, owners and editors set goals)
+	api.Post("/starthubs/:id/pledges", middleware.RequirePermission(middleware.PermPledge), routes.CreatePledge(funding, services.Payments))
+	api.Get("/pledges", middleware.RequirePermission(middleware.PermPledge), routes.GetMyPledges(funding))
+	api.Post("/starthubs/:id/goals", routes.CreateFundingGoal(starthubs, funding))
+	api.Delete("/starthubs/:id/goals/:goalId", routes.DeleteFundingGoal(starthubs, funding))
+
+	// Open roles (protected, owners and editors post roles and decide applications, collaborators apply)
+	canApply := middleware.RequirePermission(middleware.PermApply)
+	api.Post("/starthubs/:id/roles", routes.CreateOpenRole(starthubs, openRoles))
+	api.Post("/starthubs/:id/roles/:roleId/close", routes.CloseOpenRole(starthubs, openRoles))
+	api.Get("/starthubs/:id/applications", routes.GetApplications(starthubs, openRoles))
+	api.Post("/starthubs/:id/applications/:applicationId/accept", routes.AcceptApplication(starthubs, openRoles))
+	api.Post("/starthubs/:id/applications/:applicationId/reject", routes.RejectApplication(starthubs, openRoles))
+	api.Post("/roles/:roleId/applications", canApply, routes.ApplyToOpenRole(openRoles))
+	api.Get("/applications", canApply, routes.GetMyApplications(openRoles))
+	api.Delete("/applications/:applicationId", canApply, routes.WithdrawApplication(openRoles))
+
+	// Category curation (admin only)
+	admin := api.Group("/admin", middleware.RequirePermission(middleware.PermManageCategories))
+	admin.Put("/categories/:id", routes.RenameCategory(categories))
+	admin.Post("/categories/merge", routes.MergeCategories(categories))
 
 	// Public starthub routes (no auth required)
-	app.Get("/starthubs", routes.GetAllStarthubs(db))
-	app.Get("/starthubs/search", routes.GetStartHubsBySearchTerm(db))
-	app.Get("/starthubs/:id", routes.GetStartHubByID(db))
+	app.Get("/starthubs", routes.GetAllStarthubs(starthubs))
+	app.Get("/starthubs/search", routes.GetStartHubsBySearchTerm(starthubs))
+	app.Get("/starthubs/:id", routes.GetStartHubByID(starthubs))
+	app.Get("/starthubs/:id/external-collaborators", routes.GetExternalCollaborators(starthubs))
+	app.Get("/starthubs/:id/funding", routes.GetFundingProgress(funding))
+	app.Get("/starthubs/:id/roles", routes.GetStartHubOpenRoles(openRoles))
+
+	// Public open role routes
+	app.Get("/roles", routes.GetOpenRoles(openRoles))
+	app.Get("/roles/:roleId", routes.GetOpenRole(openRoles))
+
+	// Public user profiles
+	app.Get("/users/:id", routes.GetPublicProfile(users, members))
+
+	// Public category routes
+	app.Get("/categories", routes.GetCategories(categories))
+
+	// Uploaded images, when they are kept on local disk
+	if local, ok := services.Blobs.(*storage.Local); ok {
+		app.Static(storage.LocalRoute, local.Dir)
+	}
 }
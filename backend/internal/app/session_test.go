@@ -0,0 +1,47 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/pkg/utils"
+)
+
+func TestRefreshSession(t *testing.T) {
+	s := newTestServer(t)
+	auth := s.signUp(models.RoleDonator)
+	sessionID, _, _ := utils.ParseRefreshToken(auth.RefreshToken)
+
+	// Knowing the session id isn't enough to sign anyone out
+	forged := models.RefreshRequest{RefreshToken: utils.FormatRefreshToken(sessionID, "guessed-secret")}
+	s.expect(http.StatusUnauthorized, "POST", "/auth/refresh", forged, "", nil)
+	s.expect(http.StatusOK, "GET", "/api/me", nil, auth.Token, nil)
+
+	var rotated models.AuthResponse
+	s.expect(http.StatusOK, "POST", "/auth/refresh", models.RefreshRequest{RefreshToken: auth.RefreshToken}, "", &rotated)
+	if rotated.RefreshToken == auth.RefreshToken {
+		t.Fatal("refresh token was not rotated")
+	}
+	s.expect(http.StatusUnauthorized, "POST", "/auth/refresh", forged, "", nil)
+	s.expect(http.StatusOK, "GET", "/api/me", nil, rotated.Token, nil)
+
+	// Presenting the rotated-away token again means it leaked, the session ends
+	s.expect(http.StatusUnauthorized, "POST", "/auth/refresh", models.RefreshRequest{RefreshToken: auth.RefreshToken}, "", nil)
+	s.expect(http.StatusUnauthorized, "GET", "/api/me", nil, rotated.Token, nil)
+	s.expect(http.StatusUnauthorized, "POST", "/auth/refresh", models.RefreshRequest{RefreshToken: rotated.RefreshToken}, "", nil)
+}
+
+func TestRefreshTokenReuseAfterSeveralRotations(t *testing.T) {
+	s := newTestServer(t)
+	auth := s.signUp(models.RoleInvestor)
+
+	// Any token the session rotated away revokes it, not only the last one
+	latest := auth
+	for range 2 {
+		s.expect(http.StatusOK, "POST", "/auth/refresh", models.RefreshRequest{RefreshToken: latest.RefreshToken}, "", &latest)
+	}
+	s.expect(http.StatusUnauthorized, "POST", "/auth/refresh", models.RefreshRequest{RefreshToken: auth.RefreshToken}, "", nil)
+	s.expect(http.StatusUnauthorized, "GET", "/api/me", nil, latest.Token, nil)
+	s.expect(http.StatusUnauthorized, "POST", "/auth/refresh", models.RefreshRequest{RefreshToken: latest.RefreshToken}, "", nil)
+}
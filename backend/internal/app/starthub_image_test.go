@@ -0,0 +1,178 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ecetinerdem/starthub-backend/internal/images"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/internal/storage"
+	"github.com/ecetinerdem/starthub-backend/internal/storage/storagetest"
+	"github.com/gofiber/fiber/v2"
+)
+
+// testPNG returns a w by h PNG
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 0x80
+	}
+	img.Set(0, 0, color.NRGBA{255, 0, 0, 255})
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// upload posts data as a file in the given field of a multipart form
+func (s *testServer) upload(status int, path, field string, data []byte, token string, out any) {
+	s.t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile(field, "logo.png")
+	part.Write(data)
+	form.Close()
+
+	req := httptest.NewRequest("POST", path, &body)
+	req.Header.Set(fiber.HeaderContentType, form.FormDataContentType())
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+
+	resp, err := s.app.Test(req, -1)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != status {
+		s.t.Fatalf("POST %s: got status %d, want %d\n%s", path, resp.StatusCode, status, respBody)
+	}
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			s.t.Fatal(err)
+		}
+	}
+}
+
+type uploadResponse struct {
+	ImageURL string            `json:"image_url"`
+	Images   map[string]string `json:"images"`
+}
+
+func TestUploadStartHubImage(t *testing.T) {
+	uploads := t.TempDir()
+	s := newTestServerWith(t, Services{
+		Images: images.Placeholder{},
+		Blobs:  &storage.Local{Dir: uploads, BaseURL: testUploadsURL},
+	})
+	owner := s.signUp(models.RoleStartHub).Token
+	other := s.signUp(models.RoleStartHub).Token
+	id := s.createStartHub(owner, models.CreateStartHubRequest{Name: "Logo Co"}).ID
+	path := "/api/starthubs/" + id + "/image"
+
+	tests := []struct {
+		name   string
+		token  string
+		field  string
+		data   []byte
+		status int
+	}{
+		{"another starthub", other, "image", testPNG(t, 10, 10), http.StatusForbidden},
+		{"missing file", owner, "file", testPNG(t, 10, 10), http.StatusBadRequest},
+		{"not an image", owner, "image", []byte("just some text"), http.StatusUnsupportedMediaType},
+		{"too big", owner, "image", bytes.Repeat([]byte{0}, images.MaxUploadBytes+1), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.sub(t).upload(tt.status, path, tt.field, tt.data, tt.token, nil)
+		})
+	}
+
+	var first uploadResponse
+	s.upload(http.StatusOK, path, "image", testPNG(t, 800, 600), owner, &first)
+
+	if len(first.Images) != len(images.Sizes) || first.ImageURL != first.Images["medium"] {
+		t.Fatalf("got %+v", first)
+	}
+
+	// The starthub points at the upload and the app serves it
+	var got models.StartHub
+	s.expect(http.StatusOK, "GET", "/starthubs/"+id, nil, "", &got)
+	if got.ImageURL != first.ImageURL {
+		t.Errorf("got image_url %q, want %q", got.ImageURL, first.ImageURL)
+	}
+
+	served := s.do("GET", strings.TrimPrefix(first.Images["small"], "http://starthub.test"), nil, "")
+	thumb, _ := io.ReadAll(served.Body)
+	config, _, err := image.DecodeConfig(bytes.NewReader(thumb))
+	if served.StatusCode != http.StatusOK || err != nil || config.Width != 128 || config.Height != 96 {
+		t.Errorf("small thumbnail: status %d, %v, %dx%d", served.StatusCode, err, config.Width, config.Height)
+	}
+
+	// A new upload replaces the files of the old one
+	var second uploadResponse
+	s.upload(http.StatusOK, path, "image", testPNG(t, 50, 50), owner, &second)
+
+	files, _ := filepath.Glob(filepath.Join(uploads, "starthubs", "*", "*", "*"))
+	if len(files) != len(images.Sizes) {
+		t.Errorf("got %d files after replacing the upload, want %d", len(files), len(images.Sizes))
+	}
+	for _, file := range files {
+		if !strings.Contains(second.ImageURL, filepath.Base(filepath.Dir(file))) {
+			t.Errorf("file %s of the old upload was kept", file)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(uploads, strings.TrimPrefix(first.ImageURL, testUploadsURL))); !os.IsNotExist(err) {
+		t.Errorf("old medium image still exists: %v", err)
+	}
+}
+
+func TestUploadStartHubImageToS3(t *testing.T) {
+	bucket := storagetest.NewS3(t, "media", "key-id")
+	s := newTestServerWith(t, Services{
+		Images: images.Placeholder{},
+		Blobs: &storage.S3{
+			Endpoint:        bucket.URL,
+			Region:          "us-east-1",
+			Bucket:          "media",
+			AccessKeyID:     "key-id",
+			SecretAccessKey: "secret",
+		},
+	})
+	owner := s.signUp(models.RoleStartHub).Token
+	created := s.createStartHub(owner, models.CreateStartHubRequest{Name: "Bucket Co"})
+
+	var uploaded uploadResponse
+	s.upload(http.StatusOK, "/api/starthubs/"+created.ID+"/image", "image", testPNG(t, 20, 20), owner, &uploaded)
+
+	if !strings.HasPrefix(uploaded.ImageURL, bucket.URL+"/media/starthubs/"+created.ID+"/") {
+		t.Errorf("got image_url %q", uploaded.ImageURL)
+	}
+	if bucket.Len() != len(images.Sizes) {
+		t.Errorf("got %d objects, want %d", bucket.Len(), len(images.Sizes))
+	}
+
+	resp, err := http.Get(uploaded.ImageURL)
+	if err != nil || resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/png" {
+		t.Errorf("could not fetch the uploaded image: %v", err)
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,524 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ecetinerdem/starthub-backend/internal/images"
+	"github.com/ecetinerdem/starthub-backend/internal/images/imagestest"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+)
+
+func TestStartHubLifecycle(t *testing.T) {
+	s := newTestServer(t)
+	owner := s.signUp(models.RoleStartHub).Token
+	other := s.signUp(models.RoleStartHub).Token
+
+	created := s.createStartHub(owner, models.CreateStartHubRequest{
+		Name:        "Solar Collective",
+		Description: "Community owned solar panels",
+		Location:    "Berlin",
+		TeamSize:    8,
+		Categories:  []string{"Energy", "Climate"},
+	})
+	path := "/starthubs/" + created.ID
+
+	t.Run("get", func(t *testing.T) {
+		s := s.sub(t)
+
+		var got models.StartHub
+		s.expect(http.StatusOK, "GET", path, nil, "", &got)
+
+		if got.Name != "Solar Collective" || got.TeamSize != 8 {
+			t.Errorf("got %+v", got)
+		}
+		if !slices.Equal(got.Categories, []string{"Climate", "Energy"}) {
+			t.Errorf("got categories %v", got.Categories)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		s := s.sub(t)
+
+		var page struct {
+			Data  []models.StartHub `json:"data"`
+			Total int               `json:"total"`
+		}
+		s.expect(http.StatusOK, "GET", "/starthubs?category=energy", nil, "", &page)
+
+		if page.Total != 1 || len(page.Data) != 1 || page.Data[0].ID != created.ID {
+			t.Errorf("got page %+v", page)
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		s := s.sub(t)
+
+		var found struct {
+			Total   int                           `json:"total"`
+			Results []models.StartHubSearchResult `json:"results"`
+		}
+		s.expect(http.StatusOK, "GET", "/starthubs/search?q="+url.QueryEscape("sola"), nil, "", &found)
+
+		if found.Total != 1 || found.Results[0].ID != created.ID {
+			t.Fatalf("got %+v", found)
+		}
+		if found.Results[0].Highlight.Name != "<mark>Solar</mark> Collective" {
+			t.Errorf("got highlight %q", found.Results[0].Highlight.Name)
+		}
+	})
+
+	t.Run("search escapes markup", func(t *testing.T) {
+		s := s.sub(t)
+
+		s.createStartHub(other, models.CreateStartHubRequest{
+			Name:        `Lunar <img src=x onerror="alert(1)"> Labs`,
+			Description: "Moon <b>rovers</b> & landers",
+		})
+
+		var found struct {
+			Results []models.StartHubSearchResult `json:"results"`
+		}
+		s.expect(http.StatusOK, "GET", "/starthubs/search?q=lunar+labs", nil, "", &found)
+
+		if len(found.Results) != 1 {
+			t.Fatalf("got %+v", found)
+		}
+		got := found.Results[0].Highlight
+		if strings.Contains(got.Name, "<img") || !strings.Contains(got.Name, "<mark>Lunar</mark> &lt;img") || !strings.Contains(got.Name, "<mark>Labs</mark>") {
+			t.Errorf("got name highlight %q", got.Name)
+		}
+		if strings.Contains(got.Description, "<b>") || !strings.Contains(got.Description, "&amp;") {
+			t.Errorf("got description highlight %q", got.Description)
+		}
+	})
+
+	update := models.CreateStartHubRequest{
+		Name:       "Solar Collective Berlin",
+		Location:   "Berlin",
+		TeamSize:   12,
+		Email:      created.Email,
+		Categories: []string{"Energy"},
+	}
+
+	updates := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{"update without token", "", http.StatusUnauthorized},
+		{"update by another starthub", other, http.StatusForbidden},
+		{"update by owner", owner, http.StatusOK},
+	}
+	for _, tt := range updates {
+		t.Run(tt.name, func(t *testing.T) {
+			s := s.sub(t)
+			s.expect(tt.status, "PUT", "/api"+path, update, tt.token, nil)
+		})
+	}
+
+	t.Run("update is visible", func(t *testing.T) {
+		s := s.sub(t)
+
+		var got models.StartHub
+		s.expect(http.StatusOK, "GET", path, nil, "", &got)
+
+		if got.Name != update.Name || got.TeamSize != 12 || !slices.Equal(got.Categories, []string{"Energy"}) {
+			t.Errorf("got %+v", got)
+		}
+	})
+
+	deletes := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{"delete by another starthub", other, http.StatusForbidden},
+		{"delete by owner", owner, http.StatusOK},
+		{"delete twice", owner, http.StatusForbidden},
+	}
+	for _, tt := range deletes {
+		t.Run(tt.name, func(t *testing.T) {
+			s := s.sub(t)
+			s.expect(tt.status, "DELETE", "/api"+path, nil, tt.token, nil)
+		})
+	}
+
+	t.Run("gone after delete", func(t *testing.T) {
+		s := s.sub(t)
+		s.expect(http.StatusNotFound, "GET", path, nil, "", nil)
+	})
+}
+
+func TestCreateStartHub(t *testing.T) {
+	s := newTestServer(t)
+	starthub := s.signUp(models.RoleStartHub).Token
+	investor := s.signUp(models.RoleInvestor).Token
+	taken := s.createStartHub(starthub, models.CreateStartHubRequest{Name: "Taken"}).Email
+
+	tests := []struct {
+		name   string
+		token  string
+		body   models.CreateStartHubRequest
+		status int
+	}{
+		{"valid", starthub, models.CreateStartHubRequest{Name: "New", Email: uniqueEmail("new")}, http.StatusCreated},
+		{"missing name", starthub, models.CreateStartHubRequest{Email: uniqueEmail("noname")}, http.StatusBadRequest},
+		{"missing email", starthub, models.CreateStartHubRequest{Name: "No Email"}, http.StatusBadRequest},
+		{"duplicate email", starthub, models.CreateStartHubRequest{Name: "Dup", Email: taken}, http.StatusConflict},
+		{"role without permission", investor, models.CreateStartHubRequest{Name: "Nope", Email: uniqueEmail("nope")}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := s.sub(t)
+			s.expect(tt.status, "POST", "/api/starthubs", tt.body, tt.token, nil)
+		})
+	}
+}
+
+func TestListStartHubsPaging(t *testing.T) {
+	s := newTestServer(t)
+	token := s.signUp(models.RoleStartHub).Token
+
+	for _, name := range []string{"Charlie", "Alpha", "Bravo"} {
+		s.createStartHub(token, models.CreateStartHubRequest{Name: name})
+	}
+
+	type page struct {
+		Data       []models.StartHub `json:"data"`
+		NextCursor *string           `json:"next_cursor"`
+		Total      int               `json:"total"`
+	}
+
+	var names []string
+	next := "/starthubs?sort=name&limit=2"
+	for next != "" {
+		var p page
+		s.expect(http.StatusOK, "GET", next, nil, "", &p)
+
+		if p.Total != 3 {
+			t.Errorf("got total %d, want 3", p.Total)
+		}
+		for _, hub := range p.Data {
+			names = append(names, hub.Name)
+		}
+
+		next = ""
+		if p.NextCursor != nil {
+			next = "/starthubs?sort=name&limit=2&cursor=" + *p.NextCursor
+		}
+	}
+
+	if !slices.Equal(names, []string{"Alpha", "Bravo", "Charlie"}) {
+		t.Errorf("got %v", names)
+	}
+
+	// A cursor only works with the sort order it came from
+	var first page
+	s.expect(http.StatusOK, "GET", "/starthubs?sort=name&limit=1", nil, "", &first)
+	s.expect(http.StatusBadRequest, "GET", "/starthubs?sort=newest&cursor="+*first.NextCursor, nil, "", nil)
+}
+
+func TestCreateStartHubImage(t *testing.T) {
+	api := imagestest.NewServer(t, map[string]string{
+		"energy":  "https://img.test/energy.jpg",
+		"startup": "https://img.test/startup.jpg",
+	})
+	pexels := images.NewPexels("key")
+	pexels.BaseURL = api.URL
+
+	s := newTestServerWith(t, Services{
+		Images: images.NewCache(images.Chain{pexels, images.Placeholder{}}, time.Hour),
+	})
+	token := s.signUp(models.RoleStartHub).Token
+
+	tests := []struct {
+		name       string
+		categories []string
+		want       string
+	}{
+		{"first category", []string{"Energy", "Climate"}, "https://img.test/energy.jpg"},
+		{"cached category", []string{"energy"}, "https://img.test/energy.jpg"},
+		{"no categories", nil, "https://img.test/startup.jpg"},
+		{"unknown category", []string{"Knitting"}, "data:image/svg+xml;base64,"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := s.sub(t)
+
+			created := s.createStartHub(token, models.CreateStartHubRequest{Name: tt.name, Categories: tt.categories})
+			if !strings.HasPrefix(created.ImageURL, tt.want) {
+				t.Errorf("got image %q, want %q", created.ImageURL, tt.want)
+			}
+		})
+	}
+
+	// One lookup per query, the second energy starthub came from the cache
+	if got := api.Queries(); !slices.Equal(got, []string{"energy", "startup", "knitting"}) {
+		t.Errorf("got queries %v", got)
+	}
+}
+
+func TestPatchStartHub(t *testing.T) {
+	s := newTestServer(t)
+	owner := s.signUp(models.RoleStartHub).Token
+	other := s.signUp(models.RoleStartHub).Token
+
+	created := s.createStartHub(owner, models.CreateStartHubRequest{
+		Name:        "Patchwork",
+		Description: "Quilts on demand",
+		Location:    "Lisbon",
+		TeamSize:    3,
+		Categories:  []string{"Textiles"},
+	})
+	path := "/api/starthubs/" + created.ID
+
+	tests := []struct {
+		name   string
+		token  string
+		patch  any
+		status int
+		field  string
+		check  func(t *testing.T, got models.StartHub)
+	}{
+		{
+			name:   "one field",
+			token:  owner,
+			patch:  map[string]any{"team_size": 5},
+			status: http.StatusOK,
+			check: func(t *testing.T, got models.StartHub) {
+				if got.TeamSize != 5 || got.Name != "Patchwork" || got.Description != "Quilts on demand" ||
+					!slices.Equal(got.Categories, []string{"Textiles"}) {
+					t.Errorf("other fields changed: %+v", got)
+				}
+			},
+		},
+		{
+			name:   "null clears a field",
+			token:  owner,
+			patch:  map[string]any{"description": nil, "location": "Porto"},
+			status: http.StatusOK,
+			check: func(t *testing.T, got models.StartHub) {
+				if got.Description != "" || got.Location != "Porto" || got.TeamSize != 5 {
+					t.Errorf("got %+v", got)
+				}
+			},
+		},
+		{
+			name:   "categories are replaced",
+			token:  owner,
+			patch:  map[string]any{"categories": []string{"Fashion", "Retail"}},
+			status: http.StatusOK,
+			check: func(t *testing.T, got models.StartHub) {
+				if !slices.Equal(got.Categories, []string{"Fashion", "Retail"}) {
+					t.Errorf("got categories %v", got.Categories)
+				}
+			},
+		},
+		{
+			name:   "null categories clears them",
+			token:  owner,
+			patch:  map[string]any{"categories": nil},
+			status: http.StatusOK,
+			check: func(t *testing.T, got models.StartHub) {
+				if len(got.Categories) != 0 {
+					t.Errorf("got categories %v", got.Categories)
+				}
+			},
+		},
+		{"required field can't be cleared", owner, map[string]any{"name": nil}, http.StatusBadRequest, "name", nil},
+		{"invalid email", owner, map[string]any{"email": "nope"}, http.StatusBadRequest, "email", nil},
+		{"negative team size", owner, map[string]any{"team_size": -1}, http.StatusBadRequest, "team_size", nil},
+		{"wrong type", owner, map[string]any{"team_size": "ten"}, http.StatusBadRequest, "team_size", nil},
+		{"unknown field", owner, map[string]any{"founded": 2020}, http.StatusBadRequest, "founded", nil},
+		{"read-only field", owner, map[string]any{"image_url": "https://example.com/x.png"}, http.StatusBadRequest, "image_url", nil},
+		{"not an object", owner, []int{1, 2}, http.StatusBadRequest, "", nil},
+		{"another starthub", other, map[string]any{"team_size": 1}, http.StatusForbidden, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := s.sub(t)
+
+			if tt.status != http.StatusOK {
+				var p problem
+				s.expect(tt.status, "PATCH", path, tt.patch, tt.token, &p)
+				if tt.field != "" && (len(p.Errors) != 1 || p.Errors[0].Field != tt.field) {
+					t.Errorf("got field errors %+v, want one for %q", p.Errors, tt.field)
+				}
+				return
+			}
+
+			var got models.StartHub
+			s.expect(tt.status, "PATCH", path, tt.patch, tt.token, &got)
+			tt.check(t, got)
+		})
+	}
+
+	t.Run("merge patch content type", func(t *testing.T) {
+		for contentType, status := range map[string]int{
+			"application/merge-patch+json":                http.StatusOK,
+			"application/merge-patch+json; charset=utf-8": http.StatusOK,
+			"text/plain": http.StatusUnsupportedMediaType,
+		} {
+			req := httptest.NewRequest("PATCH", path, strings.NewReader(`{"team_size": 7}`))
+			req.Header.Set("Content-Type", contentType)
+			req.Header.Set("Authorization", "Bearer "+owner)
+
+			resp, err := s.app.Test(req, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != status {
+				t.Errorf("%s: got status %d, want %d", contentType, resp.StatusCode, status)
+			}
+		}
+	})
+}
+
+func TestPutReplacesStartHub(t *testing.T) {
+	s := newTestServer(t)
+	owner := s.signUp(models.RoleStartHub).Token
+
+	created := s.createStartHub(owner, models.CreateStartHubRequest{
+		Name:        "Replace Me",
+		Description: "Will be gone",
+		Categories:  []string{"Energy"},
+	})
+	path := "/api/starthubs/" + created.ID
+
+	var p problem
+	s.expect(http.StatusBadRequest, "PUT", path, models.CreateStartHubRequest{Name: "No Email"}, owner, &p)
+	if len(p.Errors) != 1 || p.Errors[0].Field != "email" {
+		t.Errorf("got field errors %+v", p.Errors)
+	}
+
+	var got models.StartHub
+	s.expect(http.StatusOK, "PUT", path, models.CreateStartHubRequest{Name: "Replaced", Email: created.Email}, owner, &got)
+	if got.Name != "Replaced" || got.Description != "" || len(got.Categories) != 0 {
+		t.Errorf("fields left out were kept: %+v", got)
+	}
+}
+
+func TestUpdateReturnsStoredCategories(t *testing.T) {
+	s := newTestServer(t)
+	owner := s.signUp(models.RoleStartHub).Token
+
+	created := s.createStartHub(owner, models.CreateStartHubRequest{Name: "Category Co", Categories: []string{"AI"}})
+	path := "/api/starthubs/" + created.ID
+
+	// Categories come back as stored, once each, whatever letter case was sent
+	var got models.StartHub
+	s.expect(http.StatusOK, "PUT", path, models.CreateStartHubRequest{Name: "Category Co", Email: created.Email, Categories: []string{"ai", "Fintech"}}, owner, &got)
+	if want := []string{"AI", "Fintech"}; !slices.Equal(got.Categories, want) {
+		t.Errorf("got categories %q after PUT, want %q", got.Categories, want)
+	}
+
+	s.expect(http.StatusOK, "PATCH", path, map[string]any{"categories": []string{"fintech"}}, owner, &got)
+	if want := []string{"Fintech"}; !slices.Equal(got.Categories, want) {
+		t.Errorf("got categories %q after PATCH, want %q", got.Categories, want)
+	}
+
+	s.expect(http.StatusOK, "GET", "/starthubs/"+created.ID, nil, "", &got)
+	if want := []string{"Fintech"}; !slices.Equal(got.Categories, want) {
+		t.Errorf("got categories %q after GET, want %q", got.Categories, want)
+	}
+}
+
+func TestStartHubETags(t *testing.T) {
+	s := newTestServer(t)
+	owner := s.signUp(models.RoleStartHub).Token
+
+	created := s.createStartHub(owner, models.CreateStartHubRequest{Name: "Versioned"})
+	path := "/starthubs/" + created.ID
+
+	etagOf := func(t *testing.T, resp *http.Response) string {
+		t.Helper()
+		tag := resp.Header.Get("ETag")
+		if tag == "" {
+			t.Fatalf("%s %s sent no ETag", resp.Request.Method, resp.Request.URL.Path)
+		}
+		return tag
+	}
+
+	first := etagOf(t, s.do("GET", path, nil, ""))
+
+	t.Run("if-none-match", func(t *testing.T) {
+		for header, status := range map[string]int{
+			first:           http.StatusNotModified,
+			"W/" + first:    http.StatusNotModified,
+			`"0", ` + first: http.StatusNotModified,
+			"*":             http.StatusNotModified,
+			`"0"`:           http.StatusOK,
+		} {
+			resp := s.doWithHeaders("GET", path, nil, "", http.Header{"If-None-Match": {header}})
+			if resp.StatusCode != status {
+				t.Errorf("If-None-Match %s: got status %d, want %d", header, resp.StatusCode, status)
+			}
+		}
+	})
+
+	// Two editors read the same version, the second one to save loses
+	patch := map[string]any{"team_size": 2}
+	resp := s.doWithHeaders("PATCH", "/api"+path, patch, owner, http.Header{"If-Match": {first}})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("first edit: got status %d", resp.StatusCode)
+	}
+	second := etagOf(t, resp)
+	if second == first {
+		t.Fatal("the ETag did not change with the edit")
+	}
+
+	stale := []struct {
+		name   string
+		method string
+		body   any
+	}{
+		{"stale patch", "PATCH", patch},
+		{"stale put", "PUT", models.CreateStartHubRequest{Name: "Overwrite", Email: created.Email}},
+		{"stale delete", "DELETE", nil},
+	}
+	for _, tt := range stale {
+		t.Run(tt.name, func(t *testing.T) {
+			var p problem
+			resp := s.doWithHeaders(tt.method, "/api"+path, tt.body, owner, http.Header{"If-Match": {first}})
+			json.NewDecoder(resp.Body).Decode(&p)
+			if resp.StatusCode != http.StatusPreconditionFailed || p.Code != "precondition_failed" {
+				t.Errorf("got status %d, code %q", resp.StatusCode, p.Code)
+			}
+		})
+	}
+
+	t.Run("weak tags never match", func(t *testing.T) {
+		resp := s.doWithHeaders("PATCH", "/api"+path, patch, owner, http.Header{"If-Match": {"W/" + second}})
+		if resp.StatusCode != http.StatusPreconditionFailed {
+			t.Errorf("got status %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("related changes change the etag", func(t *testing.T) {
+		s.expect(http.StatusCreated, "POST", "/api"+path+"/external-collaborators", map[string]string{"name": "Partner"}, owner, nil)
+		if etagOf(t, s.do("GET", path, nil, "")) == second {
+			t.Error("adding an external collaborator kept the ETag")
+		}
+	})
+
+	current := etagOf(t, s.do("GET", path, nil, ""))
+	resp = s.doWithHeaders("PUT", "/api"+path, models.CreateStartHubRequest{Name: "Current", Email: created.Email}, owner, http.Header{"If-Match": {`"0", ` + current}})
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("put with the current ETag: got status %d", resp.StatusCode)
+	}
+
+	// Without If-Match the last write wins, as before
+	s.expect(http.StatusOK, "PUT", "/api"+path, models.CreateStartHubRequest{Name: "Anyone", Email: created.Email}, owner, nil)
+	s.expect(http.StatusOK, "DELETE", "/api"+path, nil, owner, nil)
+}
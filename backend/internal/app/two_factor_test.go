@@ -0,0 +1,161 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/internal/totp"
+)
+
+// totpCode returns the code of the secret offset periods from now
+func (s *testServer) totpCode(secret string, offset int64) string {
+	s.t.Helper()
+
+	code, err := totp.Code(secret, totp.Step(time.Now())+offset)
+	if err != nil {
+		s.t.Fatalf("could not make a code: %v", err)
+	}
+	return code
+}
+
+// enableTwoFactor enrolls an authenticator for the user with the code of the
+// current period and returns its secret and the recovery codes
+func (s *testServer) enableTwoFactor(token string) (string, []string) {
+	s.t.Helper()
+
+	var setup models.TwoFactorSetupResponse
+	s.expect(http.StatusOK, "POST", "/api/me/2fa/setup", models.TwoFactorSetupRequest{Password: "password123"}, token, &setup)
+
+	var recovery models.RecoveryCodesResponse
+	s.expect(http.StatusOK, "POST", "/api/me/2fa/confirm", models.TwoFactorCodeRequest{Code: s.totpCode(setup.Secret, 0)}, token, &recovery)
+
+	return setup.Secret, recovery.RecoveryCodes
+}
+
+// challenge signs in with the password and returns the challenge token
+func (s *testServer) challenge(email string) string {
+	s.t.Helper()
+
+	var challenge models.MFAChallengeResponse
+	s.expect(http.StatusOK, "POST", "/sign-in", models.LoginRequest{Email: email, Password: "password123"}, "", &challenge)
+	if !challenge.MFARequired || challenge.ChallengeToken == "" {
+		s.t.Fatalf("got %+v, want a challenge", challenge)
+	}
+
+	return challenge.ChallengeToken
+}
+
+func TestTwoFactor(t *testing.T) {
+	s := newTestServer(t)
+	auth := s.signUp(models.RoleInvestor)
+	email := auth.User.Email
+
+	// Enrolling takes the password, then a code from the new authenticator
+	s.expect(http.StatusBadRequest, "POST", "/api/me/2fa/setup", models.TwoFactorSetupRequest{Password: "wrong-password"}, auth.Token, nil)
+	s.expect(http.StatusConflict, "POST", "/api/me/2fa/confirm", models.TwoFactorCodeRequest{Code: "123456"}, auth.Token, nil)
+
+	var setup models.TwoFactorSetupResponse
+	s.expect(http.StatusOK, "POST", "/api/me/2fa/setup", models.TwoFactorSetupRequest{Password: "password123"}, auth.Token, &setup)
+	if setup.Secret == "" || !strings.HasPrefix(setup.OTPAuthURI, "otpauth://totp/StartHub:") || !strings.Contains(setup.OTPAuthURI, "secret="+setup.Secret) {
+		t.Fatalf("got setup %+v", setup)
+	}
+
+	// Until it is confirmed sign-in is unchanged
+	s.signIn(email, "password123")
+
+	s.expect(http.StatusBadRequest, "POST", "/api/me/2fa/confirm", models.TwoFactorCodeRequest{Code: "abcdef"}, auth.Token, nil)
+	var recovery models.RecoveryCodesResponse
+	s.expect(http.StatusOK, "POST", "/api/me/2fa/confirm", models.TwoFactorCodeRequest{Code: s.totpCode(setup.Secret, 0)}, auth.Token, &recovery)
+	if len(recovery.RecoveryCodes) != models.RecoveryCodeCount {
+		t.Fatalf("got %d recovery codes, want %d", len(recovery.RecoveryCodes), models.RecoveryCodeCount)
+	}
+	if msg, ok := s.mail.Last(email); !ok || !strings.Contains(msg.Subject, "turned on") {
+		t.Errorf("got email %+v", msg)
+	}
+
+	var me models.UserResponse
+	s.expect(http.StatusOK, "GET", "/api/me", nil, auth.Token, &me)
+	if !me.TwoFactor {
+		t.Fatalf("got %+v after enabling two-factor authentication", me)
+	}
+	s.expect(http.StatusConflict, "POST", "/api/me/2fa/setup", models.TwoFactorSetupRequest{Password: "password123"}, auth.Token, nil)
+
+	// The password alone only earns a challenge, never a session
+	var body map[string]any
+	s.expect(http.StatusOK, "POST", "/sign-in", models.LoginRequest{Email: email, Password: "password123"}, "", &body)
+	if _, ok := body["token"]; ok || body["mfa_required"] != true {
+		t.Fatalf("got sign-in response %+v", body)
+	}
+
+	challenge := s.challenge(email)
+	tests := []struct {
+		name   string
+		body   models.MFARequest
+		status int
+	}{
+		{"no token", models.MFARequest{Code: s.totpCode(setup.Secret, 1)}, http.StatusBadRequest},
+		{"no code", models.MFARequest{ChallengeToken: challenge}, http.StatusBadRequest},
+		{"unknown challenge", models.MFARequest{ChallengeToken: "not-a-token", Code: s.totpCode(setup.Secret, 1)}, http.StatusUnauthorized},
+		{"wrong code", models.MFARequest{ChallengeToken: challenge, Code: "abcdef"}, http.StatusUnauthorized},
+		{"replayed code", models.MFARequest{ChallengeToken: challenge, Code: s.totpCode(setup.Secret, 0)}, http.StatusUnauthorized},
+		{"signed in", models.MFARequest{ChallengeToken: challenge, Code: s.totpCode(setup.Secret, 1)}, http.StatusOK},
+		{"challenge used twice", models.MFARequest{ChallengeToken: challenge, Code: recovery.RecoveryCodes[0]}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.sub(t).expect(tt.status, "POST", "/auth/mfa", tt.body, "", nil)
+		})
+	}
+
+	// Recovery codes work once, however they are typed
+	var signedIn models.AuthResponse
+	s.expect(http.StatusOK, "POST", "/auth/mfa", models.MFARequest{ChallengeToken: s.challenge(email), Code: strings.ToUpper(recovery.RecoveryCodes[0])}, "", &signedIn)
+	s.expect(http.StatusOK, "GET", "/api/me", nil, signedIn.Token, nil)
+	s.expect(http.StatusUnauthorized, "POST", "/auth/mfa", models.MFARequest{ChallengeToken: s.challenge(email), Code: recovery.RecoveryCodes[0]}, "", nil)
+
+	// New recovery codes replace the old ones
+	var fresh models.RecoveryCodesResponse
+	s.expect(http.StatusBadRequest, "POST", "/api/me/2fa/recovery-codes", models.TwoFactorCodeRequest{Code: "abcdef"}, auth.Token, nil)
+	s.expect(http.StatusOK, "POST", "/api/me/2fa/recovery-codes", models.TwoFactorCodeRequest{Code: recovery.RecoveryCodes[1]}, auth.Token, &fresh)
+	if len(fresh.RecoveryCodes) != models.RecoveryCodeCount || fresh.RecoveryCodes[0] == recovery.RecoveryCodes[2] {
+		t.Fatalf("got recovery codes %+v", fresh)
+	}
+
+	// Turning it off takes the password and a second factor
+	disable := func(password, code string) models.DisableTwoFactorRequest {
+		return models.DisableTwoFactorRequest{Password: password, Code: code}
+	}
+	s.expect(http.StatusBadRequest, "POST", "/api/me/2fa/disable", disable("password123", recovery.RecoveryCodes[2]), auth.Token, nil)
+	s.expect(http.StatusBadRequest, "POST", "/api/me/2fa/disable", disable("wrong-password", fresh.RecoveryCodes[0]), auth.Token, nil)
+	s.expect(http.StatusOK, "POST", "/api/me/2fa/disable", disable("password123", fresh.RecoveryCodes[0]), auth.Token, nil)
+	s.expect(http.StatusConflict, "POST", "/api/me/2fa/disable", disable("password123", fresh.RecoveryCodes[1]), auth.Token, nil)
+	if msg, ok := s.mail.Last(email); !ok || !strings.Contains(msg.Subject, "turned off") {
+		t.Errorf("got email %+v", msg)
+	}
+
+	s.signIn(email, "password123")
+}
+
+func TestTwoFactorGuessing(t *testing.T) {
+	s := newTestServer(t)
+	auth := s.signUp(models.RoleInvestor)
+	email := auth.User.Email
+	_, codes := s.enableTwoFactor(auth.Token)
+
+	// A challenge takes a few wrong codes, which also lock the account
+	challenge := s.challenge(email)
+	for range 5 {
+		s.expect(http.StatusUnauthorized, "POST", "/auth/mfa", models.MFARequest{ChallengeToken: challenge, Code: "abcdef"}, "", nil)
+	}
+	s.expect(http.StatusUnauthorized, "POST", "/auth/mfa", models.MFARequest{ChallengeToken: challenge, Code: codes[0]}, "", nil)
+	s.expectRetryAfter("POST", "/sign-in", models.LoginRequest{Email: email, Password: "password123"})
+
+	var attempts []models.LoginAttempt
+	s.expect(http.StatusOK, "GET", "/api/me/login-attempts", nil, auth.Token, &attempts)
+	if len(attempts) != 6 || attempts[0].Reason != models.LoginLocked || attempts[1].Reason != models.LoginWrongMFACode {
+		t.Errorf("got attempts %+v", attempts)
+	}
+}
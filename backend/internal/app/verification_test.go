@@ -0,0 +1,65 @@
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+)
+
+func TestEmailVerification(t *testing.T) {
+	s := newTestServer(t)
+	auth := s.signUpUnverified(models.RoleStartHub)
+	email := auth.User.Email
+	if auth.User.EmailVerified {
+		t.Fatalf("new account is verified: %+v", auth.User)
+	}
+
+	// Unverified users can't create starthubs, and are told why
+	var denied problem
+	s.expect(http.StatusForbidden, "POST", "/api/starthubs", models.CreateStartHubRequest{Name: "Early Co", Email: uniqueEmail("starthub")}, auth.Token, &denied)
+	if denied.Code != "forbidden" {
+		t.Errorf("got problem %+v", denied)
+	}
+
+	// Resending right after sign-up is throttled
+	resp := s.do("POST", "/api/me/resend-verification", nil, auth.Token)
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("got status %d for an immediate resend, want 429", resp.StatusCode)
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err != nil || seconds < 1 || seconds > 60 {
+		t.Errorf("got Retry-After %q", resp.Header.Get("Retry-After"))
+	}
+	if n := len(s.mail.Messages(email)); n != 1 {
+		t.Errorf("got %d emails, want only the one sent at sign-up", n)
+	}
+
+	token := s.mailedCode(email, "Verification code: ")
+	tests := []struct {
+		name   string
+		body   models.VerifyEmailRequest
+		status int
+	}{
+		{"no token", models.VerifyEmailRequest{}, http.StatusBadRequest},
+		{"unknown token", models.VerifyEmailRequest{Token: "not-a-token"}, http.StatusBadRequest},
+		{"verified", models.VerifyEmailRequest{Token: token}, http.StatusOK},
+		{"used twice", models.VerifyEmailRequest{Token: token}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.sub(t).expect(tt.status, "POST", "/auth/verify-email", tt.body, "", nil)
+		})
+	}
+
+	// The same access token works now, the check reads the account fresh
+	var me models.UserResponse
+	s.expect(http.StatusOK, "GET", "/api/me", nil, auth.Token, &me)
+	if !me.EmailVerified || me.EmailVerifiedAt == nil {
+		t.Fatalf("got %+v after verifying", me)
+	}
+	s.createStartHub(auth.Token, models.CreateStartHubRequest{Name: "Verified Co"})
+
+	s.expect(http.StatusConflict, "POST", "/api/me/resend-verification", nil, auth.Token, nil)
+	s.expect(http.StatusUnauthorized, "POST", "/api/me/resend-verification", nil, "", nil)
+}
@@ -0,0 +1,134 @@
+package apperrors
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Error codes clients can switch on
+const (
+	CodeBadRequest           = "bad_request"
+	CodeValidation           = "validation_failed"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodePaymentRequired      = "payment_required"
+	CodeNotFound             = "not_found"
+	CodeConflict             = "conflict"
+	CodePreconditionFailed   = "precondition_failed"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeTooManyRequests      = "too_many_requests"
+	CodeInternal             = "internal_error"
+	CodeServiceUnavailable   = "service_unavailable"
+)
+
+// FieldError describes what is wrong with one field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is an error that knows how it should be shown to API clients.
+// Handlers return it and the error handler renders it, so no handler
+// builds error JSON itself.
+type Error struct {
+	Status  int            // HTTP status code
+	Code    string         // Machine readable code, one of the Code constants
+	Message string         // Safe to show to clients
+	Fields  []FieldError   // Per-field problems, for validation errors
+	Extra   map[string]any // Additional members added to the response body
+	Err     error          // Underlying cause, logged but never sent to clients
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// With adds a member to the response body
+func (e *Error) With(key string, value any) *Error {
+	if e.Extra == nil {
+		e.Extra = map[string]any{}
+	}
+	e.Extra[key] = value
+	return e
+}
+
+// New creates an error with the given status, code and message
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func BadRequest(message string) *Error {
+	return New(http.StatusBadRequest, CodeBadRequest, message)
+}
+
+// Validation reports one or more invalid fields
+func Validation(fields ...FieldError) *Error {
+	message := "Request validation failed"
+	if len(fields) == 1 {
+		message = fields[0].Message
+	}
+
+	err := New(http.StatusBadRequest, CodeValidation, message)
+	err.Fields = fields
+	return err
+}
+
+// Field is shorthand for a validation error on a single field
+func Field(field, message string) *Error {
+	return Validation(FieldError{Field: field, Message: message})
+}
+
+func Unauthorized(message string) *Error {
+	return New(http.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+func Forbidden(message string) *Error {
+	return New(http.StatusForbidden, CodeForbidden, message)
+}
+
+func PaymentRequired(message string) *Error {
+	return New(http.StatusPaymentRequired, CodePaymentRequired, message)
+}
+
+func NotFound(message string) *Error {
+	return New(http.StatusNotFound, CodeNotFound, message)
+}
+
+func Conflict(message string) *Error {
+	return New(http.StatusConflict, CodeConflict, message)
+}
+
+func PreconditionFailed(message string) *Error {
+	return New(http.StatusPreconditionFailed, CodePreconditionFailed, message)
+}
+
+func PayloadTooLarge(message string) *Error {
+	return New(http.StatusRequestEntityTooLarge, CodePayloadTooLarge, message)
+}
+
+func UnsupportedMediaType(message string) *Error {
+	return New(http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, message)
+}
+
+func TooManyRequests(message string) *Error {
+	return New(http.StatusTooManyRequests, CodeTooManyRequests, message)
+}
+
+func ServiceUnavailable(message string) *Error {
+	return New(http.StatusServiceUnavailable, CodeServiceUnavailable, message)
+}
+
+// Internal hides err from the client behind message, the error handler logs it
+func Internal(message string, err error) *Error {
+	e := New(http.StatusInternalServerError, CodeInternal, message)
+	e.Err = err
+	return e
+}
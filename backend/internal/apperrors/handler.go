@@ -0,0 +1,91 @@
+package apperrors
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler is the Fiber ErrorHandler. It renders every error returned by a
+// handler or middleware as an RFC 7807 problem document:
+//
+//	{
+//	  "type": "about:blank",
+//	  "title": "Not Found",
+//	  "status": 404,
+//	  "detail": "Starthub not found",
+//	  "instance": "/starthubs/123",
+//	  "code": "not_found",
+//	  "request_id": "…",
+//	  "errors": [{"field": "email", "message": "…"}]
+//	}
+func Handler(c *fiber.Ctx, err error) error {
+	var appErr *Error
+	if !errors.As(err, &appErr) {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			// Errors raised by Fiber itself, like unknown routes or oversized bodies
+			appErr = New(fiberErr.Code, codeForStatus(fiberErr.Code), fiberErr.Message)
+		} else {
+			appErr = Internal("Internal server error", err)
+		}
+	}
+
+	requestID, _ := c.Locals("requestid").(string)
+
+	if appErr.Status >= http.StatusInternalServerError {
+		log.Printf("❌ [%s] %s %s: %v", requestID, c.Method(), c.Path(), appErr)
+	}
+
+	body := fiber.Map{
+		"type":       "about:blank",
+		"title":      http.StatusText(appErr.Status),
+		"status":     appErr.Status,
+		"detail":     appErr.Message,
+		"instance":   c.OriginalURL(),
+		"code":       appErr.Code,
+		"request_id": requestID,
+		// Kept for clients written against the old {"error": "..."} responses
+		"error": appErr.Message,
+	}
+	if len(appErr.Fields) > 0 {
+		body["errors"] = appErr.Fields
+	}
+	for key, value := range appErr.Extra {
+		body[key] = value
+	}
+
+	c.Status(appErr.Status)
+	if err := c.JSON(body); err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, "application/problem+json")
+
+	return nil
+}
+
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusPreconditionFailed:
+		return CodePreconditionFailed
+	case http.StatusTooManyRequests:
+		return CodeTooManyRequests
+	}
+
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
@@ -2,25 +2,311 @@ package database
 
 import (
 	"context"
+	"embed"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func RunMigrations(db *pgxpool.Pool) {
-	fmt.Println("📄 Running database migrations...")
+// Migrations are numbered files in migrations/, one pair per version:
+// 0007_add_thing.up.sql and 0007_add_thing.down.sql. They are embedded in the
+// binary, so the server no longer depends on its working directory.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// MigrationsDir is where `migrate create` writes new files, relative to the backend root
+const MigrationsDir = "internal/database/migrations"
+
+// migrationLockID is the advisory lock key held while migrating, so instances
+// starting at the same time don't apply the same migration twice
+const migrationLockID = 4_771_532_019
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one schema version with the SQL to apply and revert it
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus tells whether a migration has been applied and when
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// loadMigrations reads every embedded migration, sorted by version
+func loadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
 
-	content, err := os.ReadFile("sql/schema.sql")
+// withMigrationLock runs fn on a single connection holding the migration lock.
+// Advisory locks belong to a session, so everything has to go through that connection.
+func withMigrationLock(ctx context.Context, db *pgxpool.Pool, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("could not take migration lock: %w", err)
+	}
+	defer conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
 
+	_, err = conn.Exec(ctx, `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
 	if err != nil {
-		panic("Failed to read migration file from sql: " + err.Error())
+		return fmt.Errorf("could not create schema_migrations: %w", err)
 	}
 
-	_, err = db.Exec(context.Background(), string(content))
+	return fn(conn)
+}
 
+// appliedMigrations returns the applied versions with the time they were applied
+func appliedMigrations(ctx context.Context, conn *pgxpool.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.Query(ctx, "SELECT version, applied_at FROM schema_migrations")
 	if err != nil {
-		panic("Failed to read migration file from sql: " + err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]time.Time{}
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// runMigrationStep runs one migration's SQL and its bookkeeping in a single transaction
+func runMigrationStep(ctx context.Context, conn *pgxpool.Conn, sql, bookkeeping string, args ...any) error {
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(ctx, bookkeeping, args...)
+		return err
+	})
+}
+
+// MigrateUp applies every pending migration in order and returns the ones it applied
+func MigrateUp(ctx context.Context, db *pgxpool.Pool) ([]Migration, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	err = withMigrationLock(ctx, db, func(conn *pgxpool.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+
+			err := runMigrationStep(ctx, conn, m.Up, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.Version, m.Name)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", m.Version, m.Name, err)
+			}
+			done = append(done, m)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// MigrateDown reverts the last n applied migrations, newest first
+func MigrateDown(ctx context.Context, db *pgxpool.Pool, n int) ([]Migration, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]Migration, len(migrations))
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+
+	var done []Migration
+	err = withMigrationLock(ctx, db, func(conn *pgxpool.Conn) error {
+		rows, err := conn.Query(ctx, "SELECT version FROM schema_migrations ORDER BY version DESC LIMIT $1", n)
+		if err != nil {
+			return err
+		}
+		versions, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+		if err != nil {
+			return err
+		}
+
+		for _, version := range versions {
+			m, ok := byVersion[version]
+			if !ok {
+				return fmt.Errorf("migration %d is applied but its files are missing", version)
+			}
+			if m.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down file", m.Version, m.Name)
+			}
+
+			err := runMigrationStep(ctx, conn, m.Down, "DELETE FROM schema_migrations WHERE version = $1", m.Version)
+			if err != nil {
+				return fmt.Errorf("reverting migration %d_%s failed: %w", m.Version, m.Name, err)
+			}
+			done = append(done, m)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// MigrationStatuses lists every known migration and whether it has been applied
+func MigrationStatuses(ctx context.Context, db *pgxpool.Pool) ([]MigrationStatus, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	err = withMigrationLock(ctx, db, func(conn *pgxpool.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			status := MigrationStatus{Migration: m}
+			if appliedAt, ok := applied[m.Version]; ok {
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// CreateMigration writes an empty up/down pair to dir, numbered after the newest file there
+func CreateMigration(dir, name string) (string, string, error) {
+	name = strings.Trim(regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "", "", errors.New("migration name must contain letters or numbers")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	var latest int64
+	for _, entry := range entries {
+		if match := migrationFileName.FindStringSubmatch(entry.Name()); match != nil {
+			version, _ := strconv.ParseInt(match[1], 10, 64)
+			latest = max(latest, version)
+		}
+	}
+
+	base := fmt.Sprintf("%04d_%s", latest+1, name)
+	upPath := filepath.Join(dir, base+".up.sql")
+	downPath := filepath.Join(dir, base+".down.sql")
+
+	if err := os.WriteFile(upPath, []byte("-- Write the migration here\n"), 0o644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(downPath, []byte("-- Revert the up migration here\n"), 0o644); err != nil {
+		return "", "", err
+	}
+
+	return upPath, downPath, nil
+}
+
+// RunMigrations applies pending migrations at startup
+func RunMigrations(db *pgxpool.Pool) {
+	fmt.Println("📄 Running database migrations...")
+
+	applied, err := MigrateUp(context.Background(), db)
+
+	if err != nil {
+		panic("Failed to run migrations: " + err.Error())
+	}
+
+	for _, m := range applied {
+		fmt.Printf("   ⬆️  %04d_%s\n", m.Version, m.Name)
 	}
 
 	fmt.Println("✅ Migrations completed successfully!")
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS external_collaborators;
+DROP TABLE IF EXISTS starthub_collaborations;
+DROP TABLE IF EXISTS starthub_categories;
+DROP TABLE IF EXISTS starthubs;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS users;
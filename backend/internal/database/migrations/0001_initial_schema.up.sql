@@ -1,3 +1,6 @@
+-- Statements use IF NOT EXISTS so databases created by the old schema.sql
+-- runner can adopt this migration without errors.
+
 -- Enable UUID generation
 CREATE EXTENSION IF NOT EXISTS "pgcrypto";
 
@@ -54,5 +57,3 @@ CREATE TABLE IF NOT EXISTS external_collaborators (
     starthub_id UUID REFERENCES starthubs(id) ON DELETE CASCADE,
     name TEXT NOT NULL
 );
-
-
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_starthub_collaborations_pair;
+
+ALTER TABLE starthub_collaborations DROP COLUMN IF EXISTS responded_at;
+ALTER TABLE starthub_collaborations DROP COLUMN IF EXISTS created_at;
+ALTER TABLE starthub_collaborations DROP COLUMN IF EXISTS requested_by;
+ALTER TABLE starthub_collaborations DROP COLUMN IF EXISTS message;
+ALTER TABLE starthub_collaborations DROP COLUMN IF EXISTS status;
@@ -0,0 +1,14 @@
+-- Collaboration lifecycle: starthub_id proposes, collaborator_id accepts or declines.
+-- Only accepted rows show up on starthub profiles.
+ALTER TABLE starthub_collaborations ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('pending', 'accepted', 'declined', 'ended'));
+ALTER TABLE starthub_collaborations ADD COLUMN IF NOT EXISTS message TEXT;
+ALTER TABLE starthub_collaborations ADD COLUMN IF NOT EXISTS requested_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE starthub_collaborations ADD COLUMN IF NOT EXISTS created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE starthub_collaborations ADD COLUMN IF NOT EXISTS responded_at TIMESTAMP;
+
+-- A pair of starthubs can only have one collaboration, whoever proposed it
+CREATE UNIQUE INDEX IF NOT EXISTS idx_starthub_collaborations_pair ON starthub_collaborations (
+    LEAST(starthub_id, collaborator_id),
+    GREATEST(starthub_id, collaborator_id)
+);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_external_collaborators_starthub_id;
+
+ALTER TABLE external_collaborators DROP COLUMN IF EXISTS created_at;
+ALTER TABLE external_collaborators DROP COLUMN IF EXISTS position;
+ALTER TABLE external_collaborators DROP COLUMN IF EXISTS logo_url;
+ALTER TABLE external_collaborators DROP COLUMN IF EXISTS role;
+ALTER TABLE external_collaborators DROP COLUMN IF EXISTS url;
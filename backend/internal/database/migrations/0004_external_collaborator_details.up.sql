@@ -0,0 +1,8 @@
+-- External collaborator details, shown on the starthub profile in position order
+ALTER TABLE external_collaborators ADD COLUMN IF NOT EXISTS url TEXT;
+ALTER TABLE external_collaborators ADD COLUMN IF NOT EXISTS role TEXT;
+ALTER TABLE external_collaborators ADD COLUMN IF NOT EXISTS logo_url TEXT;
+ALTER TABLE external_collaborators ADD COLUMN IF NOT EXISTS position INT NOT NULL DEFAULT 0;
+ALTER TABLE external_collaborators ADD COLUMN IF NOT EXISTS created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_external_collaborators_starthub_id ON external_collaborators(starthub_id, position);
@@ -0,0 +1,4 @@
+-- Fails while admin users exist, demote them first
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('starthub', 'investor', 'donator', 'collaborator'));
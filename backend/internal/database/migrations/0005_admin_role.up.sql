@@ -0,0 +1,5 @@
+-- Admins curate shared data like categories. They are promoted directly in the
+-- database and can't be picked at sign-up.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('starthub', 'investor', 'donator', 'collaborator', 'admin'));
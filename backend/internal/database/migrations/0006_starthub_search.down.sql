@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS trg_categories_search ON categories;
+DROP TRIGGER IF EXISTS trg_starthub_categories_search ON starthub_categories;
+DROP FUNCTION IF EXISTS categories_search_trigger();
+DROP FUNCTION IF EXISTS starthub_categories_search_trigger();
+DROP FUNCTION IF EXISTS refresh_starthub_categories_text(UUID);
+
+DROP INDEX IF EXISTS idx_starthubs_search_vector;
+ALTER TABLE starthubs DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE starthubs DROP COLUMN IF EXISTS categories_text;
@@ -0,0 +1,59 @@
+-- Full-text search over name, categories, description and location.
+-- Category names live in another table, so triggers copy them into
+-- categories_text where the generated search_vector can see them.
+ALTER TABLE starthubs ADD COLUMN IF NOT EXISTS categories_text TEXT NOT NULL DEFAULT '';
+ALTER TABLE starthubs ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(categories_text, '')), 'B') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'C') ||
+    setweight(to_tsvector('english', COALESCE(location, '')), 'D')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_starthubs_search_vector ON starthubs USING GIN (search_vector);
+
+CREATE OR REPLACE FUNCTION refresh_starthub_categories_text(target UUID) RETURNS VOID AS $$
+    UPDATE starthubs SET categories_text = COALESCE((
+        SELECT string_agg(c.name, ' ' ORDER BY c.name)
+        FROM starthub_categories sc
+        JOIN categories c ON c.id = sc.category_id
+        WHERE sc.starthub_id = target
+    ), '')
+    WHERE id = target;
+$$ LANGUAGE sql;
+
+CREATE OR REPLACE FUNCTION starthub_categories_search_trigger() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM refresh_starthub_categories_text(NEW.starthub_id);
+    END IF;
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM refresh_starthub_categories_text(OLD.starthub_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_starthub_categories_search ON starthub_categories;
+CREATE TRIGGER trg_starthub_categories_search
+    AFTER INSERT OR UPDATE OR DELETE ON starthub_categories
+    FOR EACH ROW EXECUTE FUNCTION starthub_categories_search_trigger();
+
+-- Renaming a category changes the text of every starthub using it
+CREATE OR REPLACE FUNCTION categories_search_trigger() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM refresh_starthub_categories_text(sc.starthub_id)
+    FROM starthub_categories sc
+    WHERE sc.category_id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_categories_search ON categories;
+CREATE TRIGGER trg_categories_search
+    AFTER UPDATE OF name ON categories
+    FOR EACH ROW EXECUTE FUNCTION categories_search_trigger();
+
+-- Backfill starthubs whose categories were linked before the triggers existed
+SELECT refresh_starthub_categories_text(s.id)
+FROM starthubs s
+WHERE s.categories_text = '' AND EXISTS (SELECT 1 FROM starthub_categories sc WHERE sc.starthub_id = s.id);
@@ -0,0 +1,2 @@
+ALTER TABLE starthubs DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE starthubs DROP COLUMN IF EXISTS version;
@@ -0,0 +1,7 @@
+-- Every write to a starthub bumps its version, which clients see as the ETag
+-- and send back in If-Match so concurrent edits don't overwrite each other.
+ALTER TABLE starthubs ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE starthubs ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- Existing starthubs were last changed no later than they joined, as far as we know
+UPDATE starthubs SET updated_at = join_date WHERE join_date IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS starthub_invitations;
+DROP TABLE IF EXISTS starthub_members;
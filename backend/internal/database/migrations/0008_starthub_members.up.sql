@@ -0,0 +1,39 @@
+-- Starthubs are run by a team instead of the single user in created_by.
+-- Owners manage the team and can delete the starthub, editors change its
+-- profile and collaborations, viewers only see what the team sees.
+CREATE TABLE IF NOT EXISTS starthub_members (
+    starthub_id UUID NOT NULL REFERENCES starthubs(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (starthub_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_starthub_members_user_id ON starthub_members(user_id);
+
+-- Whoever created a starthub owns it. Starthubs whose creator is already gone
+-- have no owner and can only be claimed in the database.
+INSERT INTO starthub_members (starthub_id, user_id, role, created_at)
+SELECT id, created_by, 'owner', COALESCE(join_date, NOW())
+FROM starthubs
+WHERE created_by IS NOT NULL
+ON CONFLICT (starthub_id, user_id) DO NOTHING;
+
+-- Invitations are accepted with a token sent to the invited email. Only the
+-- SHA-256 of the token is stored, like refresh tokens.
+CREATE TABLE IF NOT EXISTS starthub_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    starthub_id UUID NOT NULL REFERENCES starthubs(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    token_hash TEXT UNIQUE NOT NULL,
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ
+);
+
+-- One open invitation per email and starthub
+CREATE UNIQUE INDEX IF NOT EXISTS idx_starthub_invitations_pending
+    ON starthub_invitations (starthub_id, LOWER(email))
+    WHERE accepted_at IS NULL;
@@ -0,0 +1,15 @@
+-- Investors track the starthubs they are interested in through a private
+-- pipeline. Starthub teams only ever see how many investors are interested.
+CREATE TABLE IF NOT EXISTS investor_interests (
+    investor_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    starthub_id UUID NOT NULL REFERENCES starthubs(id) ON DELETE CASCADE,
+    stage TEXT NOT NULL DEFAULT 'watching'
+        CHECK (stage IN ('watching', 'contacted', 'due_diligence', 'passed', 'invested')),
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (investor_id, starthub_id)
+);
+
+-- Counting the interest in a starthub
+CREATE INDEX IF NOT EXISTS idx_investor_interests_starthub_id ON investor_interests(starthub_id);
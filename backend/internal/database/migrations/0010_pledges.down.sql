@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pledges;
+DROP TABLE IF EXISTS funding_goals;
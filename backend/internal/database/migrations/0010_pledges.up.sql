@@ -0,0 +1,33 @@
+-- Money is stored as integer minor units (cents) with its ISO 4217 currency,
+-- totals are only ever added up per currency.
+CREATE TABLE IF NOT EXISTS funding_goals (
+    id SERIAL PRIMARY KEY,
+    starthub_id UUID NOT NULL REFERENCES starthubs(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency TEXT NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    deadline DATE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_funding_goals_starthub_id ON funding_goals(starthub_id);
+
+-- A pledge is stored as pending before it is charged, so a charge never
+-- happens without a record of it. The pledge id is the idempotency key.
+CREATE TABLE IF NOT EXISTS pledges (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    donator_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    starthub_id UUID NOT NULL REFERENCES starthubs(id) ON DELETE CASCADE,
+    goal_id INT REFERENCES funding_goals(id) ON DELETE SET NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency TEXT NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    message TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid', 'failed')),
+    provider TEXT NOT NULL,
+    payment_id TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pledges_donator_id ON pledges(donator_id);
+CREATE INDEX IF NOT EXISTS idx_pledges_starthub_id ON pledges(starthub_id) WHERE status = 'paid';
+CREATE INDEX IF NOT EXISTS idx_pledges_goal_id ON pledges(goal_id) WHERE status = 'paid';
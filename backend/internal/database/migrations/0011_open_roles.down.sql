@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS role_applications;
+DROP TABLE IF EXISTS open_roles;
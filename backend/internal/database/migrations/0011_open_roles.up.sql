@@ -0,0 +1,38 @@
+-- Starthubs post open roles that collaborators apply to. Skills are stored
+-- lowercase so they can be matched exactly.
+CREATE TABLE IF NOT EXISTS open_roles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    starthub_id UUID NOT NULL REFERENCES starthubs(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    skills TEXT[] NOT NULL DEFAULT '{}',
+    remote BOOLEAN NOT NULL DEFAULT FALSE,
+    location TEXT NOT NULL DEFAULT '',
+    commitment TEXT NOT NULL CHECK (commitment IN ('full_time', 'part_time', 'contract', 'volunteer')),
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_open_roles_starthub_id ON open_roles(starthub_id);
+CREATE INDEX IF NOT EXISTS idx_open_roles_skills ON open_roles USING GIN (skills) WHERE status = 'open';
+
+-- The name and url are what the applicant wants shown as an external
+-- collaborator of the starthub once they are accepted.
+CREATE TABLE IF NOT EXISTS role_applications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    role_id UUID NOT NULL REFERENCES open_roles(id) ON DELETE CASCADE,
+    applicant_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'accepted', 'rejected', 'withdrawn')),
+    external_collaborator_id INT REFERENCES external_collaborators(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    decided_at TIMESTAMPTZ
+);
+
+-- One application per role and applicant, unless they withdrew it
+CREATE UNIQUE INDEX IF NOT EXISTS idx_role_applications_active
+    ON role_applications(role_id, applicant_id) WHERE status <> 'withdrawn';
+CREATE INDEX IF NOT EXISTS idx_role_applications_applicant_id ON role_applications(applicant_id);
@@ -0,0 +1,10 @@
+DELETE FROM login_attempts WHERE reason = 'wrong_mfa_code';
+ALTER TABLE login_attempts DROP CONSTRAINT IF EXISTS login_attempts_reason_check;
+ALTER TABLE login_attempts ADD CONSTRAINT login_attempts_reason_check
+    CHECK (reason IN ('unknown_account', 'wrong_password', 'locked'));
+
+DROP TABLE IF EXISTS mfa_challenges;
+DROP TABLE IF EXISTS recovery_codes;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_last_step;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_enabled_at;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_secret;
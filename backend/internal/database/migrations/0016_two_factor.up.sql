@@ -0,0 +1,32 @@
+-- TOTP two-factor authentication. The secret is set when enrollment starts and
+-- only counts once a code from it was confirmed (totp_enabled_at). The last
+-- step that was used is kept so a code can't be replayed.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step BIGINT;
+
+-- One-time codes for when the authenticator is lost, only their SHA-256 is stored
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMPTZ,
+    UNIQUE (user_id, code_hash)
+);
+
+-- Sign-ins that passed the password and wait for a second factor
+CREATE TABLE IF NOT EXISTS mfa_challenges (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT UNIQUE NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_challenges_user_id ON mfa_challenges(user_id);
+
+ALTER TABLE login_attempts DROP CONSTRAINT IF EXISTS login_attempts_reason_check;
+ALTER TABLE login_attempts ADD CONSTRAINT login_attempts_reason_check
+    CHECK (reason IN ('unknown_account', 'wrong_password', 'locked', 'wrong_mfa_code'));
@@ -0,0 +1,4 @@
+-- The refresh token hash a session had before its last rotation. Presenting
+-- it again means the token leaked, while a secret that never belonged to the
+-- session is just refused, so knowing a session id can't sign anyone out.
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS previous_token_hash TEXT;
@@ -0,0 +1 @@
+-- Collaborations set to accepted stay accepted
@@ -0,0 +1,8 @@
+-- 0003 gave the collaborations from before the lifecycle the pending default,
+-- which hid them from profiles. Those rows got their created_at when 0003 ran,
+-- in the same transaction that recorded it, and nobody requested them.
+UPDATE starthub_collaborations
+SET status = 'accepted'
+WHERE status = 'pending'
+  AND requested_by IS NULL
+  AND created_at <= (SELECT applied_at FROM schema_migrations WHERE version = 3);
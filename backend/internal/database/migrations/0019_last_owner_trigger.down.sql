@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS trg_users_last_owner ON users;
+DROP FUNCTION IF EXISTS users_last_owner_trigger();
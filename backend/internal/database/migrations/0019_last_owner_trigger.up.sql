@@ -0,0 +1,26 @@
+-- Deleting a user cascades to their memberships, which must not leave a
+-- starthub without an owner. The app refuses that with ErrLastOwner, this
+-- covers users deleted straight in the database.
+CREATE OR REPLACE FUNCTION users_last_owner_trigger() RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1
+        FROM starthub_members m
+        WHERE m.user_id = OLD.id AND m.role = 'owner'
+          AND NOT EXISTS (
+              SELECT 1 FROM starthub_members other
+              WHERE other.starthub_id = m.starthub_id AND other.role = 'owner' AND other.user_id <> OLD.id
+          )
+    ) THEN
+        RAISE EXCEPTION 'user % is the last owner of a starthub', OLD.id
+            USING ERRCODE = 'restrict_violation',
+                  HINT = 'Make someone else an owner or delete the starthub first';
+    END IF;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_users_last_owner ON users;
+CREATE TRIGGER trg_users_last_owner
+    BEFORE DELETE ON users
+    FOR EACH ROW EXECUTE FUNCTION users_last_owner_trigger();
@@ -0,0 +1,8 @@
+-- Pledges of deleted starthubs would have been deleted with them
+DELETE FROM pledges WHERE starthub_id IS NULL;
+
+ALTER TABLE pledges DROP CONSTRAINT IF EXISTS pledges_starthub_id_fkey;
+ALTER TABLE pledges ADD CONSTRAINT pledges_starthub_id_fkey
+    FOREIGN KEY (starthub_id) REFERENCES starthubs(id) ON DELETE CASCADE;
+ALTER TABLE pledges ALTER COLUMN starthub_id SET NOT NULL;
+ALTER TABLE pledges DROP COLUMN IF EXISTS starthub_name;
@@ -0,0 +1,13 @@
+-- Pledges are payment records and outlive their starthub, which is why its
+-- name is kept on the pledge
+ALTER TABLE pledges ADD COLUMN IF NOT EXISTS starthub_name TEXT;
+
+UPDATE pledges p SET starthub_name = s.name
+FROM starthubs s
+WHERE s.id = p.starthub_id AND p.starthub_name IS NULL;
+
+ALTER TABLE pledges ALTER COLUMN starthub_name SET NOT NULL;
+ALTER TABLE pledges ALTER COLUMN starthub_id DROP NOT NULL;
+ALTER TABLE pledges DROP CONSTRAINT IF EXISTS pledges_starthub_id_fkey;
+ALTER TABLE pledges ADD CONSTRAINT pledges_starthub_id_fkey
+    FOREIGN KEY (starthub_id) REFERENCES starthubs(id) ON DELETE SET NULL;
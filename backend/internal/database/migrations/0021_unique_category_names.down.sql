@@ -0,0 +1,2 @@
+-- Merged categories stay merged
+DROP INDEX IF EXISTS idx_categories_lower_name;
@@ -0,0 +1,20 @@
+-- Categories are matched case-insensitively so "AI" and "ai" end up as one, and
+-- their names are unique in any letter case so concurrent renames and creates
+-- can't fork a category. Case variants stored before are merged into the
+-- oldest one first. Databases that ran 0005 before it stopped creating a plain
+-- index on LOWER(name) have one, which is replaced.
+INSERT INTO starthub_categories (starthub_id, category_id)
+SELECT sc.starthub_id, keep.id
+FROM starthub_categories sc
+JOIN categories c ON c.id = sc.category_id
+JOIN LATERAL (
+    SELECT MIN(k.id) AS id FROM categories k WHERE LOWER(k.name) = LOWER(c.name)
+) keep ON keep.id <> c.id
+ON CONFLICT (starthub_id, category_id) DO NOTHING;
+
+DELETE FROM categories c
+USING categories keep
+WHERE LOWER(keep.name) = LOWER(c.name) AND keep.id < c.id;
+
+DROP INDEX IF EXISTS idx_categories_lower_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_lower_name ON categories (LOWER(name));
@@ -0,0 +1,10 @@
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS previous_token_hash TEXT;
+
+UPDATE sessions s SET previous_token_hash = (
+    SELECT r.token_hash FROM session_retired_tokens r
+    WHERE r.session_id = s.id
+    ORDER BY r.retired_at DESC
+    LIMIT 1
+);
+
+DROP TABLE IF EXISTS session_retired_tokens;
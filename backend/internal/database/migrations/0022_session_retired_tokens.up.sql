@@ -0,0 +1,15 @@
+-- Every refresh token hash a session rotated away, not only the last one.
+-- Presenting any of them again means the token leaked, while a secret that
+-- never belonged to the session is just refused.
+CREATE TABLE IF NOT EXISTS session_retired_tokens (
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL,
+    retired_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (session_id, token_hash)
+);
+
+INSERT INTO session_retired_tokens (session_id, token_hash)
+SELECT id, previous_token_hash FROM sessions WHERE previous_token_hash IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS previous_token_hash;
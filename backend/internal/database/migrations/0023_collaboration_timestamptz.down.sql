@@ -0,0 +1,2 @@
+ALTER TABLE starthub_collaborations ALTER COLUMN responded_at TYPE TIMESTAMP;
+ALTER TABLE starthub_collaborations ALTER COLUMN created_at TYPE TIMESTAMP;
@@ -0,0 +1,3 @@
+-- Collaboration times are stored with their time zone, like the other tables
+ALTER TABLE starthub_collaborations ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE starthub_collaborations ALTER COLUMN responded_at TYPE TIMESTAMPTZ;
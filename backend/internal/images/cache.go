@@ -0,0 +1,74 @@
+package images
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxCacheEntries bounds the cache, expired entries are dropped first when it is full
+const maxCacheEntries = 1000
+
+// Cache remembers the images found by a provider, keyed by the normalized
+// query. Only hits are cached, so a failing API is asked again next time.
+type Cache struct {
+	provider Provider
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	url     string
+	expires time.Time
+}
+
+func NewCache(provider Provider, ttl time.Duration) *Cache {
+	return &Cache{provider: provider, ttl: ttl, entries: map[string]cacheEntry{}}
+}
+
+func (c *Cache) Name() string { return "cache(" + c.provider.Name() + ")" }
+
+func (c *Cache) Image(ctx context.Context, query string) (string, error) {
+	key := normalize(query)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.url, nil
+	}
+
+	url, err := c.provider.Image(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCacheEntries {
+		c.evict()
+	}
+	c.entries[key] = cacheEntry{url: url, expires: time.Now().Add(c.ttl)}
+
+	return url, nil
+}
+
+// evict makes room for a new entry. The caller holds the lock.
+func (c *Cache) evict() {
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+
+	// Nothing expired, drop an arbitrary entry
+	for key := range c.entries {
+		if len(c.entries) < maxCacheEntries {
+			break
+		}
+		delete(c.entries, key)
+	}
+}
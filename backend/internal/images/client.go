@@ -0,0 +1,99 @@
+package images
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// StatusError is returned when an image API answers with a non-200 status
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d", e.Code)
+}
+
+// Client calls image APIs. Every attempt has its own timeout, and attempts that
+// failed for a reason that may go away (network errors, 429 and 5xx) are retried
+// with exponential backoff until Attempts is reached or the context is done.
+type Client struct {
+	HTTP     *http.Client
+	Attempts int
+	Backoff  time.Duration
+}
+
+// NewClient returns a client with a 5 second timeout and 3 attempts
+func NewClient() *Client {
+	return &Client{
+		HTTP:     &http.Client{Timeout: 5 * time.Second},
+		Attempts: 3,
+		Backoff:  200 * time.Millisecond,
+	}
+}
+
+// getJSON sends a GET request and decodes the JSON response into out
+func (c *Client) getJSON(ctx context.Context, url string, header http.Header, out any) error {
+	var err error
+	for attempt := 0; attempt < max(c.Attempts, 1); attempt++ {
+		if attempt > 0 {
+			wait := c.Backoff << (attempt - 1)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+
+		err = c.get(ctx, url, header, out)
+		if err == nil || !retryable(ctx, err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (c *Client) get(ctx context.Context, url string, header http.Header, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{Code: resp.StatusCode}
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// retryable reports whether a failed attempt is worth repeating
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.Code == http.StatusTooManyRequests || status.Code >= 500
+	}
+
+	// A bad response body won't get better, anything else is a transport error
+	var syntax *json.SyntaxError
+	var typ *json.UnmarshalTypeError
+	return !errors.As(err, &syntax) && !errors.As(err, &typ)
+}
@@ -0,0 +1,79 @@
+// Package images finds a cover image for a starthub based on its category.
+package images
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrNoImage is returned when a provider has no image for a query
+var ErrNoImage = errors.New("no image found")
+
+// Provider returns the URL of an image that matches a search query
+type Provider interface {
+	Name() string
+	Image(ctx context.Context, query string) (string, error)
+}
+
+// DefaultQuery is searched when a starthub has no categories
+const DefaultQuery = "startup"
+
+// normalize turns a query into the form providers and the cache see
+func normalize(query string) string {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return DefaultQuery
+	}
+	return query
+}
+
+// Chain tries each provider in order and returns the first image found
+type Chain []Provider
+
+func (c Chain) Name() string { return "chain" }
+
+func (c Chain) Image(ctx context.Context, query string) (string, error) {
+	for _, p := range c {
+		url, err := p.Image(ctx, query)
+		if err == nil {
+			return url, nil
+		}
+		if !errors.Is(err, ErrNoImage) {
+			log.Printf("❌ %s image lookup for '%s' failed: %v", p.Name(), query, err)
+		}
+	}
+
+	return "", ErrNoImage
+}
+
+// FromEnv builds the provider used by the server: Pexels when PEXELS_API_KEY is
+// set, then Unsplash when UNSPLASH_ACCESS_KEY is set, then a placeholder so
+// every starthub gets an image. Images found by the APIs are cached for a day.
+func FromEnv() Provider {
+	var apis []Provider
+	if key := os.Getenv("PEXELS_API_KEY"); key != "" {
+		apis = append(apis, NewPexels(key))
+	}
+	if key := os.Getenv("UNSPLASH_ACCESS_KEY"); key != "" {
+		apis = append(apis, NewUnsplash(key))
+	}
+	if len(apis) == 0 {
+		log.Printf("⚠️  No image API keys found, starthubs get placeholder images")
+	}
+
+	return WithPlaceholder(apis, 24*time.Hour)
+}
+
+// WithPlaceholder caches what the APIs find for ttl and falls back to a
+// placeholder outside the cache, so a failing API is asked again next time
+// instead of the placeholder being served until the entry expires.
+func WithPlaceholder(apis []Provider, ttl time.Duration) Provider {
+	if len(apis) == 0 {
+		return Placeholder{}
+	}
+	return Chain{NewCache(Chain(apis), ttl), Placeholder{}}
+}
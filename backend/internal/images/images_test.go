@@ -0,0 +1,171 @@
+package images_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ecetinerdem/starthub-backend/internal/images"
+	"github.com/ecetinerdem/starthub-backend/internal/images/imagestest"
+)
+
+// fastClient retries without waiting long, so failing tests stay quick
+func fastClient() *images.Client {
+	return &images.Client{
+		HTTP:     &http.Client{Timeout: 200 * time.Millisecond},
+		Attempts: 3,
+		Backoff:  time.Millisecond,
+	}
+}
+
+func newPexels(server *imagestest.Server) *images.Pexels {
+	return &images.Pexels{APIKey: "key", BaseURL: server.URL, Client: fastClient()}
+}
+
+func TestProviders(t *testing.T) {
+	server := imagestest.NewServer(t, map[string]string{"clean energy": "https://img.test/energy.jpg"})
+
+	providers := []images.Provider{
+		newPexels(server),
+		&images.Unsplash{AccessKey: "key", BaseURL: server.URL, Client: fastClient()},
+	}
+
+	for _, p := range providers {
+		t.Run(p.Name(), func(t *testing.T) {
+			// The query is normalized and sent URL encoded
+			url, err := p.Image(context.Background(), "  Clean Energy ")
+			if err != nil || url != "https://img.test/energy.jpg" {
+				t.Errorf("got %q, %v", url, err)
+			}
+
+			if _, err := p.Image(context.Background(), "nothing"); !errors.Is(err, images.ErrNoImage) {
+				t.Errorf("got %v, want ErrNoImage", err)
+			}
+		})
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		wantErr  bool
+	}{
+		{"recovers", 2, false},
+		{"gives up", 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := imagestest.NewServer(t, map[string]string{"ai": "https://img.test/ai.jpg"})
+			server.FailNext(tt.failures)
+
+			_, err := newPexels(server).Image(context.Background(), "ai")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if got := len(server.Queries()); got != 3 {
+				t.Errorf("got %d requests, want 3", got)
+			}
+		})
+	}
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		server := imagestest.NewServer(t, nil)
+		p := newPexels(server)
+		p.APIKey = ""
+
+		var status *images.StatusError
+		if _, err := p.Image(context.Background(), "ai"); !errors.As(err, &status) || status.Code != http.StatusUnauthorized {
+			t.Errorf("got %v, want a 401", err)
+		}
+		if got := len(server.Queries()); got != 1 {
+			t.Errorf("got %d requests, want 1", got)
+		}
+	})
+}
+
+func TestClientTimeout(t *testing.T) {
+	server := imagestest.NewServer(t, map[string]string{"ai": "https://img.test/ai.jpg"})
+	server.Delay(time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := newPexels(server).Image(ctx, "ai"); err == nil {
+		t.Fatal("expected the lookup to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("lookup took %v after its context was done", elapsed)
+	}
+}
+
+func TestCache(t *testing.T) {
+	server := imagestest.NewServer(t, map[string]string{"fintech": "https://img.test/fintech.jpg"})
+	cache := images.NewCache(newPexels(server), time.Hour)
+
+	for _, query := range []string{"Fintech", "fintech ", "FINTECH"} {
+		if url, err := cache.Image(context.Background(), query); err != nil || url != "https://img.test/fintech.jpg" {
+			t.Fatalf("got %q, %v", url, err)
+		}
+	}
+	if got := server.Queries(); !slices.Equal(got, []string{"fintech"}) {
+		t.Errorf("got queries %v, want one lookup", got)
+	}
+
+	// Misses are asked again
+	cache.Image(context.Background(), "unknown")
+	cache.Image(context.Background(), "unknown")
+	if got := len(server.Queries()); got != 3 {
+		t.Errorf("got %d requests, want 3", got)
+	}
+}
+
+func TestChainFallsBackToPlaceholder(t *testing.T) {
+	server := imagestest.NewServer(t, map[string]string{"health": "https://img.test/health.jpg"})
+	chain := images.Chain{newPexels(server), images.Placeholder{}}
+
+	if url, _ := chain.Image(context.Background(), "health"); url != "https://img.test/health.jpg" {
+		t.Errorf("got %q", url)
+	}
+
+	first, err := chain.Image(context.Background(), "underwater basket weaving")
+	if err != nil || !strings.HasPrefix(first, "data:image/svg+xml;base64,") {
+		t.Fatalf("got %q, %v", first, err)
+	}
+
+	// Placeholders are stable and differ between queries
+	again, _ := images.Placeholder{}.Image(context.Background(), "Underwater Basket Weaving")
+	other, _ := images.Placeholder{}.Image(context.Background(), "health")
+	if again != first || other == first {
+		t.Errorf("placeholders are not deterministic per query")
+	}
+}
+
+func TestPlaceholderIsNotCached(t *testing.T) {
+	server := imagestest.NewServer(t, map[string]string{"biotech": "https://img.test/biotech.jpg"})
+	provider := images.WithPlaceholder([]images.Provider{newPexels(server)}, time.Hour)
+
+	// A failing API falls back to the placeholder, and is asked again next time
+	server.FailNext(3)
+	if url, err := provider.Image(context.Background(), "biotech"); err != nil || !strings.HasPrefix(url, "data:image/svg+xml;base64,") {
+		t.Fatalf("got %q, %v, want a placeholder", url, err)
+	}
+	if url, err := provider.Image(context.Background(), "biotech"); err != nil || url != "https://img.test/biotech.jpg" {
+		t.Errorf("got %q, %v after the API recovered", url, err)
+	}
+	if got := len(server.Queries()); got != 4 {
+		t.Errorf("got %d requests, want 4", got)
+	}
+
+	// The image found is cached from then on
+	provider.Image(context.Background(), "biotech")
+	if got := len(server.Queries()); got != 4 {
+		t.Errorf("got %d requests after a cached lookup, want 4", got)
+	}
+}
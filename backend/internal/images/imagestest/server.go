@@ -0,0 +1,107 @@
+// Package imagestest runs a local server that answers like the Pexels and
+// Unsplash search APIs, so image providers can be tested without the network.
+package imagestest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Server is a fake image API. Photos maps a search query to the image URL it
+// returns, any other query finds nothing.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	photos   map[string]string
+	failures int
+	delay    time.Duration
+	queries  []string
+}
+
+// NewServer starts a fake image API that is closed when the test ends
+func NewServer(t testing.TB, photos map[string]string) *Server {
+	s := &Server{photos: photos}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/search", s.handle(func(url string) any {
+		resp := map[string]any{"photos": []any{}}
+		if url != "" {
+			resp["photos"] = []any{map[string]any{"src": map[string]string{"medium": url}}}
+		}
+		return resp
+	}))
+	mux.HandleFunc("GET /search/photos", s.handle(func(url string) any {
+		resp := map[string]any{"results": []any{}}
+		if url != "" {
+			resp["results"] = []any{map[string]any{"urls": map[string]string{"small": url}}}
+		}
+		return resp
+	}))
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// FailNext makes the next n requests answer 503
+func (s *Server) FailNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = n
+}
+
+// Delay makes every request wait d before answering
+func (s *Server) Delay(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay = d
+}
+
+// Queries returns the search queries received so far, failed ones included
+func (s *Server) Queries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.queries...)
+}
+
+func (s *Server) handle(respond func(url string) any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+
+		s.mu.Lock()
+		s.queries = append(s.queries, query)
+		delay := s.delay
+		fail := s.failures > 0
+		if fail {
+			s.failures--
+		}
+		url := s.photos[query]
+		s.mu.Unlock()
+
+		if delay > 0 {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(delay):
+			}
+		}
+
+		if r.Header.Get("Authorization") == "" {
+			http.Error(w, "missing api key", http.StatusUnauthorized)
+			return
+		}
+		if fail {
+			http.Error(w, "try again later", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(respond(url))
+	}
+}
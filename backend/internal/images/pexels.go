@@ -0,0 +1,45 @@
+package images
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Pexels finds images with the Pexels search API
+type Pexels struct {
+	APIKey  string
+	BaseURL string
+	Client  *Client
+}
+
+func NewPexels(apiKey string) *Pexels {
+	return &Pexels{APIKey: apiKey, BaseURL: "https://api.pexels.com", Client: NewClient()}
+}
+
+// pexelsResponse is the part of a Pexels search response we use
+type pexelsResponse struct {
+	Photos []struct {
+		Src struct {
+			Medium string `json:"medium"`
+		} `json:"src"`
+	} `json:"photos"`
+}
+
+func (p *Pexels) Name() string { return "pexels" }
+
+func (p *Pexels) Image(ctx context.Context, query string) (string, error) {
+	params := url.Values{"query": {normalize(query)}, "per_page": {"1"}}
+	header := http.Header{"Authorization": {p.APIKey}}
+
+	var resp pexelsResponse
+	if err := p.Client.getJSON(ctx, p.BaseURL+"/v1/search?"+params.Encode(), header, &resp); err != nil {
+		return "", err
+	}
+
+	if len(resp.Photos) == 0 || resp.Photos[0].Src.Medium == "" {
+		return "", ErrNoImage
+	}
+
+	return resp.Photos[0].Src.Medium, nil
+}
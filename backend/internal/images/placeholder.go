@@ -0,0 +1,47 @@
+package images
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// Placeholder draws an identicon for the query: a mirrored 5x5 grid in a color
+// picked from the query's hash. The same query always gives the same image and
+// it never calls out, so it is the last provider in every chain.
+type Placeholder struct{}
+
+func (Placeholder) Name() string { return "placeholder" }
+
+func (Placeholder) Image(ctx context.Context, query string) (string, error) {
+	return "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(identicon(normalize(query)))), nil
+}
+
+// identicon returns the SVG of the query's identicon
+func identicon(query string) string {
+	hash := sha256.Sum256([]byte(query))
+	hue := int(hash[0]) * 360 / 256
+	color := fmt.Sprintf("hsl(%d,55%%,50%%)", hue)
+
+	var svg strings.Builder
+	svg.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 5 5" shape-rendering="crispEdges">`)
+	svg.WriteString(`<rect width="5" height="5" fill="#f0f0f0"/>`)
+
+	// The left three columns come from the hash, the right two mirror them
+	for row := 0; row < 5; row++ {
+		for col := 0; col < 3; col++ {
+			if hash[1+row*3+col]&1 == 0 {
+				continue
+			}
+			fmt.Fprintf(&svg, `<rect x="%d" y="%d" width="1" height="1" fill="%s"/>`, col, row, color)
+			if col < 2 {
+				fmt.Fprintf(&svg, `<rect x="%d" y="%d" width="1" height="1" fill="%s"/>`, 4-col, row, color)
+			}
+		}
+	}
+
+	svg.WriteString(`</svg>`)
+	return svg.String()
+}
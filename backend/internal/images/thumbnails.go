@@ -0,0 +1,154 @@
+package images
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // decodes the first frame of GIFs
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// Upload limits. Dimensions are checked before decoding so a small file can't
+// expand into a huge image in memory.
+const (
+	MaxUploadBytes = 5 << 20
+	MaxDimension   = 6000
+)
+
+var (
+	ErrUnsupportedType = errors.New("only JPEG, PNG and GIF images are supported")
+	ErrTooLarge        = fmt.Errorf("images can be at most %dx%d pixels", MaxDimension, MaxDimension)
+)
+
+// Sizes are the thumbnails made of every upload, by the length of their longest side
+var Sizes = []struct {
+	Name string
+	Max  int
+}{
+	{"large", 1024},
+	{"medium", 512},
+	{"small", 128},
+}
+
+// Thumbnail is one encoded size of an uploaded image
+type Thumbnail struct {
+	Size        string
+	ContentType string
+	Ext         string
+	Data        []byte
+}
+
+// Thumbnails checks an uploaded image and returns it in every size of Sizes.
+// Images are never scaled up. JPEGs stay JPEGs, anything else becomes a PNG so
+// logos keep their transparency. Re-encoding also drops metadata like EXIF.
+func Thumbnails(data []byte) ([]Thumbnail, error) {
+	// Step 1: Trust the bytes, not the file name or the client's content type
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+	default:
+		return nil, ErrUnsupportedType
+	}
+
+	// Step 2: Check the dimensions before decoding the pixels
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedType
+	}
+	if config.Width > MaxDimension || config.Height > MaxDimension {
+		return nil, ErrTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedType
+	}
+
+	encode, ext := encodePNG, "png"
+	if contentType == "image/jpeg" {
+		encode, ext = encodeJPEG, "jpg"
+	} else {
+		contentType = "image/png"
+	}
+
+	// Step 3: Scale down size by size, each one from the previous
+	thumbnails := make([]Thumbnail, 0, len(Sizes))
+	current := toRGBA(src)
+	for _, size := range Sizes {
+		current = shrink(current, size.Max)
+
+		var buf bytes.Buffer
+		if err := encode(&buf, current); err != nil {
+			return nil, err
+		}
+		thumbnails = append(thumbnails, Thumbnail{Size: size.Name, ContentType: contentType, Ext: ext, Data: buf.Bytes()})
+	}
+
+	return thumbnails, nil
+}
+
+func encodeJPEG(buf *bytes.Buffer, img image.Image) error {
+	return jpeg.Encode(buf, img, &jpeg.Options{Quality: 85})
+}
+
+func encodePNG(buf *bytes.Buffer, img image.Image) error {
+	return png.Encode(buf, img)
+}
+
+// toRGBA copies img into an RGBA image starting at 0,0
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// shrink scales img down so its longest side is at most longest, averaging
+// every source pixel that falls in a target pixel
+func shrink(img *image.RGBA, longest int) *image.RGBA {
+	sw, sh := img.Bounds().Dx(), img.Bounds().Dy()
+	if sw <= longest && sh <= longest {
+		return img
+	}
+
+	dw, dh := longest, sh*longest/sw
+	if sh > sw {
+		dw, dh = sw*longest/sh, longest
+	}
+	dw, dh = max(dw, 1), max(dh, 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0 := y * sh / dh
+		y1 := max((y+1)*sh/dh, y0+1)
+		for x := 0; x < dw; x++ {
+			x0 := x * sw / dw
+			x1 := max((x+1)*sw/dw, x0+1)
+
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				row := img.Pix[sy*img.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += int(p[0])
+					g += int(p[1])
+					b += int(p[2])
+					a += int(p[3])
+					n++
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,108 @@
+package images_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/ecetinerdem/starthub-backend/internal/images"
+)
+
+func encoded(t *testing.T, w, h int, asJPEG bool) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 200, 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if asJPEG {
+		jpeg.Encode(&buf, img, nil)
+	} else {
+		png.Encode(&buf, img)
+	}
+	return buf.Bytes()
+}
+
+func TestThumbnails(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		wantType string
+		want     map[string][2]int
+	}{
+		{
+			name:     "wide jpeg",
+			data:     encoded(t, 2000, 1000, true),
+			wantType: "image/jpeg",
+			want:     map[string][2]int{"large": {1024, 512}, "medium": {512, 256}, "small": {128, 64}},
+		},
+		{
+			name:     "small png is not scaled up",
+			data:     encoded(t, 300, 400, false),
+			wantType: "image/png",
+			want:     map[string][2]int{"large": {300, 400}, "medium": {300, 400}, "small": {96, 128}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thumbnails, err := images.Thumbnails(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(thumbnails) != len(tt.want) {
+				t.Fatalf("got %d thumbnails", len(thumbnails))
+			}
+
+			for _, thumb := range thumbnails {
+				if thumb.ContentType != tt.wantType {
+					t.Errorf("%s: got type %s", thumb.Size, thumb.ContentType)
+				}
+
+				config, _, err := image.DecodeConfig(bytes.NewReader(thumb.Data))
+				if err != nil {
+					t.Fatalf("%s: %v", thumb.Size, err)
+				}
+				if got := [2]int{config.Width, config.Height}; got != tt.want[thumb.Size] {
+					t.Errorf("%s: got %v, want %v", thumb.Size, got, tt.want[thumb.Size])
+				}
+			}
+		})
+	}
+}
+
+func TestThumbnailsRejects(t *testing.T) {
+	// A 1x1 PNG whose header claims 7000x7000 pixels, with the header checksum fixed up
+	huge := encoded(t, 1, 1, false)
+	copy(huge[16:24], []byte{0, 0, 0x1b, 0x58, 0, 0, 0x1b, 0x58})
+	binary.BigEndian.PutUint32(huge[29:33], crc32.ChecksumIEEE(huge[12:29]))
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"text", []byte("hello, this is not an image"), images.ErrUnsupportedType},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), images.ErrUnsupportedType},
+		{"truncated png", encoded(t, 10, 10, false)[:40], images.ErrUnsupportedType},
+		{"too many pixels", huge, images.ErrTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := images.Thumbnails(tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
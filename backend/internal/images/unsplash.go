@@ -0,0 +1,48 @@
+package images
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Unsplash finds images with the Unsplash search API
+type Unsplash struct {
+	AccessKey string
+	BaseURL   string
+	Client    *Client
+}
+
+func NewUnsplash(accessKey string) *Unsplash {
+	return &Unsplash{AccessKey: accessKey, BaseURL: "https://api.unsplash.com", Client: NewClient()}
+}
+
+// unsplashResponse is the part of an Unsplash search response we use
+type unsplashResponse struct {
+	Results []struct {
+		URLs struct {
+			Small string `json:"small"`
+		} `json:"urls"`
+	} `json:"results"`
+}
+
+func (u *Unsplash) Name() string { return "unsplash" }
+
+func (u *Unsplash) Image(ctx context.Context, query string) (string, error) {
+	params := url.Values{"query": {normalize(query)}, "per_page": {"1"}}
+	header := http.Header{
+		"Authorization":  {"Client-ID " + u.AccessKey},
+		"Accept-Version": {"v1"},
+	}
+
+	var resp unsplashResponse
+	if err := u.Client.getJSON(ctx, u.BaseURL+"/search/photos?"+params.Encode(), header, &resp); err != nil {
+		return "", err
+	}
+
+	if len(resp.Results) == 0 || resp.Results[0].URLs.Small == "" {
+		return "", ErrNoImage
+	}
+
+	return resp.Results[0].URLs.Small, nil
+}
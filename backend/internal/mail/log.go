@@ -0,0 +1,52 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Log keeps emails on this machine instead of sending them. With a Dir every
+// email is written there as an .eml file, otherwise the whole email is logged.
+// Emails hold secrets like reset links, so it is for local development only.
+type Log struct {
+	Dir  string
+	From string
+}
+
+func (l *Log) Send(ctx context.Context, msg Message) error {
+	now := time.Now().UTC()
+	data, err := format(l.From, msg, now)
+	if err != nil {
+		return err
+	}
+
+	if l.Dir == "" {
+		log.Printf("📧 Email to %s\n%s", msg.To, data)
+		return nil
+	}
+
+	if err := os.MkdirAll(l.Dir, 0o700); err != nil {
+		return err
+	}
+
+	// Named by time so the outbox lists in the order emails were sent
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405.000000"), hex.EncodeToString(suffix))
+	path := filepath.Join(l.Dir, name)
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return err
+	}
+
+	log.Printf("📧 Email to %s written to %s", msg.To, path)
+	return nil
+}
@@ -0,0 +1,111 @@
+// Package mail sends the emails of the app, like password reset links, over
+// SMTP or to a local outbox during development.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"mime/quotedprintable"
+	"os"
+	"strings"
+	"time"
+)
+
+// Message is a plain text email to one recipient
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// FromEnv builds the mailer used by the server. MAILER=smtp sends through
+// SMTP_HOST, MAILER=file writes every email to MAIL_DIR and MAILER=log writes
+// them to the log, which is only fit for local development. MAILER has to be
+// set, so reset and verification tokens never end up in production logs by
+// accident.
+func FromEnv() (Mailer, error) {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "StartHub <no-reply@localhost>"
+	}
+
+	switch strings.ToLower(os.Getenv("MAILER")) {
+	case "smtp":
+		mailer := &SMTP{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		}
+		if mailer.Host == "" {
+			return nil, errors.New("SMTP_HOST must be set")
+		}
+		if mailer.Port == "" {
+			mailer.Port = "587"
+		}
+
+		log.Printf("✅ Sending emails through %s:%s", mailer.Host, mailer.Port)
+		return mailer, nil
+
+	case "file":
+		dir := os.Getenv("MAIL_DIR")
+		if dir == "" {
+			dir = "mail"
+		}
+
+		log.Printf("⚠️ Writing emails to %s instead of sending them", dir)
+		return &Log{Dir: dir, From: from}, nil
+
+	case "log":
+		log.Printf("⚠️ MAILER=log writes every email to the log, reset and verification tokens included. Never use it in production!")
+		return &Log{From: from}, nil
+
+	case "":
+		return nil, errors.New("MAILER must be set, use smtp, file or log for local development")
+
+	default:
+		return nil, fmt.Errorf("unknown MAILER %q, use smtp, file or log", os.Getenv("MAILER"))
+	}
+}
+
+// format renders msg as an RFC 5322 message from from
+func format(from string, msg Message, date time.Time) ([]byte, error) {
+	// A line break in a header would let the content add headers of its own
+	for _, header := range []string{from, msg.To, msg.Subject} {
+		if strings.ContainsAny(header, "\r\n") {
+			return nil, errors.New("mail headers can't contain line breaks")
+		}
+	}
+	if msg.To == "" {
+		return nil, errors.New("mail has no recipient")
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	body := quotedprintable.NewWriter(&buf)
+	if _, err := body.Write([]byte(strings.ReplaceAll(msg.Body, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,158 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testDate = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func TestFormat(t *testing.T) {
+	msg := Message{To: "ada@example.com", Subject: "Réinitialiser", Body: "Line one\nhttps://app.test/reset?token=abc"}
+
+	data, err := format("StartHub <no-reply@starthub.test>", msg, testDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	for _, want := range []string{"To: ada@example.com\r\n", "Subject: =?utf-8?q?R=C3=A9initialiser?=\r\n", "Line one\r\nhttps://app.test/reset?token=3Dabc"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("formatted mail is missing %q:\n%s", want, text)
+		}
+	}
+
+	// Line breaks in headers would let the content add headers of its own
+	for _, bad := range []Message{
+		{To: "ada@example.com\r\nBcc: eve@example.com", Subject: "Hi"},
+		{To: "ada@example.com", Subject: "Hi\nBcc: eve@example.com"},
+		{Subject: "No one"},
+	} {
+		if _, err := format("no-reply@starthub.test", bad, testDate); err == nil {
+			t.Errorf("format(%+v) succeeded", bad)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	tests := []struct {
+		mailer  string
+		wantErr bool
+	}{
+		{"", true}, // the log mailer has to be asked for
+		{"log", false},
+		{"file", false},
+		{"smtp", true}, // without SMTP_HOST
+		{"carrier-pigeon", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mailer, func(t *testing.T) {
+			t.Setenv("MAILER", tt.mailer)
+			t.Setenv("SMTP_HOST", "")
+
+			if _, err := FromEnv(); (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLogDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	mailer := &Log{Dir: dir, From: "no-reply@starthub.test"}
+
+	if err := mailer.Send(context.Background(), Message{To: "ada@example.com", Subject: "Hi", Body: "Hello"}); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("got files %v, %v", files, err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil || !strings.Contains(string(data), "Hello") {
+		t.Errorf("got %q, %v", data, err)
+	}
+}
+
+func TestSMTP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go serveSMTP(t, listener, received)
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	mailer := &SMTP{Host: host, Port: port, Username: "user", Password: "secret", From: "StartHub <no-reply@starthub.test>"}
+
+	if err := mailer.Send(context.Background(), Message{To: "Ada <ada@example.com>", Subject: "Hi", Body: "Hello"}); err != nil {
+		t.Fatal(err)
+	}
+
+	commands := strings.Join(<-received, "\n")
+	for _, want := range []string{"AUTH PLAIN", "MAIL FROM:<no-reply@starthub.test>", "RCPT TO:<ada@example.com>", "Subject: Hi", "Hello"} {
+		if !strings.Contains(commands, want) {
+			t.Errorf("server did not receive %q:\n%s", want, commands)
+		}
+	}
+}
+
+// serveSMTP answers one SMTP conversation and sends back every line it received
+func serveSMTP(t *testing.T, listener net.Listener, received chan<- []string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Error(err)
+		received <- nil
+		return
+	}
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	reader := bufio.NewReader(conn)
+	var lines []string
+	defer func() { received <- lines }()
+
+	text.PrintfLine("220 smtp.test ready")
+	inData := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		lines = append(lines, line)
+
+		if inData {
+			if line == "." {
+				inData = false
+				text.PrintfLine("250 queued")
+			}
+			continue
+		}
+
+		switch command := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); command {
+		case "EHLO":
+			text.PrintfLine("250-smtp.test\r\n250 AUTH PLAIN")
+		case "AUTH":
+			text.PrintfLine("235 authenticated")
+		case "DATA":
+			inData = true
+			text.PrintfLine("354 go ahead")
+		case "QUIT":
+			text.PrintfLine("221 bye")
+			return
+		default:
+			text.PrintfLine("250 ok")
+		}
+	}
+}
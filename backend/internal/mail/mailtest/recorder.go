@@ -0,0 +1,62 @@
+// Package mailtest has a mailer that keeps what it sends, so tests can read
+// the emails the app sent.
+package mailtest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ecetinerdem/starthub-backend/internal/mail"
+)
+
+// Recorder is a mailer that keeps every email instead of sending it
+type Recorder struct {
+	mu       sync.Mutex
+	messages []mail.Message
+}
+
+func (r *Recorder) Send(ctx context.Context, msg mail.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+// Messages returns every email sent to the address, oldest first
+func (r *Recorder) Messages(to string) []mail.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var messages []mail.Message
+	for _, msg := range r.messages {
+		if msg.To == to {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// Last returns the latest email sent to the address
+func (r *Recorder) Last(to string) (mail.Message, bool) {
+	messages := r.Messages(to)
+	if len(messages) == 0 {
+		return mail.Message{}, false
+	}
+	return messages[len(messages)-1], true
+}
+
+// WaitFor returns the emails sent to the address once there are n of them, or
+// the ones sent so far after timeout. It is for emails sent after the request
+// that caused them was answered.
+func (r *Recorder) WaitFor(to string, n int, timeout time.Duration) []mail.Message {
+	deadline := time.Now().Add(timeout)
+	for {
+		messages := r.Messages(to)
+		if len(messages) >= n || time.Now().After(deadline) {
+			return messages
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,120 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+// dialTimeout bounds connecting to the SMTP server when ctx has no deadline
+const dialTimeout = 30 * time.Second
+
+// SMTP sends emails through an SMTP server. Port 465 speaks TLS from the
+// start, other ports upgrade with STARTTLS when the server offers it.
+type SMTP struct {
+	Host     string
+	Port     string
+	Username string // No authentication when empty
+	Password string
+	From     string
+}
+
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	data, err := format(s.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	// Envelope addresses are the bare addresses, without display names
+	from, err := mail.ParseAddress(s.From)
+	if err != nil {
+		return err
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if s.Username != "" {
+		// PlainAuth refuses to send the password without TLS, except to localhost
+		if err := client.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// dial connects to the server and secures the connection when it can
+func (s *SMTP) dial(ctx context.Context) (*smtp.Client, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, dialTimeout)
+		defer cancel()
+	}
+
+	addr := net.JoinHostPort(s.Host, s.Port)
+	config := &tls.Config{ServerName: s.Host}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if s.Port == "465" {
+		conn, err = (&tls.Dialer{Config: config}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The whole conversation has to finish in time, not just the dial
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if ok, _ := client.Extension("STARTTLS"); ok && s.Port != "465" {
+		if err := client.StartTLS(config); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
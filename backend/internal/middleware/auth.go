@@ -3,42 +3,53 @@ package middleware
 import (
 	"strings"
 
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
 	"github.com/ecetinerdem/starthub-backend/pkg/utils"
 	"github.com/gofiber/fiber/v2"
 )
 
-func RequireAuth(c *fiber.Ctx) error {
-	authHeader := c.Get("Authorization")
+// RequireAuth validates the bearer token and checks that its session is still active
+func RequireAuth(users repository.UserRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		authHeader := c.Get("Authorization")
 
-	if authHeader == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization header required",
-		})
-	}
+		if authHeader == "" {
+			return apperrors.Unauthorized("Authorization header required")
+		}
 
-	//Bearer
-	tokenParts := strings.Split(authHeader, " ")
-	if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid authorization format. Use: Bearer <token>",
-		})
-	}
+		//Bearer
+		tokenParts := strings.Split(authHeader, " ")
+		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+			return apperrors.Unauthorized("Invalid authorization format. Use: Bearer <token>")
+		}
 
-	token := tokenParts[1]
+		token := tokenParts[1]
 
-	//Validate JWT
+		//Validate JWT
 
-	claims, err := utils.ValidateJWT(token)
+		claims, err := utils.ValidateJWT(token)
 
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid or expired token",
-		})
-	}
+		if err != nil || claims.SessionID == "" {
+			return apperrors.Unauthorized("Invalid or expired token")
+		}
+
+		//Reject tokens whose session was logged out or revoked
 
-	c.Locals("user_id", claims.UserID)
-	c.Locals("user_email", claims.Email)
-	c.Locals("user_role", claims.Role)
+		active, err := users.SessionActive(c.Context(), claims.SessionID, claims.UserID)
+		if err != nil {
+			return apperrors.Internal("Could not verify session", err)
+		}
 
-	return c.Next()
+		if !active {
+			return apperrors.Unauthorized("Session has expired or was revoked")
+		}
+
+		c.Locals("user_id", claims.UserID)
+		c.Locals("user_email", claims.Email)
+		c.Locals("user_role", claims.Role)
+		c.Locals("session_id", claims.SessionID)
+
+		return c.Next()
+	}
 }
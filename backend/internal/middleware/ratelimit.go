@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/ratelimit"
+	"github.com/ecetinerdem/starthub-backend/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RateLimit takes a token from the bucket a request falls in and answers 429
+// with Retry-After once it is empty. name keeps the buckets of different routes
+// apart and key picks the bucket, requests it returns "" for are not limited.
+// Without a store nothing is limited, and when the store fails requests are let
+// through rather than locking everyone out.
+func RateLimit(store ratelimit.Store, name string, limit ratelimit.Limit, key func(c *fiber.Ctx) string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if store == nil {
+			return c.Next()
+		}
+
+		k := key(c)
+		if k == "" {
+			return c.Next()
+		}
+
+		ok, wait, err := store.Take(c.Context(), name+":"+k, limit)
+		if err != nil {
+			log.Printf("⚠️ Rate limiting %s is unavailable: %v", name, err)
+			return c.Next()
+		}
+		if !ok {
+			seconds := int(math.Ceil(wait.Seconds()))
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+			return apperrors.TooManyRequests("Too many attempts, try again later").
+				With("retry_after", seconds)
+		}
+
+		return c.Next()
+	}
+}
+
+// ByIP puts requests in buckets by the address they come from
+func ByIP(c *fiber.Ctx) string {
+	return c.IP()
+}
+
+// ByEmail puts requests in buckets by the email in their JSON body, whether an
+// account has it or not. Buckets are named after a hash so stores never hold
+// the addresses.
+func ByEmail(c *fiber.Ctx) string {
+	var body struct {
+		Email string `json:"email"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return ""
+	}
+
+	email := strings.ToLower(strings.TrimSpace(body.Email))
+	if email == "" {
+		return ""
+	}
+	return utils.HashToken(email)
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Permission names an action that only some roles may perform
+type Permission string
+
+const (
+	PermCreateStartHub   Permission = "starthubs:create"
+	PermTrackDeals       Permission = "deals:track"
+	PermPledge           Permission = "pledges:create"
+	PermApply            Permission = "applications:create"
+	PermManageCategories Permission = "categories:manage"
+)
+
+// policies is the single place that decides which roles may call which routes.
+// Anything done to one starthub is decided by the team roles of models.Member
+// instead, so an investor can still be invited to edit a starthub.
+var policies = map[Permission][]string{
+	PermCreateStartHub:   {models.RoleStartHub, models.RoleAdmin},
+	PermTrackDeals:       {models.RoleInvestor},
+	PermPledge:           {models.RoleDonator},
+	PermApply:            {models.RoleCollaborator},
+	PermManageCategories: {models.RoleAdmin},
+}
+
+// Can reports whether a role has a permission
+func Can(role string, permission Permission) bool {
+	return slices.Contains(policies[permission], role)
+}
+
+// RequirePermission only lets through users whose role has the permission.
+// It must run after RequireAuth.
+func RequirePermission(permission Permission) fiber.Handler {
+	roles, ok := policies[permission]
+	if !ok {
+		// A typo in the router should fail at startup, not lock everyone out at runtime
+		panic(fmt.Sprintf("no policy defined for permission %q", permission))
+	}
+
+	return RequireRole(roles...)
+}
+
+// RequireRole only lets through users with one of the given roles.
+// It must run after RequireAuth.
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("user_role").(string)
+
+		if !slices.Contains(roles, role) {
+			return apperrors.Forbidden("Your role is not allowed to perform this action").
+				With("required_roles", roles)
+		}
+
+		return c.Next()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
 	"github.com/ecetinerdem/starthub-backend/internal/models"
 	"github.com/gofiber/fiber/v2"
 )
@@ -13,29 +14,21 @@ func ValidateLogin(c *fiber.Ctx) error {
 
 	// Parse the request body
 	if err := c.BodyParser(&request); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request data",
-		})
+		return apperrors.BadRequest("Invalid request data")
 	}
 
 	// Check required fields
 	if strings.TrimSpace(request.Email) == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Email is required",
-		})
+		return apperrors.Field("email", "Email is required")
 	}
 	if strings.TrimSpace(request.Password) == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Password is required",
-		})
+		return apperrors.Field("password", "Password is required")
 	}
 
 	// Email validation
 	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 	if !emailRegex.MatchString(request.Email) {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid email format",
-		})
+		return apperrors.Field("email", "Invalid email format")
 	}
 
 	return c.Next()
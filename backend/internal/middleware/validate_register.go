@@ -1,9 +1,11 @@
 package middleware
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
 	"github.com/ecetinerdem/starthub-backend/internal/models"
 	"github.com/gofiber/fiber/v2"
 )
@@ -14,56 +16,44 @@ func ValidateRegister(c *fiber.Ctx) error {
 
 	// Parse the request body
 	if err := c.BodyParser(&request); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request data",
-		})
+		return apperrors.BadRequest("Invalid request data")
 	}
 
 	// Check required fields
 	if strings.TrimSpace(request.Email) == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Email is required",
-		})
+		return apperrors.Field("email", "Email is required")
 	}
 	if strings.TrimSpace(request.Password) == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Password is required",
-		})
+		return apperrors.Field("password", "Password is required")
 	}
 	if strings.TrimSpace(request.Role) == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Role is required",
-		})
+		return apperrors.Field("role", "Role is required")
 	}
 
 	// Email validation
 	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 	if !emailRegex.MatchString(request.Email) {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid email format",
-		})
+		return apperrors.Field("email", "Invalid email format")
 	}
 
 	// Password validation
-	if len(request.Password) < 6 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Password must be at least 6 characters long",
-		})
+	if len(request.Password) < models.MinPasswordLength {
+		return apperrors.Field("password", fmt.Sprintf("Password must be at least %d characters long", models.MinPasswordLength))
+	}
+	if len(request.Password) > models.MaxPasswordBytes {
+		return apperrors.Field("password", fmt.Sprintf("Password can be at most %d bytes long", models.MaxPasswordBytes))
 	}
 
 	// Role validation
-	validRoles := []string{"starthub", "investor", "donator", "collaborator"}
 	roleValid := false
-	for _, role := range validRoles {
+	for _, role := range models.SignUpRoles {
 		if request.Role == role {
 			roleValid = true
 			break
 		}
 	}
 	if !roleValid {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Role must be either 'starthub', 'investor', 'donator' or 'collaborator'",
-		})
+		return apperrors.Field("role", "Role must be either 'starthub', 'investor', 'donator' or 'collaborator'")
 	}
 
 	// If all validations pass, continue to next handler
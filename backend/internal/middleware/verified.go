@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireVerifiedEmail only lets through users who confirmed their email with
+// the token sent at sign-up. It must run after RequireAuth.
+func RequireVerifiedEmail(users repository.UserRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, _ := c.Locals("user_id").(string)
+
+		// Read fresh, the access token was issued before the email may have been verified
+		user, err := users.GetByID(c.Context(), userID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperrors.NotFound("User not found")
+		}
+		if err != nil {
+			return apperrors.Internal("Could not load user", err)
+		}
+
+		if user.EmailVerifiedAt == nil {
+			return apperrors.Forbidden("Verify your email address first, we sent you a link when you signed up").
+				With("reason", "email_not_verified")
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,19 @@
+package models
+
+// Category represents a category along with how many starthubs use it
+type Category struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	StartHubCount int    `json:"starthub_count"`
+}
+
+// RenameCategoryRequest represents the request body for renaming a category
+type RenameCategoryRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// MergeCategoriesRequest moves every starthub of the source categories to the target
+type MergeCategoriesRequest struct {
+	SourceIDs []int `json:"source_ids" validate:"required"`
+	TargetID  int   `json:"target_id" validate:"required"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Collaboration statuses
+const (
+	CollaborationPending  = "pending"
+	CollaborationAccepted = "accepted"
+	CollaborationDeclined = "declined"
+	CollaborationEnded    = "ended"
+)
+
+// Collaboration represents a collaboration seen from one of the two starthubs
+type Collaboration struct {
+	Partner     StartHubSummary `json:"partner"`
+	Direction   string          `json:"direction"` // "outgoing" if this starthub proposed it, "incoming" otherwise
+	Status      string          `json:"status"`
+	Message     string          `json:"message,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	RespondedAt *time.Time      `json:"responded_at,omitempty"`
+}
+
+// CollaborationRequest represents the request body for proposing a collaboration
+type CollaborationRequest struct {
+	StartHubID string `json:"starthub_id" validate:"required"`
+	Message    string `json:"message"`
+}
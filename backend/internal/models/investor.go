@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Stages of a starthub in the pipeline of an investor
+const (
+	StageWatching     = "watching"
+	StageContacted    = "contacted"
+	StageDueDiligence = "due_diligence"
+	StagePassed       = "passed" // no longer counts as interest
+	StageInvested     = "invested"
+)
+
+// PipelineStages are every pipeline stage in the order deals move through them
+var PipelineStages = []string{StageWatching, StageContacted, StageDueDiligence, StagePassed, StageInvested}
+
+// PipelineEntry is a starthub an investor tracks. Only that investor can see it.
+type PipelineEntry struct {
+	StartHub  StartHubSummary `json:"starthub"`
+	Stage     string          `json:"stage"`
+	Notes     string          `json:"notes"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// PipelineRequest represents the request body for tracking a starthub
+type PipelineRequest struct {
+	Stage *string `json:"stage"` // nil keeps the stage already on the entry, new entries are watching
+	Notes *string `json:"notes"` // nil keeps the notes already on the entry
+}
+
+// InterestSummary is what the team of a starthub sees of the investors tracking it
+type InterestSummary struct {
+	StartHubID          string `json:"starthub_id"`
+	InterestedInvestors int    `json:"interested_investors"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Why a sign-in failed
+const (
+	LoginUnknownAccount = "unknown_account"
+	LoginWrongPassword  = "wrong_password"
+	LoginLocked         = "locked"         // the password wasn't even checked
+	LoginWrongMFACode   = "wrong_mfa_code" // the password was right, the second factor wasn't
+)
+
+// LoginAttempt is an entry of the audit log of failed sign-ins
+type LoginAttempt struct {
+	ID        int64     `json:"id"`
+	UserID    string    `json:"-"` // "" when no account has the email
+	Reason    string    `json:"reason"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
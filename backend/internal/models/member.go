@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// Roles a user can have in the team of a starthub
+const (
+	MemberOwner  = "owner"  // manages the team and can delete the starthub
+	MemberEditor = "editor" // edits the profile, images and collaborations
+	MemberViewer = "viewer" // sees what the team sees, like pending collaborations
+)
+
+// MemberRoles are every team role, from most to least allowed
+var MemberRoles = []string{MemberOwner, MemberEditor, MemberViewer}
+
+// EditorRoles are the team roles that may change a starthub
+var EditorRoles = []string{MemberOwner, MemberEditor}
+
+// Member is a user on the team of a starthub
+type Member struct {
+	UserID   string    `json:"user_id"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// TeamStartHub is a starthub seen from a user on its team
+type TeamStartHub struct {
+	StartHub StartHubSummary `json:"starthub"`
+	Role     string          `json:"role"`
+	JoinedAt time.Time       `json:"joined_at"`
+}
+
+// Invitation asks whoever owns an email to join the team of a starthub
+type Invitation struct {
+	ID         string     `json:"id"`
+	StartHubID string     `json:"starthub_id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	InvitedBy  string     `json:"invited_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+}
+
+// InvitationRequest represents the request body for inviting someone to a team
+type InvitationRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// AcceptInvitationRequest represents the request body for joining a team
+type AcceptInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+// MemberRoleRequest represents the request body for changing the role of a member
+type MemberRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// TransferOwnershipRequest represents the request body for handing a starthub to another member
+type TransferOwnershipRequest struct {
+	UserID string `json:"user_id"`
+}
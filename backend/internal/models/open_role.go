@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// Open role statuses
+const (
+	OpenRoleOpen   = "open"
+	OpenRoleClosed = "closed" // kept with its applications, takes no new ones
+)
+
+// How much time an open role asks for
+const (
+	CommitmentFullTime  = "full_time"
+	CommitmentPartTime  = "part_time"
+	CommitmentContract  = "contract"
+	CommitmentVolunteer = "volunteer"
+)
+
+// Commitments are every commitment an open role can ask for
+var Commitments = []string{CommitmentFullTime, CommitmentPartTime, CommitmentContract, CommitmentVolunteer}
+
+// Application statuses
+const (
+	ApplicationPending   = "pending"
+	ApplicationAccepted  = "accepted" // the applicant was added as an external collaborator
+	ApplicationRejected  = "rejected"
+	ApplicationWithdrawn = "withdrawn"
+)
+
+// OpenRole is a role a starthub is looking for collaborators to fill
+type OpenRole struct {
+	ID          string          `json:"id"`
+	StartHub    StartHubSummary `json:"starthub"`
+	Title       string          `json:"title"`
+	Description string          `json:"description,omitempty"`
+	Skills      []string        `json:"skills"`
+	Remote      bool            `json:"remote"`
+	Location    string          `json:"location,omitempty"`
+	Commitment  string          `json:"commitment"`
+	Status      string          `json:"status"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// OpenRoleRequest represents the request body for posting an open role
+type OpenRoleRequest struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Skills      []string `json:"skills"`
+	Remote      bool     `json:"remote"`
+	Location    string   `json:"location"`
+	Commitment  string   `json:"commitment"`
+}
+
+// OpenRoleFilter narrows down a list of open roles, zero values match everything
+type OpenRoleFilter struct {
+	StartHubID string
+	Skill      string
+	Remote     *bool
+	Commitment string
+}
+
+// Application is a collaborator applying to an open role
+type Application struct {
+	ID                     string     `json:"id"`
+	Role                   OpenRole   `json:"role"`
+	ApplicantEmail         string     `json:"applicant_email"`
+	Name                   string     `json:"name"`
+	URL                    string     `json:"url,omitempty"`
+	Message                string     `json:"message,omitempty"`
+	Status                 string     `json:"status"`
+	ExternalCollaboratorID *int       `json:"external_collaborator_id,omitempty"`
+	CreatedAt              time.Time  `json:"created_at"`
+	DecidedAt              *time.Time `json:"decided_at,omitempty"`
+	ApplicantID            string     `json:"-"`
+}
+
+// ApplicationRequest represents the request body for applying to an open role.
+// Name and URL are shown on the starthub once the application is accepted.
+type ApplicationRequest struct {
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// Pledge statuses
+const (
+	PledgePending = "pending" // stored, not charged yet
+	PledgePaid    = "paid"
+	PledgeFailed  = "failed"
+)
+
+// Pledge is money a donator gives to a starthub. Amounts are in minor units
+// of the currency, like cents. Pledges stay when the starthub is deleted, its
+// id is empty then and the name is the one it had at the time of the pledge.
+type Pledge struct {
+	ID        string          `json:"id"`
+	StartHub  StartHubSummary `json:"starthub"`
+	GoalID    *int            `json:"goal_id,omitempty"`
+	Amount    int64           `json:"amount"`
+	Currency  string          `json:"currency"`
+	Message   string          `json:"message,omitempty"`
+	Status    string          `json:"status"`
+	Provider  string          `json:"provider"`
+	PaymentID string          `json:"payment_id,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	DonatorID string          `json:"-"`
+}
+
+// PledgeRequest represents the request body for pledging to a starthub
+type PledgeRequest struct {
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	GoalID        *int   `json:"goal_id"`
+	Message       string `json:"message"`
+	PaymentMethod string `json:"payment_method"`
+}
+
+// FundingGoal is an amount a starthub wants to raise, Raised counts paid pledges to it
+type FundingGoal struct {
+	ID         int        `json:"id"`
+	StartHubID string     `json:"starthub_id"`
+	Title      string     `json:"title"`
+	Amount     int64      `json:"amount"`
+	Currency   string     `json:"currency"`
+	Deadline   *time.Time `json:"deadline,omitempty"`
+	Raised     int64      `json:"raised"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// FundingGoalRequest represents the request body for publishing a funding goal.
+// Deadline is a date like 2025-12-31.
+type FundingGoalRequest struct {
+	Title    string `json:"title"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	Deadline string `json:"deadline"`
+}
+
+// FundingTotal is the paid pledges to a starthub in one currency
+type FundingTotal struct {
+	Currency string `json:"currency"`
+	Amount   int64  `json:"amount"`
+	Pledges  int    `json:"pledges"`
+}
+
+// FundingProgress is what a starthub has raised, overall and per goal
+type FundingProgress struct {
+	StartHubID string         `json:"starthub_id"`
+	Donators   int            `json:"donators"`
+	Totals     []FundingTotal `json:"totals"`
+	Goals      []FundingGoal  `json:"goals"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Session is a signed-in device. Only a hash of its refresh token is stored.
+type Session struct {
+	ID               string
+	UserID           string
+	RefreshTokenHash string
+	RetiredHashes    []string // the refresh token hashes rotated away, oldest first
+	UserAgent        string
+	IPAddress        string
+	CreatedAt        time.Time
+	LastUsedAt       time.Time
+	ExpiresAt        time.Time
+	RevokedAt        *time.Time
+	RevokedReason    string
+}
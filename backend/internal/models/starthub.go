@@ -3,19 +3,65 @@ package models
 import "time"
 
 type StartHub struct {
-	ID                     string    `json:"id"`
-	Name                   string    `json:"name"`
-	Description            string    `json:"description"`
-	Location               string    `json:"location"`
-	TeamSize               int       `json:"team_size"`
-	URL                    string    `json:"url"`
-	Email                  string    `json:"email"`
-	JoinDate               time.Time `json:"join_date"`
-	ImageURL               string    `json:"image_url,omitempty"`
-	Categories             []string  `json:"categories,omitempty"`
-	CollaboratingStarthubs []string  `json:"collaborating_starthubs,omitempty"`
-	ExternalCollaborators  []string  `json:"external_collaborators,omitempty"`
-	CreatedBy              string    `json:"-"`
+	ID                     string                 `json:"id"`
+	Name                   string                 `json:"name"`
+	Description            string                 `json:"description"`
+	Location               string                 `json:"location"`
+	TeamSize               int                    `json:"team_size"`
+	URL                    string                 `json:"url"`
+	Email                  string                 `json:"email"`
+	JoinDate               time.Time              `json:"join_date"`
+	UpdatedAt              time.Time              `json:"updated_at"`
+	Version                int                    `json:"version"`
+	ImageURL               string                 `json:"image_url,omitempty"`
+	Categories             []string               `json:"categories,omitempty"`
+	CollaboratingStarthubs []StartHubSummary      `json:"collaborating_starthubs,omitempty"`
+	ExternalCollaborators  []ExternalCollaborator `json:"external_collaborators,omitempty"`
+	CreatedBy              string                 `json:"-"`
+}
+
+// StartHubSummary is the short form of a starthub used when it is referenced from another one
+type StartHubSummary struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	ImageURL string `json:"image_url,omitempty"`
+}
+
+// ExternalCollaborator represents a collaborator that is not a starthub on the platform
+type ExternalCollaborator struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	URL      string `json:"url,omitempty"`
+	Role     string `json:"role,omitempty"`
+	LogoURL  string `json:"logo_url,omitempty"`
+	Position int    `json:"position"`
+}
+
+// ExternalCollaboratorRequest represents the request body for adding an external collaborator
+type ExternalCollaboratorRequest struct {
+	Name    string `json:"name" validate:"required"`
+	URL     string `json:"url"`
+	Role    string `json:"role"`
+	LogoURL string `json:"logo_url"`
+}
+
+// ReorderRequest lists ids in their new display order
+type ReorderRequest struct {
+	IDs []int `json:"ids" validate:"required"`
+}
+
+// StartHubSearchResult is a starthub matched by full-text search
+type StartHubSearchResult struct {
+	StartHub
+	Rank      float32         `json:"rank"`
+	Highlight SearchHighlight `json:"highlight"`
+}
+
+// SearchHighlight holds HTML-escaped text snippets with matches wrapped in
+// <mark> tags
+type SearchHighlight struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
 }
 
 // CreateStartHubRequest represents the request body for creating a starthub
@@ -28,16 +74,3 @@ type CreateStartHubRequest struct {
 	Email       string   `json:"email" validate:"required,email"`
 	Categories  []string `json:"categories"`
 }
-
-// PexelsResponse represents the response from Pexels API
-type PexelsResponse struct {
-	Photos []PexelsPhoto `json:"photos"`
-}
-
-type PexelsPhoto struct {
-	Src PexelsSrc `json:"src"`
-}
-
-type PexelsSrc struct {
-	Medium string `json:"medium"`
-}
@@ -0,0 +1,51 @@
+package models
+
+// RecoveryCodeCount is how many recovery codes a user gets at a time
+const RecoveryCodeCount = 10
+
+// TwoFactorSetupRequest represents the request body for starting to enroll an
+// authenticator, the password proves it's the account owner at the keyboard
+type TwoFactorSetupRequest struct {
+	Password string `json:"password"`
+}
+
+// TwoFactorSetupResponse is what an authenticator app needs. OTPAuthURI is
+// usually shown as a QR code, Secret is for typing in by hand.
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// TwoFactorCodeRequest represents a request that needs a code from the
+// authenticator app
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// DisableTwoFactorRequest represents the request body for turning two-factor
+// authentication off. Code can be from the authenticator or a recovery code.
+type DisableTwoFactorRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+// RecoveryCodesResponse lists new recovery codes, the only time they are shown
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAChallengeResponse is the answer to a sign-in with the right password to an
+// account with two-factor authentication. The challenge token is exchanged at
+// /auth/mfa together with a code for the session.
+type MFAChallengeResponse struct {
+	MFARequired    bool   `json:"mfa_required"`
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresIn      int64  `json:"expires_in"` // seconds
+}
+
+// MFARequest represents the request body for finishing a sign-in with a code
+// from the authenticator or a recovery code
+type MFARequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
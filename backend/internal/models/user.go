@@ -2,13 +2,53 @@ package models
 
 import "time"
 
+// Roles a user can have
+const (
+	RoleStartHub     = "starthub"
+	RoleInvestor     = "investor"
+	RoleDonator      = "donator"
+	RoleCollaborator = "collaborator"
+	RoleAdmin        = "admin" // Granted directly in the database, never at sign-up
+)
+
+// Passwords are hashed with bcrypt, which only looks at the first 72 bytes
+const (
+	MinPasswordLength = 6
+	MaxPasswordBytes  = 72
+)
+
+// SignUpRoles are the roles a user can pick when registering
+var SignUpRoles = []string{RoleStartHub, RoleInvestor, RoleDonator, RoleCollaborator}
+
 // User represents a user in the system
 type User struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email" validate:"required,email"`
-	Password  string    `json:"-"` // Never output in JSON responses
-	Role      string    `json:"role" validate:"required,oneof=starthub investor donator collaborator"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        string          `json:"id"`
+	Email     string          `json:"email" validate:"required,email"`
+	Password  string          `json:"-"` // Never output in JSON responses
+	Role      string          `json:"role" validate:"required,oneof=starthub investor donator collaborator"`
+	Profile   Profile         `json:"profile"`
+	Settings  AccountSettings `json:"settings"`
+	CreatedAt time.Time       `json:"created_at"`
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at"` // nil until the emailed token is used
+	LockedUntil     *time.Time `json:"-"`                 // sign-in is refused until then
+
+	TOTPSecret    string     `json:"-"` // set once enrollment starts
+	TOTPEnabledAt *time.Time `json:"-"` // nil until a code from the secret was confirmed
+}
+
+// Profile is what a user tells others about themselves
+type Profile struct {
+	DisplayName string   `json:"display_name"`
+	Bio         string   `json:"bio"`
+	AvatarURL   string   `json:"avatar_url"`
+	Location    string   `json:"location"`
+	Links       []string `json:"links"`
+}
+
+// AccountSettings are the choices a user makes about their own account
+type AccountSettings struct {
+	PublicProfile bool `json:"public_profile"` // show the profile at /users/:id
 }
 
 // RegisterUserRequest represents the request body for user registration
@@ -20,8 +60,41 @@ type RegisterUserRequest struct {
 
 // UserResponse represents the safe user data returned in API responses
 type UserResponse struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
+	ID              string          `json:"id"`
+	Email           string          `json:"email"`
+	EmailVerified   bool            `json:"email_verified"`
+	EmailVerifiedAt *time.Time      `json:"email_verified_at"`
+	TwoFactor       bool            `json:"two_factor_enabled"`
+	Role            string          `json:"role"`
+	Profile         Profile         `json:"profile"`
+	Settings        AccountSettings `json:"settings"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+// NewUserResponse returns the parts of a user that are safe to send to that user
+func NewUserResponse(user User) UserResponse {
+	if user.Profile.Links == nil {
+		user.Profile.Links = []string{}
+	}
+
+	return UserResponse{
+		ID:              user.ID,
+		Email:           user.Email,
+		EmailVerified:   user.EmailVerifiedAt != nil,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		TwoFactor:       user.TOTPEnabledAt != nil,
+		Role:            user.Role,
+		Profile:         user.Profile,
+		Settings:        user.Settings,
+		CreatedAt:       user.CreatedAt,
+	}
+}
+
+// PublicProfile is what anyone can see of a user, never their email
+type PublicProfile struct {
+	ID        string            `json:"id"`
+	Role      string            `json:"role"`
+	Profile   Profile           `json:"profile"`
+	StartHubs []StartHubSummary `json:"starthubs"` // the ones they own
+	CreatedAt time.Time         `json:"created_at"`
 }
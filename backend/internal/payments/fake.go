@@ -0,0 +1,53 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DeclinedPrefix marks payment methods the fake provider declines, like
+// "declined_card"
+const DeclinedPrefix = "declined"
+
+// Fake accepts every charge without moving money. It declines payment methods
+// starting with DeclinedPrefix and, like real providers, charges every
+// idempotency key only once.
+type Fake struct {
+	mu      sync.Mutex
+	charges []Charge
+	ids     map[string]string // payment id by idempotency key
+}
+
+func (f *Fake) Name() string { return "fake" }
+
+func (f *Fake) Charge(ctx context.Context, charge Charge) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if strings.HasPrefix(charge.PaymentMethod, DeclinedPrefix) {
+		return "", ErrDeclined
+	}
+
+	if id, ok := f.ids[charge.IdempotencyKey]; ok && charge.IdempotencyKey != "" {
+		return id, nil
+	}
+
+	f.charges = append(f.charges, charge)
+	id := fmt.Sprintf("fake_%d", len(f.charges))
+	if f.ids == nil {
+		f.ids = map[string]string{}
+	}
+	f.ids[charge.IdempotencyKey] = id
+
+	return id, nil
+}
+
+// Charges returns every charge collected so far, oldest first
+func (f *Fake) Charges() []Charge {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Charge(nil), f.charges...)
+}
@@ -0,0 +1,60 @@
+// Package payments collects the money donators pledge to starthubs.
+package payments
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+)
+
+var (
+	// ErrDeclined means the provider refused the payment method, the donator can retry with another one
+	ErrDeclined = errors.New("payment was declined")
+
+	// ErrUnavailable means no payment provider is set up
+	ErrUnavailable = errors.New("payments are not set up")
+)
+
+// Charge is a payment to collect
+type Charge struct {
+	Amount        int64  // In minor units of Currency, like cents
+	Currency      string // ISO 4217 code in upper case
+	PaymentMethod string // Token of the payment method, as handed to the client by the provider
+	Description   string
+	// IdempotencyKey makes retrying a charge safe, the provider charges a key only once
+	IdempotencyKey string
+}
+
+// Provider charges payment methods
+type Provider interface {
+	Name() string
+	// Charge collects the payment and returns the id the provider gave it
+	Charge(ctx context.Context, charge Charge) (string, error)
+}
+
+// Disabled is the provider of a server without payments, every charge fails
+type Disabled struct{}
+
+func (Disabled) Name() string { return "disabled" }
+
+func (Disabled) Charge(ctx context.Context, charge Charge) (string, error) {
+	return "", ErrUnavailable
+}
+
+// FromEnv builds the provider used by the server. PAYMENTS_PROVIDER=fake
+// accepts every pledge without charging anyone, for local development.
+// Without it pledges can't be paid.
+func FromEnv() Provider {
+	switch os.Getenv("PAYMENTS_PROVIDER") {
+	case "fake":
+		log.Printf("⚠️ Using the fake payment provider, pledges are not actually charged")
+		return &Fake{}
+	case "":
+		log.Printf("⚠️ PAYMENTS_PROVIDER is not set, pledges can't be paid")
+		return Disabled{}
+	default:
+		log.Printf("❌ Unknown PAYMENTS_PROVIDER %q, pledges can't be paid", os.Getenv("PAYMENTS_PROVIDER"))
+		return Disabled{}
+	}
+}
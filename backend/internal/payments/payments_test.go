@@ -0,0 +1,36 @@
+package payments_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ecetinerdem/starthub-backend/internal/payments"
+)
+
+func TestFake(t *testing.T) {
+	fake := &payments.Fake{}
+	ctx := context.Background()
+	charge := payments.Charge{Amount: 2500, Currency: "EUR", PaymentMethod: "card", IdempotencyKey: "pledge-1"}
+
+	first, err := fake.Charge(ctx, charge)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A retry with the same key is not charged again
+	again, err := fake.Charge(ctx, charge)
+	if err != nil || again != first {
+		t.Errorf("retry: got %q, %v, want %q", again, err, first)
+	}
+
+	charge.IdempotencyKey = "pledge-2"
+	charge.PaymentMethod = payments.DeclinedPrefix + "_card"
+	if _, err := fake.Charge(ctx, charge); !errors.Is(err, payments.ErrDeclined) {
+		t.Errorf("got %v, want ErrDeclined", err)
+	}
+
+	if charges := fake.Charges(); len(charges) != 1 || charges[0].Amount != 2500 {
+		t.Errorf("got charges %+v", charges)
+	}
+}
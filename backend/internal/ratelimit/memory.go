@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory keeps the buckets in process memory
+type Memory struct {
+	Now func() time.Time // the clock, time.Now when nil
+
+	mu         sync.Mutex
+	buckets    map[string]*bucket
+	lastPruned time.Time
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	fullAt    time.Time // when it is full again if nobody takes from it
+}
+
+// NewMemory returns an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{buckets: map[string]*bucket{}}
+}
+
+func (m *Memory) Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if m.Now != nil {
+		now = m.Now()
+	}
+	if m.buckets == nil {
+		m.buckets = map[string]*bucket{}
+	}
+	m.prune(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		// Keys often come from request buffers that are reused
+		b = &bucket{tokens: float64(limit.Burst), updatedAt: now}
+		m.buckets[strings.Clone(key)] = b
+	}
+
+	tokens, taken, wait := take(b.tokens, now.Sub(b.updatedAt), limit)
+	b.tokens = tokens
+	b.updatedAt = now
+	b.fullAt = now.Add(fullAfter(limit))
+
+	return taken, wait, nil
+}
+
+// prune drops the buckets that filled up again. The caller holds the lock.
+func (m *Memory) prune(now time.Time) {
+	if now.Sub(m.lastPruned) < pruneEvery {
+		return
+	}
+	m.lastPruned = now
+
+	for key, b := range m.buckets {
+		if !now.Before(b.fullAt) {
+			delete(m.buckets, key)
+		}
+	}
+}
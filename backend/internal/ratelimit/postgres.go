@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Postgres keeps the buckets in the rate_limits table, so every instance of the
+// server draws from the same ones
+type Postgres struct {
+	DB *pgxpool.Pool
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+func (p *Postgres) Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	p.prune(ctx)
+
+	tx, err := p.DB.Begin(ctx)
+	if err != nil {
+		return false, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Step 1: Make sure the bucket exists, a new one is full
+	_, err = tx.Exec(
+		ctx,
+		"INSERT INTO rate_limits (key, tokens, updated_at, full_at) VALUES ($1, $2, NOW(), NOW()) ON CONFLICT (key) DO NOTHING",
+		key,
+		float64(limit.Burst),
+	)
+	if err != nil {
+		return false, 0, err
+	}
+
+	// Step 2: Lock it so concurrent requests take their tokens one after the other
+	var (
+		tokens  float64
+		elapsed float64
+	)
+	query := `
+	SELECT tokens, EXTRACT(EPOCH FROM NOW() - updated_at)::float8
+	FROM rate_limits
+	WHERE key = $1
+	FOR UPDATE
+	`
+	if err := tx.QueryRow(ctx, query, key).Scan(&tokens, &elapsed); err != nil {
+		return false, 0, err
+	}
+
+	// Step 3: Refill and take with the same rules as the in-memory store
+	tokens, taken, wait := take(tokens, time.Duration(elapsed*float64(time.Second)), limit)
+	_, err = tx.Exec(
+		ctx,
+		"UPDATE rate_limits SET tokens = $2, updated_at = NOW(), full_at = NOW() + make_interval(secs => $3) WHERE key = $1",
+		key,
+		tokens,
+		fullAfter(limit).Seconds(),
+	)
+	if err != nil {
+		return false, 0, err
+	}
+
+	return taken, wait, tx.Commit(ctx)
+}
+
+// prune now and then deletes the buckets that filled up again
+func (p *Postgres) prune(ctx context.Context) {
+	p.mu.Lock()
+	due := time.Since(p.lastPruned) >= pruneEvery
+	if due {
+		p.lastPruned = time.Now()
+	}
+	p.mu.Unlock()
+
+	if !due {
+		return
+	}
+
+	if _, err := p.DB.Exec(ctx, "DELETE FROM rate_limits WHERE full_at <= NOW()"); err != nil {
+		log.Printf("⚠️ Could not prune rate limits: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+// Package ratelimit limits how often something can be done with token buckets.
+// Every key has a bucket that holds up to Burst tokens, each attempt takes one
+// and one is added back every Every.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Limit is the size and refill rate of a bucket
+type Limit struct {
+	Burst int           // tokens a full bucket holds
+	Every time.Duration // one token is added back this often
+}
+
+// Store keeps the buckets
+type Store interface {
+	// Take removes a token from the bucket of key. When the bucket is empty
+	// it returns false and how long until the next token is added.
+	Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error)
+}
+
+// pruneEvery is how often stores drop buckets that have filled up again
+const pruneEvery = 10 * time.Minute
+
+// take refills a bucket that had tokens left elapsed ago and takes one from it.
+// It returns the tokens left and, when none could be taken, how long until one can.
+func take(tokens float64, elapsed time.Duration, limit Limit) (float64, bool, time.Duration) {
+	tokens = math.Min(float64(limit.Burst), tokens+elapsed.Seconds()/limit.Every.Seconds())
+	if tokens >= 1 {
+		return tokens - 1, true, 0
+	}
+
+	wait := time.Duration((1 - tokens) * float64(limit.Every))
+	return tokens, false, wait
+}
+
+// fullAfter is how long an untouched bucket takes to fill up again, after
+// which it is the same as no bucket
+func fullAfter(limit Limit) time.Duration {
+	return time.Duration(limit.Burst) * limit.Every
+}
+
+// FromEnv builds the store used by the server. RATE_LIMIT_STORE=postgres keeps
+// the buckets in the database so every instance of the server shares them,
+// otherwise each instance counts on its own in memory.
+func FromEnv(db *pgxpool.Pool) (Store, error) {
+	switch strings.ToLower(os.Getenv("RATE_LIMIT_STORE")) {
+	case "postgres":
+		log.Printf("✅ Keeping rate limits in Postgres")
+		return &Postgres{DB: db}, nil
+
+	case "", "memory":
+		log.Printf("✅ Keeping rate limits in memory, each instance counts on its own")
+		return NewMemory(), nil
+
+	default:
+		return nil, fmt.Errorf("unknown RATE_LIMIT_STORE %q, use memory or postgres", os.Getenv("RATE_LIMIT_STORE"))
+	}
+}
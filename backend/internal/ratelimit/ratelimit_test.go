@@ -0,0 +1,63 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ecetinerdem/starthub-backend/internal/ratelimit"
+)
+
+func TestMemory(t *testing.T) {
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := ratelimit.NewMemory()
+	store.Now = func() time.Time { return clock }
+
+	ctx := context.Background()
+	limit := ratelimit.Limit{Burst: 3, Every: 10 * time.Second}
+	take := func(key string) (bool, time.Duration) {
+		t.Helper()
+		ok, wait, err := store.Take(ctx, key, limit)
+		if err != nil {
+			t.Fatalf("take %s: %v", key, err)
+		}
+		return ok, wait
+	}
+
+	// A full bucket allows a burst, then has to refill
+	for i := range limit.Burst {
+		if ok, _ := take("a"); !ok {
+			t.Fatalf("attempt %d of the burst was refused", i+1)
+		}
+	}
+	if ok, wait := take("a"); ok || wait != 10*time.Second {
+		t.Fatalf("got %v, wait %s on an empty bucket, want refused with 10s", ok, wait)
+	}
+
+	// Other keys have their own bucket
+	if ok, _ := take("b"); !ok {
+		t.Fatal("another key was refused")
+	}
+
+	clock = clock.Add(4 * time.Second)
+	if ok, wait := take("a"); ok || wait != 6*time.Second {
+		t.Fatalf("got %v, wait %s after 4s, want refused with 6s", ok, wait)
+	}
+
+	clock = clock.Add(6 * time.Second)
+	if ok, _ := take("a"); !ok {
+		t.Fatal("refused after a token was added back")
+	}
+	if ok, _ := take("a"); ok {
+		t.Fatal("took more tokens than were added back")
+	}
+
+	// Buckets never hold more than the burst
+	clock = clock.Add(time.Hour)
+	for range limit.Burst {
+		take("a")
+	}
+	if ok, _ := take("a"); ok {
+		t.Fatal("a bucket that sat idle allowed more than the burst")
+	}
+}
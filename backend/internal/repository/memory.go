@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"crypto/rand"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+)
+
+// memoryStore holds every table of the in-memory repositories. One mutex
+// guards all of it, which keeps multi-table writes atomic like a transaction.
+type memoryStore struct {
+	mu sync.Mutex
+
+	users          map[string]models.User
+	sessions       map[string]models.Session
+	passwordResets map[string]*memoryUserToken // by token hash
+	verifications  map[string]*memoryUserToken // email_verifications, by token hash
+	failedLogins   map[string]int              // users.failed_logins, by user id
+	loginAttempts  []models.LoginAttempt
+	totpLastSteps  map[string]int64               // users.totp_last_step, by user id
+	recoveryCodes  map[string]map[string]bool     // by user id, then code hash, true once used
+	mfaChallenges  map[string]*memoryMFAChallenge // by token hash
+
+	starthubs          map[string]models.StartHub // without relations
+	categories         map[int]string
+	starthubCategories map[string]map[int]bool
+	collaborations     []*memoryCollaboration
+	externals          map[int]memoryExternal
+	members            map[memberKey]memoryMember
+	invitations        map[string]*memoryInvitation
+	interests          map[interestKey]memoryInterest
+	goals              map[int]models.FundingGoal    // without Raised
+	pledges            map[string]models.Pledge      // with the starthub name at the time of the pledge
+	openRoles          map[string]models.OpenRole    // without the starthub name and image
+	applications       map[string]models.Application // with only the id of the role
+
+	nextCategoryID int
+	nextExternalID int
+	nextGoalID     int
+}
+
+// memoryCollaboration is a row of starthub_collaborations
+type memoryCollaboration struct {
+	starthubID     string // who proposed it
+	collaboratorID string
+	status         string
+	message        string
+	requestedBy    string
+	createdAt      time.Time
+	respondedAt    *time.Time
+}
+
+// memoryUserToken is a row of password_resets or email_verifications
+type memoryUserToken struct {
+	userID    string
+	createdAt time.Time
+	expiresAt time.Time
+	usedAt    *time.Time
+}
+
+// memoryMFAChallenge is a row of mfa_challenges
+type memoryMFAChallenge struct {
+	memoryUserToken
+	attempts int
+}
+
+// memberKey is the primary key of starthub_members
+type memberKey struct {
+	starthubID string
+	userID     string
+}
+
+// memoryMember is a row of starthub_members
+type memoryMember struct {
+	role     string
+	joinedAt time.Time
+}
+
+// memoryInvitation is a row of starthub_invitations
+type memoryInvitation struct {
+	models.Invitation
+	tokenHash string
+}
+
+// interestKey is the primary key of investor_interests
+type interestKey struct {
+	investorID string
+	starthubID string
+}
+
+// memoryInterest is a row of investor_interests
+type memoryInterest struct {
+	stage     string
+	notes     string
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+// memoryExternal is a row of external_collaborators
+type memoryExternal struct {
+	starthubID string
+	models.ExternalCollaborator
+}
+
+// NewMemory returns repositories that keep everything in process memory.
+// They behave like the Postgres ones, including constraint errors, so handlers
+// can be tested without a database. Search has no stemming.
+//
+// Strings from Fiber (like c.Params) point into buffers that are reused by later
+// requests, so anything the store keeps is cloned or comes from the store itself.
+func NewMemory() *Repositories {
+	store := &memoryStore{
+		users:              map[string]models.User{},
+		sessions:           map[string]models.Session{},
+		passwordResets:     map[string]*memoryUserToken{},
+		verifications:      map[string]*memoryUserToken{},
+		failedLogins:       map[string]int{},
+		totpLastSteps:      map[string]int64{},
+		recoveryCodes:      map[string]map[string]bool{},
+		mfaChallenges:      map[string]*memoryMFAChallenge{},
+		starthubs:          map[string]models.StartHub{},
+		categories:         map[int]string{},
+		starthubCategories: map[string]map[int]bool{},
+		externals:          map[int]memoryExternal{},
+		members:            map[memberKey]memoryMember{},
+		invitations:        map[string]*memoryInvitation{},
+		interests:          map[interestKey]memoryInterest{},
+		goals:              map[int]models.FundingGoal{},
+		pledges:            map[string]models.Pledge{},
+		openRoles:          map[string]models.OpenRole{},
+		applications:       map[string]models.Application{},
+		nextCategoryID:     1,
+		nextExternalID:     1,
+		nextGoalID:         1,
+	}
+
+	return &Repositories{
+		StartHubs:      &memoryStartHubs{store},
+		Categories:     &memoryCategories{store},
+		Collaborations: &memoryCollaborations{store},
+		Members:        &memoryMembers{store},
+		Pipeline:       &memoryPipeline{store},
+		Funding:        &memoryFunding{store},
+		OpenRoles:      &memoryOpenRoles{store},
+		Users:          &memoryUsers{store},
+	}
+}
+
+// newID returns a random version 4 UUID
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// hydrate returns the starthub with its relations, like loadStartHubRelations.
+// The caller holds the lock.
+func (m *memoryStore) hydrate(s models.StartHub) models.StartHub {
+	s.Categories = nil
+	for id := range m.starthubCategories[s.ID] {
+		s.Categories = append(s.Categories, m.categories[id])
+	}
+	slices.Sort(s.Categories)
+
+	// Only accepted collaborations belong on a profile
+	s.CollaboratingStarthubs = nil
+	for _, c := range m.collaborations {
+		if c.status != models.CollaborationAccepted {
+			continue
+		}
+		switch s.ID {
+		case c.starthubID:
+			s.CollaboratingStarthubs = append(s.CollaboratingStarthubs, m.summary(c.collaboratorID))
+		case c.collaboratorID:
+			s.CollaboratingStarthubs = append(s.CollaboratingStarthubs, m.summary(c.starthubID))
+		}
+	}
+	slices.SortFunc(s.CollaboratingStarthubs, func(a, b models.StartHubSummary) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	s.ExternalCollaborators = m.externalsOf(s.ID)
+
+	return s
+}
+
+func (m *memoryStore) summary(id string) models.StartHubSummary {
+	s := m.starthubs[id]
+	return models.StartHubSummary{ID: s.ID, Name: s.Name, ImageURL: s.ImageURL}
+}
+
+// externalsOf returns the external collaborators of a starthub in display order
+func (m *memoryStore) externalsOf(starthubID string) []models.ExternalCollaborator {
+	var collaborators []models.ExternalCollaborator
+	for _, e := range m.externals {
+		if e.starthubID == starthubID {
+			collaborators = append(collaborators, e.ExternalCollaborator)
+		}
+	}
+
+	slices.SortFunc(collaborators, func(a, b models.ExternalCollaborator) int {
+		if a.Position != b.Position {
+			return a.Position - b.Position
+		}
+		return a.ID - b.ID
+	})
+
+	return collaborators
+}
+
+// touch bumps the version of starthubs, like touchStartHubs. The caller holds the lock.
+func (m *memoryStore) touch(ids ...string) {
+	for _, id := range ids {
+		if row, ok := m.starthubs[id]; ok {
+			row.Version++
+			row.UpdatedAt = now()
+			m.starthubs[row.ID] = row
+		}
+	}
+}
+
+// checkVersion returns ErrVersionMismatch unless the row is at one of the versions
+func checkVersion(row models.StartHub, versions Versions) error {
+	if !versions.Allow(row.Version) {
+		return ErrVersionMismatch
+	}
+	return nil
+}
+
+// findCategory returns the id and stored name of a category, matched case-insensitively
+func (m *memoryStore) findCategory(name string) (int, string, bool) {
+	found := 0
+	for id, existing := range m.categories {
+		if strings.EqualFold(existing, name) && (found == 0 || id < found) {
+			found = id
+		}
+	}
+
+	return found, m.categories[found], found != 0
+}
+
+// setCategories is the in-memory setStartHubCategories. The caller holds the lock.
+func (m *memoryStore) setCategories(starthubID string, names []string) []string {
+	names = normalizeCategories(names)
+	links := make(map[int]bool, len(names))
+
+	for i, name := range names {
+		id, stored, ok := m.findCategory(name)
+		if ok {
+			names[i] = stored
+		} else {
+			id = m.nextCategoryID
+			m.nextCategoryID++
+			m.categories[id] = name
+		}
+		links[id] = true
+	}
+
+	m.starthubCategories[starthubID] = links
+	return names
+}
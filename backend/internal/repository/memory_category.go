@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+)
+
+type memoryCategories struct {
+	store *memoryStore
+}
+
+// countStartHubs returns how many starthubs use a category. The caller holds the lock.
+func (m *memoryStore) countStartHubs(categoryID int) int {
+	count := 0
+	for _, links := range m.starthubCategories {
+		if links[categoryID] {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *memoryCategories) List(ctx context.Context) ([]models.Category, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	categories := []models.Category{}
+	for id, name := range r.store.categories {
+		categories = append(categories, models.Category{
+			ID:            id,
+			Name:          name,
+			StartHubCount: r.store.countStartHubs(id),
+		})
+	}
+
+	slices.SortFunc(categories, func(a, b models.Category) int {
+		return cmp.Or(cmp.Compare(b.StartHubCount, a.StartHubCount), strings.Compare(a.Name, b.Name))
+	})
+
+	return categories, nil
+}
+
+func (r *memoryCategories) Rename(ctx context.Context, id int, name string) (models.Category, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for existingID, existing := range r.store.categories {
+		if existingID != id && strings.EqualFold(existing, name) {
+			return models.Category{ID: id}, &NameTakenError{CategoryID: existingID}
+		}
+	}
+
+	if _, ok := r.store.categories[id]; !ok {
+		return models.Category{ID: id}, ErrNotFound
+	}
+	r.store.categories[id] = name
+	r.store.touchCategory(id)
+
+	return models.Category{ID: id, Name: name, StartHubCount: r.store.countStartHubs(id)}, nil
+}
+
+func (r *memoryCategories) Merge(ctx context.Context, sourceIDs []int, targetID int) (models.Category, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, id := range append(normalizeIDs(sourceIDs), targetID) {
+		if _, ok := r.store.categories[id]; !ok {
+			return models.Category{ID: targetID}, ErrNotFound
+		}
+	}
+
+	for _, id := range sourceIDs {
+		r.store.touchCategory(id)
+	}
+
+	// Re-point the links, then drop the sources
+	for _, links := range r.store.starthubCategories {
+		for _, id := range sourceIDs {
+			if links[id] {
+				delete(links, id)
+				links[targetID] = true
+			}
+		}
+	}
+	for _, id := range sourceIDs {
+		delete(r.store.categories, id)
+	}
+
+	return models.Category{
+		ID:            targetID,
+		Name:          r.store.categories[targetID],
+		StartHubCount: r.store.countStartHubs(targetID),
+	}, nil
+}
+
+// touchCategory bumps the version of the starthubs in a category. The caller holds the lock.
+func (m *memoryStore) touchCategory(id int) {
+	for starthubID, links := range m.starthubCategories {
+		if links[id] {
+			m.touch(starthubID)
+		}
+	}
+}
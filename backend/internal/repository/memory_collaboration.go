@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+)
+
+type memoryCollaborations struct {
+	store *memoryStore
+}
+
+// isPair reports whether the collaboration is between a and b, in either direction
+func (c *memoryCollaboration) isPair(a, b string) bool {
+	return (c.starthubID == a && c.collaboratorID == b) || (c.starthubID == b && c.collaboratorID == a)
+}
+
+func (r *memoryCollaborations) Propose(ctx context.Context, proposal CollaborationProposal) (models.Collaboration, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.starthubs[proposal.PartnerID]; !ok {
+		return models.Collaboration{}, ErrNotFound
+	}
+
+	// A declined or ended collaboration can be proposed again, from either side
+	r.store.collaborations = slices.DeleteFunc(r.store.collaborations, func(c *memoryCollaboration) bool {
+		return c.isPair(proposal.StartHubID, proposal.PartnerID) &&
+			(c.status == models.CollaborationDeclined || c.status == models.CollaborationEnded)
+	})
+
+	for _, c := range r.store.collaborations {
+		if c.isPair(proposal.StartHubID, proposal.PartnerID) {
+			return models.Collaboration{}, &ConflictError{Constraint: "idx_starthub_collaborations_pair"}
+		}
+	}
+
+	row := &memoryCollaboration{
+		starthubID:     strings.Clone(proposal.StartHubID),
+		collaboratorID: strings.Clone(proposal.PartnerID),
+		status:         models.CollaborationPending,
+		message:        strings.Clone(proposal.Message),
+		requestedBy:    strings.Clone(proposal.RequestedBy),
+		createdAt:      now(),
+	}
+	r.store.collaborations = append(r.store.collaborations, row)
+
+	return models.Collaboration{
+		Partner:   r.store.summary(proposal.PartnerID),
+		Direction: "outgoing",
+		Status:    row.status,
+		Message:   row.message,
+		CreatedAt: row.createdAt,
+	}, nil
+}
+
+func (r *memoryCollaborations) List(ctx context.Context, starthubID string, statuses []string) ([]models.Collaboration, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	collaborations := []models.Collaboration{}
+	for _, c := range r.store.collaborations {
+		if !slices.Contains(statuses, c.status) {
+			continue
+		}
+
+		collaboration := models.Collaboration{
+			Status:      c.status,
+			Message:     c.message,
+			CreatedAt:   c.createdAt,
+			RespondedAt: c.respondedAt,
+		}
+		switch starthubID {
+		case c.starthubID:
+			collaboration.Partner = r.store.summary(c.collaboratorID)
+			collaboration.Direction = "outgoing"
+		case c.collaboratorID:
+			collaboration.Partner = r.store.summary(c.starthubID)
+			collaboration.Direction = "incoming"
+		default:
+			continue
+		}
+
+		collaborations = append(collaborations, collaboration)
+	}
+
+	slices.SortStableFunc(collaborations, func(a, b models.Collaboration) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	return collaborations, nil
+}
+
+func (r *memoryCollaborations) Respond(ctx context.Context, starthubID, partnerID, status string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// Only the starthub that received the request can answer it
+	for _, c := range r.store.collaborations {
+		if c.starthubID == partnerID && c.collaboratorID == starthubID && c.status == models.CollaborationPending {
+			respondedAt := now()
+			c.status = status
+			c.respondedAt = &respondedAt
+			r.store.touch(starthubID, partnerID)
+			return nil
+		}
+	}
+
+	return ErrNotFound
+}
+
+func (r *memoryCollaborations) End(ctx context.Context, starthubID, partnerID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for i, c := range r.store.collaborations {
+		// Accepted collaborations are kept as history
+		if c.isPair(starthubID, partnerID) && c.status == models.CollaborationAccepted {
+			respondedAt := now()
+			c.status = models.CollaborationEnded
+			c.respondedAt = &respondedAt
+			r.store.touch(starthubID, partnerID)
+			return nil
+		}
+
+		// Withdrawn proposals are removed
+		if c.starthubID == starthubID && c.collaboratorID == partnerID && c.status == models.CollaborationPending {
+			r.store.collaborations = slices.Delete(r.store.collaborations, i, i+1)
+			return nil
+		}
+	}
+
+	return ErrNotFound
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+)
+
+type memoryFunding struct {
+	store *memoryStore
+}
+
+// pledge returns a stored pledge with its starthub, or the name it stored once
+// the starthub is gone. The caller holds the lock.
+func (m *memoryStore) pledge(p models.Pledge) models.Pledge {
+	if _, ok := m.starthubs[p.StartHub.ID]; ok {
+		p.StartHub = m.summary(p.StartHub.ID)
+	}
+	return p
+}
+
+// goal returns a stored goal with what was raised for it. The caller holds the lock.
+func (m *memoryStore) goal(g models.FundingGoal) models.FundingGoal {
+	g.Raised = 0
+	for _, p := range m.pledges {
+		if p.GoalID != nil && *p.GoalID == g.ID && p.Status == models.PledgePaid {
+			g.Raised += p.Amount
+		}
+	}
+	return g
+}
+
+func (r *memoryFunding) CreatePledge(ctx context.Context, p models.Pledge) (models.Pledge, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	s, ok := r.store.starthubs[p.StartHub.ID]
+	if !ok {
+		return p, ErrNotFound
+	}
+	if p.GoalID != nil {
+		if g, ok := r.store.goals[*p.GoalID]; !ok || g.StartHubID != s.ID {
+			return p, ErrNotFound
+		}
+		goalID := *p.GoalID
+		p.GoalID = &goalID
+	}
+
+	p.ID = newID()
+	p.StartHub = models.StartHubSummary{ID: s.ID, Name: s.Name}
+	p.DonatorID = strings.Clone(p.DonatorID)
+	p.Status = models.PledgePending
+	p.PaymentID = ""
+	p.CreatedAt = now()
+	r.store.pledges[p.ID] = p
+
+	return r.store.pledge(p), nil
+}
+
+func (r *memoryFunding) SetPledgeStatus(ctx context.Context, id, status, paymentID string) (models.Pledge, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	p, ok := r.store.pledges[id]
+	if !ok {
+		return p, ErrNotFound
+	}
+
+	p.Status = strings.Clone(status)
+	p.PaymentID = strings.Clone(paymentID)
+	r.store.pledges[p.ID] = p
+
+	return r.store.pledge(p), nil
+}
+
+func (r *memoryFunding) ListPledges(ctx context.Context, donatorID string) ([]models.Pledge, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	pledges := []models.Pledge{}
+	for _, p := range r.store.pledges {
+		if p.DonatorID == donatorID {
+			pledges = append(pledges, r.store.pledge(p))
+		}
+	}
+
+	slices.SortFunc(pledges, func(a, b models.Pledge) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+
+	return pledges, nil
+}
+
+func (r *memoryFunding) CreateGoal(ctx context.Context, goal models.FundingGoal) (models.FundingGoal, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	s, ok := r.store.starthubs[goal.StartHubID]
+	if !ok {
+		return goal, ErrNotFound
+	}
+
+	goal.ID = r.store.nextGoalID
+	r.store.nextGoalID++
+	goal.StartHubID = s.ID
+	goal.CreatedAt = now()
+	goal.Raised = 0
+	r.store.goals[goal.ID] = goal
+
+	return goal, nil
+}
+
+func (r *memoryFunding) GetGoal(ctx context.Context, starthubID string, id int) (models.FundingGoal, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	g, ok := r.store.goals[id]
+	if !ok || g.StartHubID != starthubID {
+		return models.FundingGoal{}, ErrNotFound
+	}
+
+	return r.store.goal(g), nil
+}
+
+func (r *memoryFunding) DeleteGoal(ctx context.Context, starthubID string, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	g, ok := r.store.goals[id]
+	if !ok || g.StartHubID != starthubID {
+		return ErrNotFound
+	}
+
+	// Pledges to the goal stay, like ON DELETE SET NULL
+	delete(r.store.goals, id)
+	for pledgeID, p := range r.store.pledges {
+		if p.GoalID != nil && *p.GoalID == id {
+			p.GoalID = nil
+			r.store.pledges[pledgeID] = p
+		}
+	}
+
+	return nil
+}
+
+func (r *memoryFunding) Progress(ctx context.Context, starthubID string) (models.FundingProgress, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.starthubs[starthubID]; !ok {
+		return models.FundingProgress{}, ErrNotFound
+	}
+
+	progress := models.FundingProgress{
+		StartHubID: starthubID,
+		Totals:     []models.FundingTotal{},
+		Goals:      []models.FundingGoal{},
+	}
+
+	donators := map[string]bool{}
+	totals := map[string]models.FundingTotal{}
+	for _, p := range r.store.pledges {
+		if p.StartHub.ID != starthubID || p.Status != models.PledgePaid {
+			continue
+		}
+		if p.DonatorID != "" {
+			donators[p.DonatorID] = true
+		}
+		total := totals[p.Currency]
+		total.Currency = p.Currency
+		total.Amount += p.Amount
+		total.Pledges++
+		totals[p.Currency] = total
+	}
+	progress.Donators = len(donators)
+
+	for _, total := range totals {
+		progress.Totals = append(progress.Totals, total)
+	}
+	slices.SortFunc(progress.Totals, func(a, b models.FundingTotal) int {
+		return strings.Compare(a.Currency, b.Currency)
+	})
+
+	// Goals with a deadline come first, soonest first
+	for _, g := range r.store.goals {
+		if g.StartHubID == starthubID {
+			progress.Goals = append(progress.Goals, r.store.goal(g))
+		}
+	}
+	slices.SortFunc(progress.Goals, func(a, b models.FundingGoal) int {
+		switch {
+		case a.Deadline != nil && b.Deadline == nil:
+			return -1
+		case a.Deadline == nil && b.Deadline != nil:
+			return 1
+		case a.Deadline != nil && !a.Deadline.Equal(*b.Deadline):
+			return a.Deadline.Compare(*b.Deadline)
+		}
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), a.ID-b.ID)
+	})
+
+	return progress, nil
+}
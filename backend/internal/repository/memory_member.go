@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+)
+
+type memoryMembers struct {
+	store *memoryStore
+}
+
+// member returns a team member with their email. The caller holds the lock.
+func (m *memoryStore) member(key memberKey) (models.Member, bool) {
+	row, ok := m.members[key]
+	if !ok {
+		return models.Member{}, false
+	}
+
+	return models.Member{
+		UserID:   key.userID,
+		Email:    m.users[key.userID].Email,
+		Role:     row.role,
+		JoinedAt: row.joinedAt,
+	}, true
+}
+
+// owners returns the owners of a starthub. The caller holds the lock.
+func (m *memoryStore) owners(starthubID string) []string {
+	var owners []string
+	for key, row := range m.members {
+		if key.starthubID == starthubID && row.role == models.MemberOwner {
+			owners = append(owners, key.userID)
+		}
+	}
+	return owners
+}
+
+func (r *memoryMembers) List(ctx context.Context, starthubID string) ([]models.Member, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	members := []models.Member{}
+	for key := range r.store.members {
+		if key.starthubID == starthubID {
+			member, _ := r.store.member(key)
+			members = append(members, member)
+		}
+	}
+
+	slices.SortFunc(members, func(a, b models.Member) int {
+		return cmp.Or(
+			cmp.Compare(slices.Index(models.MemberRoles, a.Role), slices.Index(models.MemberRoles, b.Role)),
+			a.JoinedAt.Compare(b.JoinedAt),
+			strings.Compare(a.Email, b.Email),
+		)
+	})
+
+	return members, nil
+}
+
+func (r *memoryMembers) SetRole(ctx context.Context, starthubID, userID, role string) (models.Member, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := memberKey{starthubID, userID}
+	row, ok := r.store.members[key]
+	if !ok {
+		return models.Member{}, ErrNotFound
+	}
+	if role != models.MemberOwner && slices.Equal(r.store.owners(starthubID), []string{userID}) {
+		return models.Member{}, ErrLastOwner
+	}
+
+	row.role = strings.Clone(role)
+	r.store.members[key] = row
+
+	member, _ := r.store.member(key)
+	return member, nil
+}
+
+func (r *memoryMembers) Remove(ctx context.Context, starthubID, userID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := memberKey{starthubID, userID}
+	if _, ok := r.store.members[key]; !ok {
+		return ErrNotFound
+	}
+	if slices.Equal(r.store.owners(starthubID), []string{userID}) {
+		return ErrLastOwner
+	}
+
+	delete(r.store.members, key)
+	return nil
+}
+
+func (r *memoryMembers) TransferOwnership(ctx context.Context, starthubID, fromUserID, toUserID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	to, ok := r.store.members[memberKey{starthubID, toUserID}]
+	if !ok {
+		return ErrNotFound
+	}
+	to.role = models.MemberOwner
+	r.store.members[memberKey{starthubID, toUserID}] = to
+
+	if from, ok := r.store.members[memberKey{starthubID, fromUserID}]; ok {
+		from.role = models.MemberEditor
+		r.store.members[memberKey{starthubID, fromUserID}] = from
+	}
+
+	return nil
+}
+
+func (r *memoryMembers) Invite(ctx context.Context, invitation models.Invitation, tokenHash string) (models.Invitation, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.starthubs[invitation.StartHubID]; !ok {
+		return invitation, ErrNotFound
+	}
+
+	// Someone already on the team has nothing to accept
+	for key := range r.store.members {
+		if key.starthubID == invitation.StartHubID && strings.EqualFold(r.store.users[key.userID].Email, invitation.Email) {
+			return invitation, &ConflictError{Constraint: "starthub_members_pkey"}
+		}
+	}
+
+	// An expired invitation doesn't block a new one
+	current := now()
+	for id, existing := range r.store.invitations {
+		if existing.StartHubID != invitation.StartHubID || existing.AcceptedAt != nil || !strings.EqualFold(existing.Email, invitation.Email) {
+			continue
+		}
+		if existing.ExpiresAt.After(current) {
+			return invitation, &ConflictError{Constraint: "idx_starthub_invitations_pending"}
+		}
+		delete(r.store.invitations, id)
+	}
+
+	invitation.ID = newID()
+	invitation.CreatedAt = current
+	invitation.StartHubID = strings.Clone(invitation.StartHubID)
+	invitation.InvitedBy = strings.Clone(invitation.InvitedBy)
+	r.store.invitations[invitation.ID] = &memoryInvitation{Invitation: invitation, tokenHash: tokenHash}
+
+	return invitation, nil
+}
+
+func (r *memoryMembers) ListInvitations(ctx context.Context, starthubID string) ([]models.Invitation, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	invitations := []models.Invitation{}
+	for _, i := range r.store.invitations {
+		if i.StartHubID == starthubID && i.AcceptedAt == nil {
+			invitations = append(invitations, i.Invitation)
+		}
+	}
+
+	slices.SortFunc(invitations, func(a, b models.Invitation) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	return invitations, nil
+}
+
+func (r *memoryMembers) RevokeInvitation(ctx context.Context, starthubID, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	i, ok := r.store.invitations[id]
+	if !ok || i.StartHubID != starthubID || i.AcceptedAt != nil {
+		return ErrNotFound
+	}
+
+	delete(r.store.invitations, i.ID)
+	return nil
+}
+
+func (r *memoryMembers) InvitationByToken(ctx context.Context, tokenHash string) (models.Invitation, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, i := range r.store.invitations {
+		if i.tokenHash == tokenHash && i.AcceptedAt == nil {
+			return i.Invitation, nil
+		}
+	}
+
+	return models.Invitation{}, ErrNotFound
+}
+
+func (r *memoryMembers) AcceptInvitation(ctx context.Context, id, userID string) (models.Member, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current := now()
+	i, ok := r.store.invitations[id]
+	if !ok || i.AcceptedAt != nil || !i.ExpiresAt.After(current) {
+		return models.Member{}, ErrNotFound
+	}
+
+	// Someone already on the team keeps their role and the invitation stays open
+	key := memberKey{i.StartHubID, strings.Clone(userID)}
+	if _, ok := r.store.members[key]; ok {
+		return models.Member{}, &ConflictError{Constraint: "starthub_members_pkey"}
+	}
+
+	i.AcceptedAt = &current
+	r.store.members[key] = memoryMember{role: i.Role, joinedAt: current}
+
+	member, _ := r.store.member(key)
+	return member, nil
+}
+
+func (r *memoryMembers) StartHubsOf(ctx context.Context, userID string, roles []string) ([]models.TeamStartHub, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	starthubs := []models.TeamStartHub{}
+	for key, m := range r.store.members {
+		if key.userID != userID || (len(roles) > 0 && !slices.Contains(roles, m.role)) {
+			continue
+		}
+		starthubs = append(starthubs, models.TeamStartHub{
+			StartHub: r.store.summary(key.starthubID),
+			Role:     m.role,
+			JoinedAt: m.joinedAt,
+		})
+	}
+
+	slices.SortFunc(starthubs, func(a, b models.TeamStartHub) int {
+		return cmp.Or(strings.Compare(a.StartHub.Name, b.StartHub.Name), strings.Compare(a.StartHub.ID, b.StartHub.ID))
+	})
+
+	return starthubs, nil
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+)
+
+type memoryOpenRoles struct {
+	store *memoryStore
+}
+
+// openRole returns a stored role with its starthub. The caller holds the lock.
+func (m *memoryStore) openRole(role models.OpenRole) models.OpenRole {
+	role.StartHub = m.summary(role.StartHub.ID)
+	role.Skills = slices.Clone(role.Skills)
+	return role
+}
+
+// application returns a stored application with its role and the email of the
+// applicant. The caller holds the lock.
+func (m *memoryStore) application(a models.Application) models.Application {
+	a.Role = m.openRole(m.openRoles[a.Role.ID])
+	a.ApplicantEmail = m.users[a.ApplicantID].Email
+	return a
+}
+
+// sortApplications orders applications newest first
+func sortApplications(applications []models.Application) {
+	slices.SortFunc(applications, func(a, b models.Application) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+}
+
+func (r *memoryOpenRoles) CreateRole(ctx context.Context, role models.OpenRole) (models.OpenRole, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	s, ok := r.store.starthubs[role.StartHub.ID]
+	if !ok {
+		return role, ErrNotFound
+	}
+
+	role.ID = newID()
+	role.StartHub = models.StartHubSummary{ID: s.ID}
+	role.Skills = slices.Clone(role.Skills)
+	role.Status = models.OpenRoleOpen
+	role.CreatedAt = now()
+	r.store.openRoles[role.ID] = role
+
+	return r.store.openRole(role), nil
+}
+
+func (r *memoryOpenRoles) GetRole(ctx context.Context, id string) (models.OpenRole, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	role, ok := r.store.openRoles[id]
+	if !ok {
+		return role, ErrNotFound
+	}
+
+	return r.store.openRole(role), nil
+}
+
+func (r *memoryOpenRoles) ListRoles(ctx context.Context, filter models.OpenRoleFilter) ([]models.OpenRole, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	roles := []models.OpenRole{}
+	for _, role := range r.store.openRoles {
+		switch {
+		case role.Status != models.OpenRoleOpen,
+			filter.StartHubID != "" && role.StartHub.ID != filter.StartHubID,
+			filter.Skill != "" && !slices.Contains(role.Skills, filter.Skill),
+			filter.Remote != nil && role.Remote != *filter.Remote,
+			filter.Commitment != "" && role.Commitment != filter.Commitment:
+			continue
+		}
+		roles = append(roles, r.store.openRole(role))
+	}
+
+	slices.SortFunc(roles, func(a, b models.OpenRole) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+
+	return roles, nil
+}
+
+func (r *memoryOpenRoles) CloseRole(ctx context.Context, starthubID, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	role, ok := r.store.openRoles[id]
+	if !ok || role.StartHub.ID != starthubID {
+		return ErrNotFound
+	}
+
+	role.Status = models.OpenRoleClosed
+	r.store.openRoles[role.ID] = role
+
+	return nil
+}
+
+func (r *memoryOpenRoles) Apply(ctx context.Context, application models.Application) (models.Application, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	role, ok := r.store.openRoles[application.Role.ID]
+	if !ok || role.Status != models.OpenRoleOpen {
+		return application, ErrNotFound
+	}
+
+	// Like idx_role_applications_active
+	for _, a := range r.store.applications {
+		if a.Role.ID == role.ID && a.ApplicantID == application.ApplicantID && a.Status != models.ApplicationWithdrawn {
+			return application, &ConflictError{Constraint: "idx_role_applications_active"}
+		}
+	}
+
+	application.ID = newID()
+	application.Role = models.OpenRole{ID: role.ID}
+	application.ApplicantID = strings.Clone(application.ApplicantID)
+	application.Status = models.ApplicationPending
+	application.ExternalCollaboratorID = nil
+	application.CreatedAt = now()
+	application.DecidedAt = nil
+	r.store.applications[application.ID] = application
+
+	return r.store.application(application), nil
+}
+
+func (r *memoryOpenRoles) ListApplications(ctx context.Context, starthubID string, statuses []string) ([]models.Application, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	applications := []models.Application{}
+	for _, a := range r.store.applications {
+		if r.store.openRoles[a.Role.ID].StartHub.ID != starthubID {
+			continue
+		}
+		if len(statuses) > 0 && !slices.Contains(statuses, a.Status) {
+			continue
+		}
+		applications = append(applications, r.store.application(a))
+	}
+	sortApplications(applications)
+
+	return applications, nil
+}
+
+func (r *memoryOpenRoles) ListMyApplications(ctx context.Context, applicantID string) ([]models.Application, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	applications := []models.Application{}
+	for _, a := range r.store.applications {
+		if a.ApplicantID == applicantID {
+			applications = append(applications, r.store.application(a))
+		}
+	}
+	sortApplications(applications)
+
+	return applications, nil
+}
+
+func (r *memoryOpenRoles) Decide(ctx context.Context, starthubID, id, status string) (models.Application, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	a, ok := r.store.applications[id]
+	if !ok || a.Status != models.ApplicationPending {
+		return a, ErrNotFound
+	}
+	role := r.store.openRoles[a.Role.ID]
+	if role.StartHub.ID != starthubID {
+		return a, ErrNotFound
+	}
+
+	decidedAt := now()
+	a.Status = strings.Clone(status)
+	a.DecidedAt = &decidedAt
+
+	// Accepted applicants show up as external collaborators
+	if status == models.ApplicationAccepted {
+		collaborator := r.store.addExternal(role.StartHub.ID, models.ExternalCollaborator{
+			Name: a.Name,
+			URL:  a.URL,
+			Role: role.Title,
+		})
+		a.ExternalCollaboratorID = &collaborator.ID
+	}
+	r.store.applications[a.ID] = a
+
+	return r.store.application(a), nil
+}
+
+func (r *memoryOpenRoles) Withdraw(ctx context.Context, applicantID, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	a, ok := r.store.applications[id]
+	if !ok || a.ApplicantID != applicantID || a.Status != models.ApplicationPending {
+		return ErrNotFound
+	}
+
+	decidedAt := now()
+	a.Status = models.ApplicationWithdrawn
+	a.DecidedAt = &decidedAt
+	r.store.applications[a.ID] = a
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+)
+
+type memoryPipeline struct {
+	store *memoryStore
+}
+
+// entry returns a row of the pipeline with its starthub. The caller holds the lock.
+func (m *memoryStore) entry(key interestKey) models.PipelineEntry {
+	row := m.interests[key]
+	return models.PipelineEntry{
+		StartHub:  m.summary(key.starthubID),
+		Stage:     row.stage,
+		Notes:     row.notes,
+		CreatedAt: row.createdAt,
+		UpdatedAt: row.updatedAt,
+	}
+}
+
+func (r *memoryPipeline) List(ctx context.Context, investorID string, stages []string) ([]models.PipelineEntry, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	entries := []models.PipelineEntry{}
+	for key, row := range r.store.interests {
+		if key.investorID != investorID || (len(stages) > 0 && !slices.Contains(stages, row.stage)) {
+			continue
+		}
+		entries = append(entries, r.store.entry(key))
+	}
+
+	slices.SortFunc(entries, func(a, b models.PipelineEntry) int {
+		return cmp.Or(b.UpdatedAt.Compare(a.UpdatedAt), strings.Compare(a.StartHub.ID, b.StartHub.ID))
+	})
+
+	return entries, nil
+}
+
+func (r *memoryPipeline) Save(ctx context.Context, investorID, starthubID string, stage, notes *string) (models.PipelineEntry, bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	s, ok := r.store.starthubs[starthubID]
+	if !ok {
+		return models.PipelineEntry{}, false, ErrNotFound
+	}
+
+	key := interestKey{strings.Clone(investorID), s.ID}
+	row, exists := r.store.interests[key]
+	if !exists {
+		row.createdAt = now()
+		row.stage = models.StageWatching
+	}
+	if stage != nil {
+		row.stage = strings.Clone(*stage)
+	}
+	if notes != nil {
+		row.notes = strings.Clone(*notes)
+	}
+	row.updatedAt = now()
+	r.store.interests[key] = row
+
+	return r.store.entry(key), !exists, nil
+}
+
+func (r *memoryPipeline) Delete(ctx context.Context, investorID, starthubID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := interestKey{investorID, starthubID}
+	if _, ok := r.store.interests[key]; !ok {
+		return ErrNotFound
+	}
+
+	delete(r.store.interests, key)
+	return nil
+}
+
+func (r *memoryPipeline) InterestCount(ctx context.Context, starthubID string) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	count := 0
+	for key, row := range r.store.interests {
+		if key.starthubID == starthubID && row.stage != models.StagePassed {
+			count++
+		}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,503 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+)
+
+type memoryStartHubs struct {
+	store *memoryStore
+}
+
+// matches reports whether the starthub passes the filter. The caller holds the lock.
+func (m *memoryStore) matches(s models.StartHub, f StartHubFilter) bool {
+	if len(f.Categories) > 0 {
+		found := false
+		for id := range m.starthubCategories[s.ID] {
+			if slices.Contains(f.Categories, strings.ToLower(m.categories[id])) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Location != "" && !strings.Contains(strings.ToLower(s.Location), strings.ToLower(f.Location)) {
+		return false
+	}
+	if f.MinTeamSize != nil && s.TeamSize < *f.MinTeamSize {
+		return false
+	}
+	if f.MaxTeamSize != nil && s.TeamSize > *f.MaxTeamSize {
+		return false
+	}
+
+	return true
+}
+
+// cursorStartHub builds a starthub that sits exactly at the cursor position
+func cursorStartHub(sort starthubSort, cur Cursor) (models.StartHub, error) {
+	pivot := models.StartHub{ID: cur.ID}
+
+	value, err := sort.decode(cur.Value)
+	if err != nil {
+		return pivot, err
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		pivot.JoinDate = v
+	case string:
+		pivot.Name = v
+	case int:
+		pivot.TeamSize = v
+	}
+
+	return pivot, nil
+}
+
+func (r *memoryStartHubs) List(ctx context.Context, opts ListOptions) ([]models.StartHub, int, error) {
+	sort, ok := starthubSorts[opts.Sort]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown sort %q", opts.Sort)
+	}
+
+	// Sort by key, then id, in the direction of the sort order
+	compare := func(a, b models.StartHub) int {
+		c := cmp.Or(sort.compare(a, b), strings.Compare(a.ID, b.ID))
+		if sort.desc {
+			return -c
+		}
+		return c
+	}
+
+	var pivot *models.StartHub
+	if opts.After != nil {
+		p, err := cursorStartHub(sort, *opts.After)
+		if err != nil {
+			return nil, 0, ErrInvalidCursor
+		}
+		pivot = &p
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	total := 0
+	var page []models.StartHub
+	for _, s := range r.store.starthubs {
+		if !r.store.matches(s, opts.Filter) {
+			continue
+		}
+		total++
+
+		if pivot == nil || compare(s, *pivot) > 0 {
+			page = append(page, s)
+		}
+	}
+
+	slices.SortFunc(page, compare)
+	if len(page) > opts.Limit {
+		page = page[:opts.Limit]
+	}
+
+	starthubs := make([]models.StartHub, len(page))
+	for i, s := range page {
+		starthubs[i] = r.store.hydrate(s)
+	}
+
+	return starthubs, total, nil
+}
+
+// Search weights, the defaults of Postgres for name (A), categories (B),
+// description (C) and location (D)
+var searchWeights = []float32{1.0, 0.4, 0.2, 0.1}
+
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// hasPrefixWord reports whether any word of text starts with term
+func hasPrefixWord(text, term string) bool {
+	return slices.ContainsFunc(searchWords(text), func(word string) bool {
+		return strings.HasPrefix(word, term)
+	})
+}
+
+// highlight HTML-escapes text and wraps every word that starts with one of the
+// terms in <mark> tags
+func highlight(text string, terms []string) string {
+	var b strings.Builder
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); {
+		if !unicode.IsLetter(runes[i]) && !unicode.IsDigit(runes[i]) {
+			b.WriteString(html.EscapeString(string(runes[i])))
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+			j++
+		}
+		word := string(runes[i:j])
+
+		lower := strings.ToLower(word)
+		if slices.ContainsFunc(terms, func(term string) bool { return strings.HasPrefix(lower, term) }) {
+			b.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+		i = j
+	}
+
+	return b.String()
+}
+
+func (r *memoryStartHubs) Search(ctx context.Context, opts SearchOptions) ([]models.StartHubSearchResult, int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var results []models.StartHubSearchResult
+	for _, s := range r.store.starthubs {
+		if !r.store.matches(s, opts.Filter) {
+			continue
+		}
+
+		hydrated := r.store.hydrate(s)
+		fields := []string{s.Name, strings.Join(hydrated.Categories, " "), s.Description, s.Location}
+
+		// Every term has to match somewhere, each adds the weight of the best field it matched
+		var rank float32
+		matched := true
+		for _, term := range opts.Terms {
+			best := float32(0)
+			for i, field := range fields {
+				if hasPrefixWord(field, term) {
+					best = max(best, searchWeights[i])
+				}
+			}
+			if best == 0 {
+				matched = false
+				break
+			}
+			rank += best
+		}
+		if !matched {
+			continue
+		}
+
+		results = append(results, models.StartHubSearchResult{
+			StartHub: hydrated,
+			Rank:     rank,
+			Highlight: models.SearchHighlight{
+				Name:        highlight(s.Name, opts.Terms),
+				Description: highlight(s.Description, opts.Terms),
+			},
+		})
+	}
+
+	slices.SortFunc(results, func(a, b models.StartHubSearchResult) int {
+		return cmp.Or(
+			cmp.Compare(b.Rank, a.Rank),
+			b.JoinDate.Compare(a.JoinDate),
+			strings.Compare(a.ID, b.ID),
+		)
+	})
+
+	total := len(results)
+	results = results[min(opts.Offset, total):]
+	results = results[:min(opts.Limit, len(results))]
+
+	return append([]models.StartHubSearchResult{}, results...), total, nil
+}
+
+func (r *memoryStartHubs) Get(ctx context.Context, id string) (models.StartHub, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	s, ok := r.store.starthubs[id]
+	if !ok {
+		return models.StartHub{}, ErrNotFound
+	}
+
+	return r.store.hydrate(s), nil
+}
+
+func (r *memoryStartHubs) Summary(ctx context.Context, id string) (models.StartHubSummary, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.starthubs[id]; !ok {
+		return models.StartHubSummary{}, ErrNotFound
+	}
+
+	return r.store.summary(id), nil
+}
+
+func (r *memoryStartHubs) MemberRole(ctx context.Context, id, userID string) (string, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.members[memberKey{id, userID}].role, nil
+}
+
+// hasMemberRole reports whether the user has one of the roles on the team of
+// the starthub. The caller holds the lock.
+func (m *memoryStore) hasMemberRole(starthubID, userID string, roles []string) bool {
+	member, ok := m.members[memberKey{starthubID, userID}]
+	return ok && slices.Contains(roles, member.role)
+}
+
+// emailTaken reports whether another starthub uses the email. The caller holds the lock.
+func (m *memoryStore) emailTaken(email, exceptID string) bool {
+	for id, s := range m.starthubs {
+		if id != exceptID && s.Email == email {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memoryStartHubs) Create(ctx context.Context, s models.StartHub) (models.StartHub, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.store.emailTaken(s.Email, "") {
+		return s, &ConflictError{Constraint: "starthubs_email_key"}
+	}
+
+	s.ID = newID()
+	s.JoinDate = now()
+	s.UpdatedAt = s.JoinDate
+	s.Version = 1
+
+	row := s
+	row.Categories, row.CollaboratingStarthubs, row.ExternalCollaborators = nil, nil, nil
+	row.CreatedBy = strings.Clone(s.CreatedBy)
+	r.store.starthubs[s.ID] = row
+	r.store.members[memberKey{s.ID, row.CreatedBy}] = memoryMember{role: models.MemberOwner, joinedAt: s.JoinDate}
+
+	if len(s.Categories) > 0 {
+		s.Categories = r.store.setCategories(s.ID, s.Categories)
+	}
+
+	return s, nil
+}
+
+func (r *memoryStartHubs) Update(ctx context.Context, userID string, s models.StartHub, versions Versions) (models.StartHub, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	row, ok := r.store.starthubs[s.ID]
+	if !ok || !r.store.hasMemberRole(s.ID, userID, models.EditorRoles) {
+		return s, ErrNotFound
+	}
+	if err := checkVersion(row, versions); err != nil {
+		return s, err
+	}
+	if r.store.emailTaken(s.Email, s.ID) {
+		return s, &ConflictError{Constraint: "starthubs_email_key"}
+	}
+
+	row.Name = s.Name
+	row.Description = s.Description
+	row.Location = s.Location
+	row.TeamSize = s.TeamSize
+	row.URL = s.URL
+	row.Email = s.Email
+	row.Version++
+	row.UpdatedAt = now()
+	r.store.starthubs[row.ID] = row
+
+	if s.Categories != nil {
+		r.store.setCategories(row.ID, s.Categories)
+	}
+
+	return r.store.hydrate(row), nil
+}
+
+func (r *memoryStartHubs) Delete(ctx context.Context, id, userID string, versions Versions) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	s, ok := r.store.starthubs[id]
+	if !ok || !r.store.hasMemberRole(id, userID, []string{models.MemberOwner}) {
+		return ErrNotFound
+	}
+	if err := checkVersion(s, versions); err != nil {
+		return err
+	}
+
+	// Cascade like the foreign keys do
+	delete(r.store.starthubs, id)
+	delete(r.store.starthubCategories, id)
+	r.store.collaborations = slices.DeleteFunc(r.store.collaborations, func(c *memoryCollaboration) bool {
+		return c.starthubID == id || c.collaboratorID == id
+	})
+	for externalID, e := range r.store.externals {
+		if e.starthubID == id {
+			delete(r.store.externals, externalID)
+		}
+	}
+	for key := range r.store.members {
+		if key.starthubID == id {
+			delete(r.store.members, key)
+		}
+	}
+	for invitationID, i := range r.store.invitations {
+		if i.StartHubID == id {
+			delete(r.store.invitations, invitationID)
+		}
+	}
+	for key := range r.store.interests {
+		if key.starthubID == id {
+			delete(r.store.interests, key)
+		}
+	}
+	for goalID, g := range r.store.goals {
+		if g.StartHubID == id {
+			delete(r.store.goals, goalID)
+		}
+	}
+	// Pledges are payment records and stay, like ON DELETE SET NULL
+	for pledgeID, p := range r.store.pledges {
+		if p.StartHub.ID == id {
+			p.StartHub.ID = ""
+			r.store.pledges[pledgeID] = p
+		}
+	}
+	for roleID, role := range r.store.openRoles {
+		if role.StartHub.ID == id {
+			delete(r.store.openRoles, roleID)
+		}
+	}
+	for applicationID, a := range r.store.applications {
+		if _, ok := r.store.openRoles[a.Role.ID]; !ok {
+			delete(r.store.applications, applicationID)
+		}
+	}
+
+	return nil
+}
+
+func (r *memoryStartHubs) SetImageURL(ctx context.Context, id, userID, imageURL string) (string, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	row, ok := r.store.starthubs[id]
+	if !ok || !r.store.hasMemberRole(id, userID, models.EditorRoles) {
+		return "", ErrNotFound
+	}
+
+	previous := row.ImageURL
+	row.ImageURL = strings.Clone(imageURL)
+	row.Version++
+	row.UpdatedAt = now()
+	r.store.starthubs[row.ID] = row
+
+	return previous, nil
+}
+
+func (r *memoryStartHubs) ListExternalCollaborators(ctx context.Context, starthubID string) ([]models.ExternalCollaborator, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.starthubs[starthubID]; !ok {
+		return nil, ErrNotFound
+	}
+
+	collaborators := r.store.externalsOf(starthubID)
+	if collaborators == nil {
+		collaborators = []models.ExternalCollaborator{}
+	}
+
+	return collaborators, nil
+}
+
+func (r *memoryStartHubs) AddExternalCollaborator(ctx context.Context, starthubID string, collaborator models.ExternalCollaborator) (models.ExternalCollaborator, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.starthubs[starthubID]; !ok {
+		return collaborator, ErrNotFound
+	}
+
+	return r.store.addExternal(strings.Clone(starthubID), collaborator), nil
+}
+
+// addExternal appends a collaborator to the list of a starthub, like
+// insertExternalCollaborator. The caller holds the lock.
+func (m *memoryStore) addExternal(starthubID string, collaborator models.ExternalCollaborator) models.ExternalCollaborator {
+	collaborator.ID = m.nextExternalID
+	m.nextExternalID++
+
+	collaborator.Position = 0
+	for _, existing := range m.externalsOf(starthubID) {
+		collaborator.Position = max(collaborator.Position, existing.Position+1)
+	}
+
+	m.externals[collaborator.ID] = memoryExternal{
+		starthubID:           starthubID,
+		ExternalCollaborator: collaborator,
+	}
+	m.touch(starthubID)
+
+	return collaborator
+}
+
+func (r *memoryStartHubs) ReorderExternalCollaborators(ctx context.Context, starthubID string, ids []int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var current []int
+	for _, e := range r.store.externalsOf(starthubID) {
+		current = append(current, e.ID)
+	}
+
+	if !sameIDs(current, ids) {
+		return ErrInvalidOrder
+	}
+
+	for position, id := range ids {
+		e := r.store.externals[id]
+		e.Position = position
+		r.store.externals[id] = e
+	}
+	r.store.touch(starthubID)
+
+	return nil
+}
+
+func (r *memoryStartHubs) DeleteExternalCollaborator(ctx context.Context, starthubID string, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	e, ok := r.store.externals[id]
+	if !ok || e.starthubID != starthubID {
+		return ErrNotFound
+	}
+
+	// Applications that added it stay, like ON DELETE SET NULL
+	delete(r.store.externals, id)
+	for applicationID, a := range r.store.applications {
+		if a.ExternalCollaboratorID != nil && *a.ExternalCollaboratorID == id {
+			a.ExternalCollaboratorID = nil
+			r.store.applications[applicationID] = a
+		}
+	}
+	r.store.touch(starthubID)
+	return nil
+}
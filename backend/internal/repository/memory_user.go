@@ -0,0 +1,530 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+)
+
+type memoryUsers struct {
+	store *memoryStore
+}
+
+func (r *memoryUsers) Create(ctx context.Context, user models.User) (models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.users {
+		if existing.Email == user.Email {
+			return user, &ConflictError{Constraint: "users_email_key"}
+		}
+	}
+
+	user.ID = newID()
+	user.Profile.Links = slices.Clone(user.Profile.Links)
+	user.CreatedAt = now()
+	r.store.users[user.ID] = user
+
+	return user, nil
+}
+
+func (r *memoryUsers) GetByEmail(ctx context.Context, email string) (models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, user := range r.store.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+
+	return models.User{}, ErrNotFound
+}
+
+func (r *memoryUsers) GetByID(ctx context.Context, id string) (models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return user, ErrNotFound
+	}
+
+	user.Profile.Links = slices.Clone(user.Profile.Links)
+	return user, nil
+}
+
+func (r *memoryUsers) UpdateProfile(ctx context.Context, id string, profile models.Profile, settings models.AccountSettings) (models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return user, ErrNotFound
+	}
+
+	profile.Links = slices.Clone(profile.Links)
+	user.Profile = profile
+	user.Settings = settings
+	r.store.users[user.ID] = user
+
+	user.Profile.Links = slices.Clone(user.Profile.Links)
+	return user, nil
+}
+
+func (r *memoryUsers) CreateSession(ctx context.Context, session models.Session) (string, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[session.UserID]; !ok {
+		return "", ErrNotFound
+	}
+
+	session.ID = newID()
+	session.UserAgent = strings.Clone(session.UserAgent)
+	session.IPAddress = strings.Clone(session.IPAddress)
+	session.CreatedAt = now()
+	session.LastUsedAt = session.CreatedAt
+	r.store.sessions[session.ID] = session
+
+	return session.ID, nil
+}
+
+// active reports whether the session can still be used. The caller holds the lock.
+func (m *memoryStore) active(session models.Session) bool {
+	return session.RevokedAt == nil && session.ExpiresAt.After(now())
+}
+
+func (r *memoryUsers) SessionActive(ctx context.Context, sessionID, userID string) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	session, ok := r.store.sessions[sessionID]
+	return ok && session.UserID == userID && r.store.active(session), nil
+}
+
+func (r *memoryUsers) RotateSession(ctx context.Context, sessionID string, matches func(string) bool, newHash string) (models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	session, ok := r.store.sessions[sessionID]
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+	user := r.store.users[session.UserID]
+
+	if !r.store.active(session) {
+		return user, ErrSessionInactive
+	}
+
+	if !matches(session.RefreshTokenHash) {
+		if !slices.ContainsFunc(session.RetiredHashes, matches) {
+			return user, ErrNotFound
+		}
+
+		r.store.revoke(sessionID, "refresh_token_reuse")
+		return user, ErrTokenReuse
+	}
+
+	session.RetiredHashes = append(slices.Clip(session.RetiredHashes), session.RefreshTokenHash)
+	session.RefreshTokenHash = newHash
+	session.LastUsedAt = now()
+	r.store.sessions[sessionID] = session
+
+	return user, nil
+}
+
+// revoke ends a session if it is still active. The caller holds the lock.
+func (m *memoryStore) revoke(sessionID, reason string) bool {
+	session, ok := m.sessions[sessionID]
+	if !ok || session.RevokedAt != nil {
+		return false
+	}
+
+	revokedAt := now()
+	session.RevokedAt = &revokedAt
+	session.RevokedReason = reason
+	m.sessions[sessionID] = session
+
+	return true
+}
+
+func (r *memoryUsers) RevokeSession(ctx context.Context, sessionID, reason string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.revoke(sessionID, reason)
+	return nil
+}
+
+func (r *memoryUsers) RevokeUserSessions(ctx context.Context, userID, reason string) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var revoked int64
+	for id, session := range r.store.sessions {
+		if session.UserID == userID && r.store.revoke(id, reason) {
+			revoked++
+		}
+	}
+
+	return revoked, nil
+}
+
+func (r *memoryUsers) ChangePassword(ctx context.Context, userID, passwordHash, keepSessionID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	user.Password = passwordHash
+	r.store.users[user.ID] = user
+
+	for id, session := range r.store.sessions {
+		if session.UserID == user.ID && id != keepSessionID {
+			r.store.revoke(id, "password_change")
+		}
+	}
+
+	return nil
+}
+
+func (r *memoryUsers) CreatePasswordReset(ctx context.Context, userID, tokenHash string, expiresAt time.Time, interval time.Duration) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.insertThrottledToken(r.store.passwordResets, "password_resets", userID, tokenHash, expiresAt, interval)
+}
+
+func (r *memoryUsers) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current := now()
+	reset, ok := r.store.passwordResets[tokenHash]
+	if !ok || reset.usedAt != nil || !reset.expiresAt.After(current) {
+		return models.User{}, ErrNotFound
+	}
+
+	for _, other := range r.store.passwordResets {
+		if other.userID == reset.userID && other.usedAt == nil {
+			other.usedAt = &current
+		}
+	}
+
+	user := r.store.users[reset.userID]
+	user.Password = passwordHash
+	user.LockedUntil = nil
+	r.store.users[user.ID] = user
+	delete(r.store.failedLogins, user.ID)
+
+	for id, session := range r.store.sessions {
+		if session.UserID == user.ID {
+			r.store.revoke(id, "password_reset")
+		}
+	}
+
+	user.Profile.Links = slices.Clone(user.Profile.Links)
+	return user, nil
+}
+
+func (r *memoryUsers) CreateEmailVerification(ctx context.Context, userID, tokenHash string, expiresAt time.Time, interval time.Duration) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.insertThrottledToken(r.store.verifications, "email_verifications", userID, tokenHash, expiresAt, interval)
+}
+
+// insertThrottledToken stores an emailed token of a user in tokens, unless the
+// previous one was created less than interval ago. The caller holds the lock.
+func (m *memoryStore) insertThrottledToken(tokens map[string]*memoryUserToken, table, userID, tokenHash string, expiresAt time.Time, interval time.Duration) error {
+	user, ok := m.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	current := now()
+	for _, previous := range tokens {
+		if wait := previous.createdAt.Add(interval).Sub(current); previous.userID == user.ID && wait > 0 {
+			return &ThrottledError{RetryAfter: wait}
+		}
+	}
+	if _, ok := tokens[tokenHash]; ok {
+		return &ConflictError{Constraint: table + "_token_hash_key"}
+	}
+
+	tokens[tokenHash] = &memoryUserToken{userID: user.ID, createdAt: current, expiresAt: expiresAt}
+	return nil
+}
+
+func (r *memoryUsers) VerifyEmail(ctx context.Context, tokenHash string) (models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current := now()
+	verification, ok := r.store.verifications[tokenHash]
+	if !ok || verification.usedAt != nil || !verification.expiresAt.After(current) {
+		return models.User{}, ErrNotFound
+	}
+
+	for _, other := range r.store.verifications {
+		if other.userID == verification.userID && other.usedAt == nil {
+			other.usedAt = &current
+		}
+	}
+
+	user := r.store.users[verification.userID]
+	if user.EmailVerifiedAt == nil {
+		user.EmailVerifiedAt = &current
+		r.store.users[user.ID] = user
+	}
+
+	user.Profile.Links = slices.Clone(user.Profile.Links)
+	return user, nil
+}
+
+func (r *memoryUsers) AddFailedLogin(ctx context.Context, userID string, lockFor func(int) time.Duration) (*time.Time, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	r.store.failedLogins[user.ID]++
+	lock := lockFor(r.store.failedLogins[user.ID])
+	if lock <= 0 {
+		return nil, nil
+	}
+
+	lockedUntil := now().Add(lock)
+	user.LockedUntil = &lockedUntil
+	r.store.users[user.ID] = user
+
+	return &lockedUntil, nil
+}
+
+func (r *memoryUsers) ClearFailedLogins(ctx context.Context, userID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userID]
+	if !ok {
+		return nil
+	}
+
+	user.LockedUntil = nil
+	r.store.users[user.ID] = user
+	delete(r.store.failedLogins, user.ID)
+
+	return nil
+}
+
+func (r *memoryUsers) RecordLoginAttempt(ctx context.Context, attempt models.LoginAttempt) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if attempt.UserID != "" {
+		user, ok := r.store.users[attempt.UserID]
+		if !ok {
+			return ErrNotFound
+		}
+		attempt.UserID = user.ID
+	}
+
+	attempt.ID = int64(len(r.store.loginAttempts) + 1)
+	attempt.IPAddress = strings.Clone(attempt.IPAddress)
+	attempt.UserAgent = strings.Clone(attempt.UserAgent)
+	attempt.CreatedAt = now()
+	r.store.loginAttempts = append(r.store.loginAttempts, attempt)
+
+	return nil
+}
+
+func (r *memoryUsers) LoginAttempts(ctx context.Context, userID string, limit int) ([]models.LoginAttempt, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// Appended in order, so newest first is back to front
+	attempts := []models.LoginAttempt{}
+	for i := len(r.store.loginAttempts) - 1; i >= 0 && len(attempts) < limit; i-- {
+		if attempt := r.store.loginAttempts[i]; attempt.UserID == userID {
+			attempts = append(attempts, attempt)
+		}
+	}
+
+	return attempts, nil
+}
+
+func (r *memoryUsers) StartTOTPEnrollment(ctx context.Context, userID, secret string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userID]
+	if !ok || user.TOTPEnabledAt != nil {
+		return ErrNotFound
+	}
+
+	user.TOTPSecret = strings.Clone(secret)
+	r.store.users[user.ID] = user
+	delete(r.store.totpLastSteps, user.ID)
+
+	return nil
+}
+
+// setRecoveryCodes replaces the recovery codes of a user. The caller holds the lock.
+func (m *memoryStore) setRecoveryCodes(userID string, codeHashes []string) {
+	codes := map[string]bool{}
+	for _, hash := range codeHashes {
+		codes[strings.Clone(hash)] = false
+	}
+	m.recoveryCodes[userID] = codes
+}
+
+func (r *memoryUsers) EnableTOTP(ctx context.Context, userID string, step int64, recoveryHashes []string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userID]
+	if !ok || user.TOTPSecret == "" || user.TOTPEnabledAt != nil {
+		return ErrNotFound
+	}
+
+	enabledAt := now()
+	user.TOTPEnabledAt = &enabledAt
+	r.store.users[user.ID] = user
+	r.store.totpLastSteps[user.ID] = step
+	r.store.setRecoveryCodes(user.ID, recoveryHashes)
+
+	return nil
+}
+
+func (r *memoryUsers) UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userID]
+	if !ok {
+		return false, nil
+	}
+	if last, ok := r.store.totpLastSteps[user.ID]; ok && last >= step {
+		return false, nil
+	}
+
+	r.store.totpLastSteps[user.ID] = step
+	return true, nil
+}
+
+func (r *memoryUsers) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	codes := r.store.recoveryCodes[userID]
+	if used, ok := codes[codeHash]; !ok || used {
+		return false, nil
+	}
+
+	codes[codeHash] = true
+	return true, nil
+}
+
+func (r *memoryUsers) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	r.store.setRecoveryCodes(user.ID, codeHashes)
+	return nil
+}
+
+func (r *memoryUsers) DisableTOTP(ctx context.Context, userID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	user.TOTPSecret = ""
+	user.TOTPEnabledAt = nil
+	r.store.users[user.ID] = user
+	delete(r.store.totpLastSteps, user.ID)
+	delete(r.store.recoveryCodes, user.ID)
+
+	current := now()
+	for _, challenge := range r.store.mfaChallenges {
+		if challenge.userID == user.ID && challenge.usedAt == nil {
+			challenge.usedAt = &current
+		}
+	}
+
+	return nil
+}
+
+func (r *memoryUsers) CreateMFAChallenge(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+	if _, ok := r.store.mfaChallenges[tokenHash]; ok {
+		return &ConflictError{Constraint: "mfa_challenges_token_hash_key"}
+	}
+
+	r.store.mfaChallenges[tokenHash] = &memoryMFAChallenge{
+		memoryUserToken: memoryUserToken{userID: user.ID, createdAt: now(), expiresAt: expiresAt},
+	}
+	return nil
+}
+
+func (r *memoryUsers) MFAChallengeUser(ctx context.Context, tokenHash string, maxAttempts int) (models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	challenge, ok := r.store.mfaChallenges[tokenHash]
+	if !ok || challenge.usedAt != nil || !challenge.expiresAt.After(now()) || challenge.attempts >= maxAttempts {
+		return models.User{}, ErrNotFound
+	}
+
+	user := r.store.users[challenge.userID]
+	user.Profile.Links = slices.Clone(user.Profile.Links)
+	return user, nil
+}
+
+func (r *memoryUsers) FailMFAChallenge(ctx context.Context, tokenHash string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if challenge, ok := r.store.mfaChallenges[tokenHash]; ok {
+		challenge.attempts++
+	}
+	return nil
+}
+
+func (r *memoryUsers) UseMFAChallenge(ctx context.Context, tokenHash string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	challenge, ok := r.store.mfaChallenges[tokenHash]
+	if !ok || challenge.usedAt != nil {
+		return ErrNotFound
+	}
+
+	current := now()
+	challenge.usedAt = &current
+	return nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// NewPostgres returns repositories backed by the database pool
+func NewPostgres(db *pgxpool.Pool) *Repositories {
+	return &Repositories{
+		StartHubs:      &postgresStartHubs{db: db},
+		Categories:     &postgresCategories{db: db},
+		Collaborations: &postgresCollaborations{db: db},
+		Members:        &postgresMembers{db: db},
+		Pipeline:       &postgresPipeline{db: db},
+		Funding:        &postgresFunding{db: db},
+		OpenRoles:      &postgresOpenRoles{db: db},
+		Users:          &postgresUsers{db: db},
+	}
+}
+
+// execer runs a statement on the pool or inside a transaction
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// translateError turns driver errors into the errors of this package so
+// callers don't have to know about pgx. Anything else is returned as is.
+func translateError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505": // unique_violation
+			return &ConflictError{Constraint: pgErr.ConstraintName, Err: err}
+		case "23503": // foreign_key_violation
+			return fmt.Errorf("%w: %w", ErrMissingReference, err)
+		case "23514": // check_violation
+			return fmt.Errorf("%w: %w", ErrNotAllowed, err)
+		case "22P02": // invalid_text_representation, e.g. a malformed UUID
+			return ErrNotFound
+		}
+	}
+
+	return err
+}
+
+// queryArgs collects positional arguments while a query is being built
+type queryArgs []any
+
+// add appends a value and returns its placeholder
+func (a *queryArgs) add(value any) string {
+	*a = append(*a, value)
+	return "$" + strconv.Itoa(len(*a))
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// categoryNameIndex keeps category names unique in any letter case
+const categoryNameIndex = "idx_categories_lower_name"
+
+type postgresCategories struct {
+	db *pgxpool.Pool
+}
+
+func (r *postgresCategories) List(ctx context.Context) ([]models.Category, error) {
+	query := `
+	SELECT c.id, c.name, COUNT(sc.starthub_id)
+	FROM categories c
+	LEFT JOIN starthub_categories sc ON sc.category_id = c.id
+	GROUP BY c.id, c.name
+	ORDER BY COUNT(sc.starthub_id) DESC, c.name
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []models.Category{}
+	for rows.Next() {
+		var category models.Category
+		if err := rows.Scan(&category.ID, &category.Name, &category.StartHubCount); err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, rows.Err()
+}
+
+func (r *postgresCategories) Rename(ctx context.Context, id int, name string) (models.Category, error) {
+	category := models.Category{ID: id}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return category, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Renaming onto another category's name would silently fork it, that's what
+	// merge is for. The unique index on LOWER(name) refuses it even when two
+	// renames race.
+	query := `
+	UPDATE categories SET name = $1 WHERE id = $2
+	RETURNING name, (SELECT COUNT(*) FROM starthub_categories WHERE category_id = $2)
+	`
+	err = tx.QueryRow(ctx, query, name, id).Scan(&category.Name, &category.StartHubCount)
+	if err != nil {
+		return category, r.nameTaken(ctx, translateError(err), name)
+	}
+
+	// Every starthub in the category now shows another name
+	if err = touchCategoryStartHubs(ctx, tx, []int{id}); err != nil {
+		return category, err
+	}
+
+	return category, tx.Commit(ctx)
+}
+
+// nameTaken turns a unique violation on the category name into a
+// *NameTakenError pointing at the category that has the name. It reads outside
+// the failed transaction, which can't run queries anymore.
+func (r *postgresCategories) nameTaken(ctx context.Context, err error, name string) error {
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.Constraint != categoryNameIndex {
+		return err
+	}
+
+	var existingID int
+	if lookupErr := r.db.QueryRow(ctx, "SELECT id FROM categories WHERE LOWER(name) = LOWER($1)", name).Scan(&existingID); lookupErr != nil {
+		return err
+	}
+	return &NameTakenError{CategoryID: existingID}
+}
+
+func (r *postgresCategories) Merge(ctx context.Context, sourceIDs []int, targetID int) (models.Category, error) {
+	category := models.Category{ID: targetID}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return category, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock every category involved so nobody links to a source mid-merge
+	var found int
+	err = tx.QueryRow(
+		ctx,
+		"SELECT COUNT(*) FROM (SELECT id FROM categories WHERE id = ANY($1) OR id = $2 FOR UPDATE) locked",
+		sourceIDs,
+		targetID,
+	).Scan(&found)
+	if err != nil {
+		return category, err
+	}
+	if found != len(normalizeIDs(sourceIDs))+1 {
+		return category, ErrNotFound
+	}
+
+	if err = touchCategoryStartHubs(ctx, tx, sourceIDs); err != nil {
+		return category, err
+	}
+
+	// Re-point the links, starthubs that already had the target keep a single link
+	linkQuery := `
+	INSERT INTO starthub_categories (starthub_id, category_id)
+	SELECT DISTINCT starthub_id, $2::int FROM starthub_categories WHERE category_id = ANY($1)
+	ON CONFLICT (starthub_id, category_id) DO NOTHING
+	`
+	if _, err = tx.Exec(ctx, linkQuery, sourceIDs, targetID); err != nil {
+		return category, translateError(err)
+	}
+
+	// Deleting the sources cascades to their old links
+	if _, err = tx.Exec(ctx, "DELETE FROM categories WHERE id = ANY($1)", sourceIDs); err != nil {
+		return category, translateError(err)
+	}
+
+	err = tx.QueryRow(
+		ctx,
+		"SELECT name, (SELECT COUNT(*) FROM starthub_categories WHERE category_id = $1) FROM categories WHERE id = $1",
+		targetID,
+	).Scan(&category.Name, &category.StartHubCount)
+	if err != nil {
+		return category, err
+	}
+
+	return category, tx.Commit(ctx)
+}
+
+// touchCategoryStartHubs bumps the version of the starthubs in the categories
+func touchCategoryStartHubs(ctx context.Context, tx pgx.Tx, categoryIDs []int) error {
+	query := `
+	UPDATE starthubs SET version = version + 1, updated_at = NOW()
+	WHERE id IN (SELECT starthub_id FROM starthub_categories WHERE category_id = ANY($1))
+	`
+	_, err := tx.Exec(ctx, query, categoryIDs)
+	return err
+}
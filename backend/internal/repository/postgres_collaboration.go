@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresCollaborations struct {
+	db *pgxpool.Pool
+}
+
+func (r *postgresCollaborations) Propose(ctx context.Context, proposal CollaborationProposal) (models.Collaboration, error) {
+	collaboration := models.Collaboration{
+		Direction: "outgoing",
+		Status:    models.CollaborationPending,
+		Message:   proposal.Message,
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return collaboration, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Make sure the partner exists and grab its summary for the response
+	err = tx.QueryRow(
+		ctx,
+		"SELECT id, name, COALESCE(image_url, '') FROM starthubs WHERE id = $1",
+		proposal.PartnerID,
+	).Scan(&collaboration.Partner.ID, &collaboration.Partner.Name, &collaboration.Partner.ImageURL)
+	if err != nil {
+		return collaboration, translateError(err)
+	}
+
+	// A declined or ended collaboration can be proposed again, from either side
+	clearQuery := `
+	DELETE FROM starthub_collaborations
+	WHERE ((starthub_id = $1 AND collaborator_id = $2) OR (starthub_id = $2 AND collaborator_id = $1))
+	AND status IN ('declined', 'ended')
+	`
+	if _, err = tx.Exec(ctx, clearQuery, proposal.StartHubID, proposal.PartnerID); err != nil {
+		return collaboration, translateError(err)
+	}
+
+	insertQuery := `
+	INSERT INTO starthub_collaborations (starthub_id, collaborator_id, status, message, requested_by)
+	VALUES ($1, $2, 'pending', $3, $4)
+	RETURNING created_at
+	`
+	err = tx.QueryRow(
+		ctx,
+		insertQuery,
+		proposal.StartHubID,
+		proposal.PartnerID,
+		proposal.Message,
+		proposal.RequestedBy,
+	).Scan(&collaboration.CreatedAt)
+	if err != nil {
+		return collaboration, translateError(err)
+	}
+
+	return collaboration, tx.Commit(ctx)
+}
+
+func (r *postgresCollaborations) List(ctx context.Context, starthubID string, statuses []string) ([]models.Collaboration, error) {
+	query := `
+	SELECT s.id, s.name, COALESCE(s.image_url, ''),
+	       CASE WHEN sc.starthub_id = $1 THEN 'outgoing' ELSE 'incoming' END,
+	       sc.status, COALESCE(sc.message, ''), sc.created_at, sc.responded_at
+	FROM starthub_collaborations sc
+	JOIN starthubs s ON s.id = CASE WHEN sc.starthub_id = $1 THEN sc.collaborator_id ELSE sc.starthub_id END
+	WHERE (sc.starthub_id = $1 OR sc.collaborator_id = $1)
+	AND sc.status = ANY($2)
+	ORDER BY sc.created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, starthubID, statuses)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	collaborations := []models.Collaboration{}
+	for rows.Next() {
+		var collaboration models.Collaboration
+		err := rows.Scan(
+			&collaboration.Partner.ID,
+			&collaboration.Partner.Name,
+			&collaboration.Partner.ImageURL,
+			&collaboration.Direction,
+			&collaboration.Status,
+			&collaboration.Message,
+			&collaboration.CreatedAt,
+			&collaboration.RespondedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		collaborations = append(collaborations, collaboration)
+	}
+
+	return collaborations, rows.Err()
+}
+
+func (r *postgresCollaborations) Respond(ctx context.Context, starthubID, partnerID, status string) error {
+	// Only the starthub that received the request can answer it
+	// Both starthubs get a new version since their list of partners may change
+	query := `
+	WITH responded AS (
+		UPDATE starthub_collaborations
+		SET status = $1, responded_at = NOW()
+		WHERE starthub_id = $2 AND collaborator_id = $3 AND status = 'pending'
+		RETURNING starthub_id, collaborator_id
+	)
+	UPDATE starthubs SET version = version + 1, updated_at = NOW()
+	WHERE id IN (SELECT starthub_id FROM responded UNION SELECT collaborator_id FROM responded)
+	`
+	result, err := r.db.Exec(ctx, query, status, partnerID, starthubID)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postgresCollaborations) End(ctx context.Context, starthubID, partnerID string) error {
+	// Accepted collaborations are kept as history, withdrawn proposals are removed
+	query := `
+	WITH ended AS (
+		UPDATE starthub_collaborations
+		SET status = 'ended', responded_at = NOW()
+		WHERE ((starthub_id = $1 AND collaborator_id = $2) OR (starthub_id = $2 AND collaborator_id = $1))
+		AND status = 'accepted'
+		RETURNING 1
+	), withdrawn AS (
+		DELETE FROM starthub_collaborations
+		WHERE starthub_id = $1 AND collaborator_id = $2 AND status = 'pending'
+		RETURNING 1
+	), touched AS (
+		UPDATE starthubs SET version = version + 1, updated_at = NOW()
+		WHERE id IN ($1, $2) AND EXISTS (SELECT 1 FROM ended)
+	)
+	SELECT (SELECT COUNT(*) FROM ended) + (SELECT COUNT(*) FROM withdrawn)
+	`
+
+	var affected int
+	if err := r.db.QueryRow(ctx, query, starthubID, partnerID).Scan(&affected); err != nil {
+		return translateError(err)
+	}
+
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
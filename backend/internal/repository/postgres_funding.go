@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// pledgeColumns is the column list every pledge query selects, in scan order.
+// The starthub is left joined, pledges outlive it with the name they stored.
+const pledgeColumns = `p.id, COALESCE(s.id::text, ''), COALESCE(s.name, p.starthub_name), COALESCE(s.image_url, ''),
+	p.goal_id, p.amount, p.currency, p.message, p.status, p.provider, COALESCE(p.payment_id, ''), p.created_at`
+
+// goalColumns is the column list every goal query selects, in scan order. The
+// raised amount needs the pledges joined as paid.
+const goalColumns = "g.id, g.starthub_id, g.title, g.amount, g.currency, g.deadline, g.created_at, COALESCE(SUM(paid.amount), 0)"
+
+type postgresFunding struct {
+	db *pgxpool.Pool
+}
+
+func scanPledge(row pgx.Row, p *models.Pledge) error {
+	return row.Scan(
+		&p.ID,
+		&p.StartHub.ID,
+		&p.StartHub.Name,
+		&p.StartHub.ImageURL,
+		&p.GoalID,
+		&p.Amount,
+		&p.Currency,
+		&p.Message,
+		&p.Status,
+		&p.Provider,
+		&p.PaymentID,
+		&p.CreatedAt,
+	)
+}
+
+func scanGoal(row pgx.Row, g *models.FundingGoal) error {
+	return row.Scan(&g.ID, &g.StartHubID, &g.Title, &g.Amount, &g.Currency, &g.Deadline, &g.CreatedAt, &g.Raised)
+}
+
+// getPledge reads one pledge with its starthub
+func getPledge(ctx context.Context, db *pgxpool.Pool, id string) (models.Pledge, error) {
+	var p models.Pledge
+
+	query := "SELECT " + pledgeColumns + " FROM pledges p LEFT JOIN starthubs s ON s.id = p.starthub_id WHERE p.id = $1"
+	err := scanPledge(db.QueryRow(ctx, query, id), &p)
+
+	return p, translateError(err)
+}
+
+func (r *postgresFunding) CreatePledge(ctx context.Context, p models.Pledge) (models.Pledge, error) {
+	// Inserting through the starthub, and the goal when there is one, makes a
+	// missing or mismatched one insert nothing instead of failing a foreign key
+	query := `
+	INSERT INTO pledges (donator_id, starthub_id, starthub_name, goal_id, amount, currency, message, status, provider)
+	SELECT $1, s.id, s.name, $3, $4, $5, $6, $7, $8
+	FROM starthubs s
+	WHERE s.id = $2 AND ($3::int IS NULL OR EXISTS (SELECT 1 FROM funding_goals WHERE id = $3 AND starthub_id = s.id))
+	RETURNING id
+	`
+
+	var id string
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		p.DonatorID,
+		p.StartHub.ID,
+		p.GoalID,
+		p.Amount,
+		p.Currency,
+		p.Message,
+		models.PledgePending,
+		p.Provider,
+	).Scan(&id)
+	if err != nil {
+		return p, translateError(err)
+	}
+
+	return getPledge(ctx, r.db, id)
+}
+
+func (r *postgresFunding) SetPledgeStatus(ctx context.Context, id, status, paymentID string) (models.Pledge, error) {
+	result, err := r.db.Exec(
+		ctx,
+		"UPDATE pledges SET status = $2, payment_id = NULLIF($3, '') WHERE id = $1",
+		id,
+		status,
+		paymentID,
+	)
+	if err != nil {
+		return models.Pledge{}, translateError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return models.Pledge{}, ErrNotFound
+	}
+
+	return getPledge(ctx, r.db, id)
+}
+
+func (r *postgresFunding) ListPledges(ctx context.Context, donatorID string) ([]models.Pledge, error) {
+	query := `
+	SELECT ` + pledgeColumns + `
+	FROM pledges p
+	LEFT JOIN starthubs s ON s.id = p.starthub_id
+	WHERE p.donator_id = $1
+	ORDER BY p.created_at DESC, p.id
+	`
+
+	rows, err := r.db.Query(ctx, query, donatorID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	pledges := []models.Pledge{}
+	for rows.Next() {
+		var p models.Pledge
+		if err := scanPledge(rows, &p); err != nil {
+			return nil, err
+		}
+		pledges = append(pledges, p)
+	}
+
+	return pledges, rows.Err()
+}
+
+func (r *postgresFunding) CreateGoal(ctx context.Context, goal models.FundingGoal) (models.FundingGoal, error) {
+	query := `
+	INSERT INTO funding_goals (starthub_id, title, amount, currency, deadline)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		goal.StartHubID,
+		goal.Title,
+		goal.Amount,
+		goal.Currency,
+		goal.Deadline,
+	).Scan(&goal.ID, &goal.CreatedAt)
+
+	return goal, translateError(err)
+}
+
+func (r *postgresFunding) GetGoal(ctx context.Context, starthubID string, id int) (models.FundingGoal, error) {
+	var goal models.FundingGoal
+
+	query := `
+	SELECT ` + goalColumns + `
+	FROM funding_goals g
+	LEFT JOIN pledges paid ON paid.goal_id = g.id AND paid.status = 'paid'
+	WHERE g.id = $1 AND g.starthub_id = $2
+	GROUP BY g.id
+	`
+	err := scanGoal(r.db.QueryRow(ctx, query, id, starthubID), &goal)
+
+	return goal, translateError(err)
+}
+
+func (r *postgresFunding) DeleteGoal(ctx context.Context, starthubID string, id int) error {
+	result, err := r.db.Exec(ctx, "DELETE FROM funding_goals WHERE id = $1 AND starthub_id = $2", id, starthubID)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postgresFunding) Progress(ctx context.Context, starthubID string) (models.FundingProgress, error) {
+	progress := models.FundingProgress{
+		StartHubID: starthubID,
+		Totals:     []models.FundingTotal{},
+		Goals:      []models.FundingGoal{},
+	}
+
+	// The donator count doubles as the existence check
+	countQuery := `
+	SELECT COUNT(DISTINCT p.donator_id)
+	FROM starthubs s
+	LEFT JOIN pledges p ON p.starthub_id = s.id AND p.status = 'paid'
+	WHERE s.id = $1
+	GROUP BY s.id
+	`
+	if err := r.db.QueryRow(ctx, countQuery, starthubID).Scan(&progress.Donators); err != nil {
+		return progress, translateError(err)
+	}
+
+	totalsQuery := `
+	SELECT currency, SUM(amount), COUNT(*)
+	FROM pledges
+	WHERE starthub_id = $1 AND status = 'paid'
+	GROUP BY currency
+	ORDER BY currency
+	`
+	rows, err := r.db.Query(ctx, totalsQuery, starthubID)
+	if err != nil {
+		return progress, translateError(err)
+	}
+	for rows.Next() {
+		var total models.FundingTotal
+		if err := rows.Scan(&total.Currency, &total.Amount, &total.Pledges); err != nil {
+			rows.Close()
+			return progress, err
+		}
+		progress.Totals = append(progress.Totals, total)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return progress, err
+	}
+
+	// Goals with a deadline come first, soonest first
+	goalsQuery := `
+	SELECT ` + goalColumns + `
+	FROM funding_goals g
+	LEFT JOIN pledges paid ON paid.goal_id = g.id AND paid.status = 'paid'
+	WHERE g.starthub_id = $1
+	GROUP BY g.id
+	ORDER BY g.deadline NULLS LAST, g.created_at, g.id
+	`
+	rows, err = r.db.Query(ctx, goalsQuery, starthubID)
+	if err != nil {
+		return progress, translateError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var goal models.FundingGoal
+		if err := scanGoal(rows, &goal); err != nil {
+			return progress, err
+		}
+		progress.Goals = append(progress.Goals, goal)
+	}
+
+	return progress, rows.Err()
+}
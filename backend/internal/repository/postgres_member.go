@@ -0,0 +1,355 @@
+package repository
+
+import (
+	"context"
+	"slices"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// memberColumns is the column list every member query selects, in scan order
+const memberColumns = "m.user_id, u.email, m.role, m.created_at"
+
+// invitationColumns is the column list every invitation query selects, in scan order
+const invitationColumns = "i.id, i.starthub_id, i.email, i.role, COALESCE(i.invited_by::text, ''), i.created_at, i.expires_at, i.accepted_at"
+
+type postgresMembers struct {
+	db *pgxpool.Pool
+}
+
+func scanMember(row pgx.Row, m *models.Member) error {
+	return row.Scan(&m.UserID, &m.Email, &m.Role, &m.JoinedAt)
+}
+
+func scanInvitation(row pgx.Row, i *models.Invitation) error {
+	return row.Scan(&i.ID, &i.StartHubID, &i.Email, &i.Role, &i.InvitedBy, &i.CreatedAt, &i.ExpiresAt, &i.AcceptedAt)
+}
+
+// getMember reads one member of a team
+func getMember(ctx context.Context, tx pgx.Tx, starthubID, userID string) (models.Member, error) {
+	var member models.Member
+
+	query := "SELECT " + memberColumns + " FROM starthub_members m JOIN users u ON u.id = m.user_id WHERE m.starthub_id = $1 AND m.user_id = $2"
+	err := scanMember(tx.QueryRow(ctx, query, starthubID, userID), &member)
+
+	return member, translateError(err)
+}
+
+// lockOwners returns the owners of a starthub and keeps their rows locked until
+// the transaction ends, so two owners can't demote each other at the same time
+func lockOwners(ctx context.Context, tx pgx.Tx, starthubID string) ([]string, error) {
+	rows, err := tx.Query(
+		ctx,
+		"SELECT user_id::text FROM starthub_members WHERE starthub_id = $1 AND role = $2 FOR UPDATE",
+		starthubID,
+		models.MemberOwner,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func (r *postgresMembers) List(ctx context.Context, starthubID string) ([]models.Member, error) {
+	query := `
+	SELECT ` + memberColumns + `
+	FROM starthub_members m
+	JOIN users u ON u.id = m.user_id
+	WHERE m.starthub_id = $1
+	ORDER BY array_position($2::text[], m.role), m.created_at, u.email
+	`
+
+	rows, err := r.db.Query(ctx, query, starthubID, models.MemberRoles)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	members := []models.Member{}
+	for rows.Next() {
+		var member models.Member
+		if err := scanMember(rows, &member); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+func (r *postgresMembers) SetRole(ctx context.Context, starthubID, userID, role string) (models.Member, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return models.Member{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	owners, err := lockOwners(ctx, tx, starthubID)
+	if err != nil {
+		return models.Member{}, err
+	}
+	if role != models.MemberOwner && slices.Equal(owners, []string{userID}) {
+		return models.Member{}, ErrLastOwner
+	}
+
+	result, err := tx.Exec(
+		ctx,
+		"UPDATE starthub_members SET role = $3 WHERE starthub_id = $1 AND user_id = $2",
+		starthubID,
+		userID,
+		role,
+	)
+	if err != nil {
+		return models.Member{}, translateError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return models.Member{}, ErrNotFound
+	}
+
+	member, err := getMember(ctx, tx, starthubID, userID)
+	if err != nil {
+		return member, err
+	}
+
+	return member, tx.Commit(ctx)
+}
+
+func (r *postgresMembers) Remove(ctx context.Context, starthubID, userID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	owners, err := lockOwners(ctx, tx, starthubID)
+	if err != nil {
+		return err
+	}
+	if slices.Equal(owners, []string{userID}) {
+		return ErrLastOwner
+	}
+
+	result, err := tx.Exec(ctx, "DELETE FROM starthub_members WHERE starthub_id = $1 AND user_id = $2", starthubID, userID)
+	if err != nil {
+		return translateError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *postgresMembers) TransferOwnership(ctx context.Context, starthubID, fromUserID, toUserID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = lockOwners(ctx, tx, starthubID); err != nil {
+		return err
+	}
+
+	// Promote first, so the starthub has an owner at every step
+	result, err := tx.Exec(
+		ctx,
+		"UPDATE starthub_members SET role = $3 WHERE starthub_id = $1 AND user_id = $2",
+		starthubID,
+		toUserID,
+		models.MemberOwner,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		"UPDATE starthub_members SET role = $3 WHERE starthub_id = $1 AND user_id = $2",
+		starthubID,
+		fromUserID,
+		models.MemberEditor,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *postgresMembers) Invite(ctx context.Context, invitation models.Invitation, tokenHash string) (models.Invitation, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return invitation, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Someone already on the team has nothing to accept
+	var member bool
+	memberQuery := `
+	SELECT EXISTS (
+		SELECT 1 FROM starthub_members m JOIN users u ON u.id = m.user_id
+		WHERE m.starthub_id = $1 AND LOWER(u.email) = LOWER($2)
+	)`
+	if err = tx.QueryRow(ctx, memberQuery, invitation.StartHubID, invitation.Email).Scan(&member); err != nil {
+		return invitation, translateError(err)
+	}
+	if member {
+		return invitation, &ConflictError{Constraint: "starthub_members_pkey"}
+	}
+
+	// An expired invitation doesn't block a new one
+	expiredQuery := `
+	DELETE FROM starthub_invitations
+	WHERE starthub_id = $1 AND LOWER(email) = LOWER($2) AND accepted_at IS NULL AND expires_at <= NOW()
+	`
+	if _, err = tx.Exec(ctx, expiredQuery, invitation.StartHubID, invitation.Email); err != nil {
+		return invitation, translateError(err)
+	}
+
+	insertQuery := `
+	INSERT INTO starthub_invitations (starthub_id, email, role, token_hash, invited_by, expires_at)
+	VALUES ($1, $2, $3, $4, NULLIF($5, '')::uuid, $6)
+	RETURNING id, created_at
+	`
+	err = tx.QueryRow(
+		ctx,
+		insertQuery,
+		invitation.StartHubID,
+		invitation.Email,
+		invitation.Role,
+		tokenHash,
+		invitation.InvitedBy,
+		invitation.ExpiresAt,
+	).Scan(&invitation.ID, &invitation.CreatedAt)
+	if err != nil {
+		return invitation, translateError(err)
+	}
+
+	return invitation, tx.Commit(ctx)
+}
+
+func (r *postgresMembers) ListInvitations(ctx context.Context, starthubID string) ([]models.Invitation, error) {
+	query := `
+	SELECT ` + invitationColumns + `
+	FROM starthub_invitations i
+	WHERE i.starthub_id = $1 AND i.accepted_at IS NULL
+	ORDER BY i.created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, starthubID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	invitations := []models.Invitation{}
+	for rows.Next() {
+		var invitation models.Invitation
+		if err := scanInvitation(rows, &invitation); err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, rows.Err()
+}
+
+func (r *postgresMembers) RevokeInvitation(ctx context.Context, starthubID, id string) error {
+	result, err := r.db.Exec(
+		ctx,
+		"DELETE FROM starthub_invitations WHERE id = $1 AND starthub_id = $2 AND accepted_at IS NULL",
+		id,
+		starthubID,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postgresMembers) InvitationByToken(ctx context.Context, tokenHash string) (models.Invitation, error) {
+	var invitation models.Invitation
+
+	query := "SELECT " + invitationColumns + " FROM starthub_invitations i WHERE i.token_hash = $1 AND i.accepted_at IS NULL"
+	err := scanInvitation(r.db.QueryRow(ctx, query, tokenHash), &invitation)
+
+	return invitation, translateError(err)
+}
+
+func (r *postgresMembers) AcceptInvitation(ctx context.Context, id, userID string) (models.Member, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return models.Member{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Claim the invitation, it can only be used once
+	var starthubID, role string
+	err = tx.QueryRow(
+		ctx,
+		`UPDATE starthub_invitations SET accepted_at = NOW()
+		WHERE id = $1 AND accepted_at IS NULL AND expires_at > NOW()
+		RETURNING starthub_id::text, role`,
+		id,
+	).Scan(&starthubID, &role)
+	if err != nil {
+		return models.Member{}, translateError(err)
+	}
+
+	// Someone already on the team keeps their role, the rollback keeps the invitation open
+	_, err = tx.Exec(
+		ctx,
+		"INSERT INTO starthub_members (starthub_id, user_id, role) VALUES ($1, $2, $3)",
+		starthubID,
+		userID,
+		role,
+	)
+	if err != nil {
+		return models.Member{}, translateError(err)
+	}
+
+	member, err := getMember(ctx, tx, starthubID, userID)
+	if err != nil {
+		return member, err
+	}
+
+	return member, tx.Commit(ctx)
+}
+
+func (r *postgresMembers) StartHubsOf(ctx context.Context, userID string, roles []string) ([]models.TeamStartHub, error) {
+	query := `
+	SELECT s.id, s.name, COALESCE(s.image_url, ''), m.role, m.created_at
+	FROM starthub_members m
+	JOIN starthubs s ON s.id = m.starthub_id
+	WHERE m.user_id = $1 AND (COALESCE(cardinality($2::text[]), 0) = 0 OR m.role = ANY($2::text[]))
+	ORDER BY s.name, s.id
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, roles)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	starthubs := []models.TeamStartHub{}
+	for rows.Next() {
+		var t models.TeamStartHub
+		if err := rows.Scan(&t.StartHub.ID, &t.StartHub.Name, &t.StartHub.ImageURL, &t.Role, &t.JoinedAt); err != nil {
+			return nil, err
+		}
+		starthubs = append(starthubs, t)
+	}
+
+	return starthubs, rows.Err()
+}
@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// openRoleColumns is the column list every open role query selects, in scan order
+const openRoleColumns = `r.id, s.id, s.name, COALESCE(s.image_url, ''), r.title, r.description, r.skills,
+	r.remote, r.location, r.commitment, r.status, r.created_at`
+
+// applicationColumns is the column list every application query selects, in
+// scan order. It needs applicationTables.
+const applicationColumns = "a.id, " + openRoleColumns + `, u.email, a.name, a.url, a.message, a.status,
+	a.external_collaborator_id, a.created_at, a.decided_at, a.applicant_id`
+
+const applicationTables = `
+	role_applications a
+	JOIN open_roles r ON r.id = a.role_id
+	JOIN starthubs s ON s.id = r.starthub_id
+	JOIN users u ON u.id = a.applicant_id
+`
+
+type postgresOpenRoles struct {
+	db *pgxpool.Pool
+}
+
+func openRoleFields(r *models.OpenRole) []any {
+	return []any{
+		&r.ID,
+		&r.StartHub.ID,
+		&r.StartHub.Name,
+		&r.StartHub.ImageURL,
+		&r.Title,
+		&r.Description,
+		&r.Skills,
+		&r.Remote,
+		&r.Location,
+		&r.Commitment,
+		&r.Status,
+		&r.CreatedAt,
+	}
+}
+
+func scanOpenRole(row pgx.Row, r *models.OpenRole) error {
+	return row.Scan(openRoleFields(r)...)
+}
+
+func scanApplication(row pgx.Row, a *models.Application) error {
+	fields := []any{&a.ID}
+	fields = append(fields, openRoleFields(&a.Role)...)
+	fields = append(fields,
+		&a.ApplicantEmail,
+		&a.Name,
+		&a.URL,
+		&a.Message,
+		&a.Status,
+		&a.ExternalCollaboratorID,
+		&a.CreatedAt,
+		&a.DecidedAt,
+		&a.ApplicantID,
+	)
+	return row.Scan(fields...)
+}
+
+// getApplication reads one application with its role and starthub
+func getApplication(ctx context.Context, db *pgxpool.Pool, id string) (models.Application, error) {
+	var a models.Application
+
+	query := "SELECT " + applicationColumns + " FROM " + applicationTables + " WHERE a.id = $1"
+	err := scanApplication(db.QueryRow(ctx, query, id), &a)
+
+	return a, translateError(err)
+}
+
+// listApplications reads the applications matching a condition on a, newest first
+func (r *postgresOpenRoles) listApplications(ctx context.Context, condition string, args ...any) ([]models.Application, error) {
+	query := "SELECT " + applicationColumns + " FROM " + applicationTables +
+		" WHERE " + condition + " ORDER BY a.created_at DESC, a.id"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	applications := []models.Application{}
+	for rows.Next() {
+		var a models.Application
+		if err := scanApplication(rows, &a); err != nil {
+			return nil, err
+		}
+		applications = append(applications, a)
+	}
+
+	return applications, rows.Err()
+}
+
+func (r *postgresOpenRoles) CreateRole(ctx context.Context, role models.OpenRole) (models.OpenRole, error) {
+	query := `
+	INSERT INTO open_roles (starthub_id, title, description, skills, remote, location, commitment)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id
+	`
+
+	var id string
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		role.StartHub.ID,
+		role.Title,
+		role.Description,
+		role.Skills,
+		role.Remote,
+		role.Location,
+		role.Commitment,
+	).Scan(&id)
+	if err != nil {
+		return role, translateError(err)
+	}
+
+	return r.GetRole(ctx, id)
+}
+
+func (r *postgresOpenRoles) GetRole(ctx context.Context, id string) (models.OpenRole, error) {
+	var role models.OpenRole
+
+	query := "SELECT " + openRoleColumns + " FROM open_roles r JOIN starthubs s ON s.id = r.starthub_id WHERE r.id = $1"
+	err := scanOpenRole(r.db.QueryRow(ctx, query, id), &role)
+
+	return role, translateError(err)
+}
+
+func (r *postgresOpenRoles) ListRoles(ctx context.Context, filter models.OpenRoleFilter) ([]models.OpenRole, error) {
+	conditions := []string{"r.status = 'open'"}
+	var args []any
+
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.StartHubID != "" {
+		add("r.starthub_id = $%d", filter.StartHubID)
+	}
+	if filter.Skill != "" {
+		add("$%d = ANY(r.skills)", filter.Skill)
+	}
+	if filter.Remote != nil {
+		add("r.remote = $%d", *filter.Remote)
+	}
+	if filter.Commitment != "" {
+		add("r.commitment = $%d", filter.Commitment)
+	}
+
+	query := "SELECT " + openRoleColumns + " FROM open_roles r JOIN starthubs s ON s.id = r.starthub_id WHERE " +
+		strings.Join(conditions, " AND ") + " ORDER BY r.created_at DESC, r.id"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	roles := []models.OpenRole{}
+	for rows.Next() {
+		var role models.OpenRole
+		if err := scanOpenRole(rows, &role); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+func (r *postgresOpenRoles) CloseRole(ctx context.Context, starthubID, id string) error {
+	result, err := r.db.Exec(ctx, "UPDATE open_roles SET status = 'closed' WHERE id = $1 AND starthub_id = $2", id, starthubID)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postgresOpenRoles) Apply(ctx context.Context, application models.Application) (models.Application, error) {
+	// Inserting through the role makes a closed or missing one insert nothing
+	query := `
+	INSERT INTO role_applications (role_id, applicant_id, name, url, message)
+	SELECT r.id, $2, $3, $4, $5
+	FROM open_roles r
+	WHERE r.id = $1 AND r.status = 'open'
+	RETURNING id
+	`
+
+	var id string
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		application.Role.ID,
+		application.ApplicantID,
+		application.Name,
+		application.URL,
+		application.Message,
+	).Scan(&id)
+	if err != nil {
+		return application, translateError(err)
+	}
+
+	return getApplication(ctx, r.db, id)
+}
+
+func (r *postgresOpenRoles) ListApplications(ctx context.Context, starthubID string, statuses []string) ([]models.Application, error) {
+	return r.listApplications(
+		ctx,
+		"r.starthub_id = $1 AND (COALESCE(cardinality($2::text[]), 0) = 0 OR a.status = ANY($2::text[]))",
+		starthubID,
+		statuses,
+	)
+}
+
+func (r *postgresOpenRoles) ListMyApplications(ctx context.Context, applicantID string) ([]models.Application, error) {
+	return r.listApplications(ctx, "a.applicant_id = $1", applicantID)
+}
+
+func (r *postgresOpenRoles) Decide(ctx context.Context, starthubID, id, status string) (models.Application, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return models.Application{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Step 1: Only pending applications to a role of this starthub can be decided
+	query := `
+	UPDATE role_applications a
+	SET status = $3, decided_at = NOW()
+	FROM open_roles r
+	WHERE a.id = $1 AND r.id = a.role_id AND r.starthub_id = $2 AND a.status = 'pending'
+	RETURNING a.name, a.url, r.title
+	`
+
+	var collaborator models.ExternalCollaborator
+	err = tx.QueryRow(ctx, query, id, starthubID, status).Scan(&collaborator.Name, &collaborator.URL, &collaborator.Role)
+	if err != nil {
+		return models.Application{}, translateError(err)
+	}
+
+	// Step 2: Accepted applicants show up as external collaborators
+	if status == models.ApplicationAccepted {
+		collaborator, err = insertExternalCollaborator(ctx, tx, starthubID, collaborator)
+		if err != nil {
+			return models.Application{}, err
+		}
+
+		_, err = tx.Exec(ctx, "UPDATE role_applications SET external_collaborator_id = $2 WHERE id = $1", id, collaborator.ID)
+		if err != nil {
+			return models.Application{}, translateError(err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return models.Application{}, err
+	}
+
+	return getApplication(ctx, r.db, id)
+}
+
+func (r *postgresOpenRoles) Withdraw(ctx context.Context, applicantID, id string) error {
+	result, err := r.db.Exec(
+		ctx,
+		"UPDATE role_applications SET status = 'withdrawn', decided_at = NOW() WHERE id = $1 AND applicant_id = $2 AND status = 'pending'",
+		id,
+		applicantID,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
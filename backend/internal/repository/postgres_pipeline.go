@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresPipeline struct {
+	db *pgxpool.Pool
+}
+
+func (r *postgresPipeline) List(ctx context.Context, investorID string, stages []string) ([]models.PipelineEntry, error) {
+	query := `
+	SELECT s.id, s.name, COALESCE(s.image_url, ''), i.stage, i.notes, i.created_at, i.updated_at
+	FROM investor_interests i
+	JOIN starthubs s ON s.id = i.starthub_id
+	WHERE i.investor_id = $1 AND (COALESCE(cardinality($2::text[]), 0) = 0 OR i.stage = ANY($2::text[]))
+	ORDER BY i.updated_at DESC, s.id
+	`
+
+	rows, err := r.db.Query(ctx, query, investorID, stages)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	entries := []models.PipelineEntry{}
+	for rows.Next() {
+		var e models.PipelineEntry
+		err := rows.Scan(&e.StartHub.ID, &e.StartHub.Name, &e.StartHub.ImageURL, &e.Stage, &e.Notes, &e.CreatedAt, &e.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *postgresPipeline) Save(ctx context.Context, investorID, starthubID string, stage, notes *string) (models.PipelineEntry, bool, error) {
+	var entry models.PipelineEntry
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return entry, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	// A missing starthub is a 404 rather than a foreign key error
+	err = tx.QueryRow(
+		ctx,
+		"SELECT id, name, COALESCE(image_url, '') FROM starthubs WHERE id = $1",
+		starthubID,
+	).Scan(&entry.StartHub.ID, &entry.StartHub.Name, &entry.StartHub.ImageURL)
+	if err != nil {
+		return entry, false, translateError(err)
+	}
+
+	// A null stage or null notes keep the stored ones, the default stage only
+	// applies to a new row. xmax is 0 for a row this statement inserted.
+	query := `
+	INSERT INTO investor_interests (investor_id, starthub_id, stage, notes)
+	VALUES ($1, $2, COALESCE($3::text, $5), COALESCE($4::text, ''))
+	ON CONFLICT (investor_id, starthub_id)
+	DO UPDATE SET stage = COALESCE($3, investor_interests.stage), notes = COALESCE($4, investor_interests.notes), updated_at = NOW()
+	RETURNING stage, notes, created_at, updated_at, xmax = 0
+	`
+	var created bool
+	err = tx.QueryRow(ctx, query, investorID, starthubID, stage, notes, models.StageWatching).
+		Scan(&entry.Stage, &entry.Notes, &entry.CreatedAt, &entry.UpdatedAt, &created)
+	if err != nil {
+		return entry, false, translateError(err)
+	}
+
+	return entry, created, tx.Commit(ctx)
+}
+
+func (r *postgresPipeline) Delete(ctx context.Context, investorID, starthubID string) error {
+	result, err := r.db.Exec(
+		ctx,
+		"DELETE FROM investor_interests WHERE investor_id = $1 AND starthub_id = $2",
+		investorID,
+		starthubID,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postgresPipeline) InterestCount(ctx context.Context, starthubID string) (int, error) {
+	var count int
+
+	query := "SELECT COUNT(*) FROM investor_interests WHERE starthub_id = $1 AND stage <> $2"
+	err := r.db.QueryRow(ctx, query, starthubID, models.StagePassed).Scan(&count)
+
+	return count, translateError(err)
+}
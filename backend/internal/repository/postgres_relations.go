@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// loadStartHubRelations fills categories, collaborating starthubs and external
+// collaborators for every starthub in the slice. It always runs one query per
+// relation no matter how many starthubs are passed in, so list endpoints don't
+// turn into N+1 lookups.
+func loadStartHubRelations(ctx context.Context, db *pgxpool.Pool, starthubs []models.StartHub) error {
+	if len(starthubs) == 0 {
+		return nil
+	}
+
+	// Map each id to its position so rows can be attached in place
+	ids := make([]string, len(starthubs))
+	index := make(map[string]int, len(starthubs))
+	for i, s := range starthubs {
+		ids[i] = s.ID
+		index[s.ID] = i
+	}
+
+	// Categories
+	categoryQuery := `
+	SELECT sc.starthub_id, c.name
+	FROM starthub_categories sc
+	JOIN categories c ON c.id = sc.category_id
+	WHERE sc.starthub_id = ANY($1)
+	ORDER BY c.name
+	`
+
+	rows, err := db.Query(ctx, categoryQuery, ids)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var starthubID, name string
+		if err := rows.Scan(&starthubID, &name); err != nil {
+			rows.Close()
+			return err
+		}
+		i := index[starthubID]
+		starthubs[i].Categories = append(starthubs[i].Categories, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Collaborations are stored once per pair, so look them up from both sides.
+	// Only accepted ones belong on a profile.
+	collaborationQuery := `
+	SELECT sc.starthub_id, s.id, s.name, COALESCE(s.image_url, '')
+	FROM starthub_collaborations sc
+	JOIN starthubs s ON s.id = sc.collaborator_id
+	WHERE sc.starthub_id = ANY($1) AND sc.status = 'accepted'
+	UNION
+	SELECT sc.collaborator_id, s.id, s.name, COALESCE(s.image_url, '')
+	FROM starthub_collaborations sc
+	JOIN starthubs s ON s.id = sc.starthub_id
+	WHERE sc.collaborator_id = ANY($1) AND sc.status = 'accepted'
+	ORDER BY 3
+	`
+
+	rows, err = db.Query(ctx, collaborationQuery, ids)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var starthubID string
+		var partner models.StartHubSummary
+		if err := rows.Scan(&starthubID, &partner.ID, &partner.Name, &partner.ImageURL); err != nil {
+			rows.Close()
+			return err
+		}
+		i := index[starthubID]
+		starthubs[i].CollaboratingStarthubs = append(starthubs[i].CollaboratingStarthubs, partner)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// External collaborators
+	externalQuery := `
+	SELECT starthub_id, id, name, COALESCE(url, ''), COALESCE(role, ''), COALESCE(logo_url, ''), position
+	FROM external_collaborators
+	WHERE starthub_id = ANY($1)
+	ORDER BY position, id
+	`
+
+	rows, err = db.Query(ctx, externalQuery, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var starthubID string
+		var collaborator models.ExternalCollaborator
+		err := rows.Scan(
+			&starthubID,
+			&collaborator.ID,
+			&collaborator.Name,
+			&collaborator.URL,
+			&collaborator.Role,
+			&collaborator.LogoURL,
+			&collaborator.Position,
+		)
+		if err != nil {
+			return err
+		}
+		i := index[starthubID]
+		starthubs[i].ExternalCollaborators = append(starthubs[i].ExternalCollaborators, collaborator)
+	}
+
+	return rows.Err()
+}
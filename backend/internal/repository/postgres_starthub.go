@@ -0,0 +1,625 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// starthubColumns is the column list every starthub query selects, in scan order
+const starthubColumns = "s.id, s.name, s.description, s.location, s.team_size, s.url, s.email, s.join_date, s.image_url, s.version, s.updated_at"
+
+type postgresStartHubs struct {
+	db *pgxpool.Pool
+}
+
+// scanStartHub reads the columns of starthubColumns followed by extra
+func scanStartHub(row pgx.Row, s *models.StartHub, extra ...any) error {
+	dest := append([]any{
+		&s.ID,
+		&s.Name,
+		&s.Description,
+		&s.Location,
+		&s.TeamSize,
+		&s.URL,
+		&s.Email,
+		&s.JoinDate,
+		&s.ImageURL,
+		&s.Version,
+		&s.UpdatedAt,
+	}, extra...)
+
+	return row.Scan(dest...)
+}
+
+// conditions returns the WHERE clauses for the filter, with their values added to args
+func (f StartHubFilter) conditions(args *queryArgs) []string {
+	var where []string
+
+	if len(f.Categories) > 0 {
+		// A starthub matches if it has any of the requested categories
+		where = append(where, `s.id IN (
+			SELECT sc.starthub_id FROM starthub_categories sc
+			JOIN categories c ON c.id = sc.category_id
+			WHERE LOWER(c.name) = ANY(`+args.add(f.Categories)+`))`)
+	}
+	if f.Location != "" {
+		where = append(where, "s.location ILIKE "+args.add("%"+f.Location+"%"))
+	}
+	if f.MinTeamSize != nil {
+		where = append(where, "s.team_size >= "+args.add(*f.MinTeamSize))
+	}
+	if f.MaxTeamSize != nil {
+		where = append(where, "s.team_size <= "+args.add(*f.MaxTeamSize))
+	}
+
+	return where
+}
+
+func (o starthubSort) order() string {
+	if o.desc {
+		return o.column + " DESC, s.id DESC"
+	}
+	return o.column + " ASC, s.id ASC"
+}
+
+// after returns the keyset condition for rows that come after the cursor
+func (o starthubSort) after(cur Cursor, args *queryArgs) (string, error) {
+	value, err := o.decode(cur.Value)
+	if err != nil {
+		return "", err
+	}
+
+	op := ">"
+	if o.desc {
+		op = "<"
+	}
+
+	return fmt.Sprintf("(%s, s.id) %s (%s, %s::uuid)", o.column, op, args.add(value), args.add(cur.ID)), nil
+}
+
+func (r *postgresStartHubs) List(ctx context.Context, opts ListOptions) ([]models.StartHub, int, error) {
+	sort, ok := starthubSorts[opts.Sort]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown sort %q", opts.Sort)
+	}
+
+	// Count every match, ignoring the cursor
+	var args queryArgs
+	where := opts.Filter.conditions(&args)
+
+	countQuery := "SELECT COUNT(*) FROM starthubs s"
+	if len(where) > 0 {
+		countQuery += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// Continue after the cursor if there is one
+	if opts.After != nil {
+		condition, err := sort.after(*opts.After, &args)
+		if err != nil {
+			return nil, 0, ErrInvalidCursor
+		}
+		where = append(where, condition)
+	}
+
+	query := "SELECT " + starthubColumns + " FROM starthubs s"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY " + sort.order() + " LIMIT " + args.add(opts.Limit)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		// The cursor id is cast to uuid, a malformed one fails here
+		if opts.After != nil && errors.Is(translateError(err), ErrNotFound) {
+			return nil, 0, ErrInvalidCursor
+		}
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	starthubs := []models.StartHub{}
+	for rows.Next() {
+		var s models.StartHub
+		if err := scanStartHub(rows, &s); err != nil {
+			return nil, 0, err
+		}
+		starthubs = append(starthubs, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// Attach categories and collaborators to every starthub
+	if err := loadStartHubRelations(ctx, r.db, starthubs); err != nil {
+		return nil, 0, err
+	}
+
+	return starthubs, total, nil
+}
+
+// prefixQuery turns search terms into a to_tsquery expression where every term
+// has to match, as a prefix so results show up while the user is still typing
+func prefixQuery(terms []string) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		parts[i] = term + ":*"
+	}
+
+	return strings.Join(parts, " & ")
+}
+
+// ts_headline copies the text as is, so matches are delimited with control
+// characters and only turned into <mark> tags once the text is HTML-escaped
+var headlineMarks = strings.NewReplacer("\x01", "<mark>", "\x02", "</mark>")
+
+func markHighlight(headline string) string {
+	return headlineMarks.Replace(html.EscapeString(headline))
+}
+
+func (r *postgresStartHubs) Search(ctx context.Context, opts SearchOptions) ([]models.StartHubSearchResult, int, error) {
+	// The tsquery is always $1 so the select list and WHERE clause can share it
+	args := queryArgs{prefixQuery(opts.Terms)}
+	where := append([]string{"s.search_vector @@ to_tsquery('english', $1)"}, opts.Filter.conditions(&args)...)
+	whereClause := " WHERE " + strings.Join(where, " AND ")
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM starthubs s" + whereClause
+	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+	SELECT ` + starthubColumns + `,
+	       ts_rank_cd(s.search_vector, to_tsquery('english', $1)) AS rank,
+	       ts_headline('english', s.name, to_tsquery('english', $1),
+	                   'StartSel=' || chr(1) || ', StopSel=' || chr(2) || ', HighlightAll=true'),
+	       ts_headline('english', COALESCE(s.description, ''), to_tsquery('english', $1),
+	                   'StartSel=' || chr(1) || ', StopSel=' || chr(2) || ', MaxWords=35, MinWords=15, MaxFragments=2')
+	FROM starthubs s` + whereClause + `
+	ORDER BY rank DESC, s.join_date DESC, s.id
+	LIMIT ` + args.add(opts.Limit) + ` OFFSET ` + args.add(opts.Offset)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, translateError(err)
+	}
+	defer rows.Close()
+
+	results := []models.StartHubSearchResult{}
+	for rows.Next() {
+		var result models.StartHubSearchResult
+		err := scanStartHub(rows, &result.StartHub, &result.Rank, &result.Highlight.Name, &result.Highlight.Description)
+		if err != nil {
+			return nil, 0, err
+		}
+		result.Highlight.Name = markHighlight(result.Highlight.Name)
+		result.Highlight.Description = markHighlight(result.Highlight.Description)
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// Load relations through the shared loader, then copy them back onto the results
+	starthubs := make([]models.StartHub, len(results))
+	for i, result := range results {
+		starthubs[i] = result.StartHub
+	}
+	if err := loadStartHubRelations(ctx, r.db, starthubs); err != nil {
+		return nil, 0, err
+	}
+	for i := range results {
+		results[i].StartHub = starthubs[i]
+	}
+
+	return results, total, nil
+}
+
+func (r *postgresStartHubs) Get(ctx context.Context, id string) (models.StartHub, error) {
+	var s models.StartHub
+
+	query := "SELECT " + starthubColumns + " FROM starthubs s WHERE s.id = $1"
+	if err := scanStartHub(r.db.QueryRow(ctx, query, id), &s); err != nil {
+		return s, translateError(err)
+	}
+
+	starthubs := []models.StartHub{s}
+	if err := loadStartHubRelations(ctx, r.db, starthubs); err != nil {
+		return s, err
+	}
+
+	return starthubs[0], nil
+}
+
+func (r *postgresStartHubs) Summary(ctx context.Context, id string) (models.StartHubSummary, error) {
+	var summary models.StartHubSummary
+
+	err := r.db.QueryRow(
+		ctx,
+		"SELECT id, name, COALESCE(image_url, '') FROM starthubs WHERE id = $1",
+		id,
+	).Scan(&summary.ID, &summary.Name, &summary.ImageURL)
+
+	return summary, translateError(err)
+}
+
+func (r *postgresStartHubs) MemberRole(ctx context.Context, id, userID string) (string, error) {
+	var role string
+
+	query := "SELECT role FROM starthub_members WHERE starthub_id = $1 AND user_id = $2"
+	err := r.db.QueryRow(ctx, query, id, userID).Scan(&role)
+
+	// A malformed id can't belong to anyone
+	if errors.Is(translateError(err), ErrNotFound) {
+		return "", nil
+	}
+	return role, err
+}
+
+func (r *postgresStartHubs) Create(ctx context.Context, s models.StartHub) (models.StartHub, error) {
+	// The starthub and its categories are written together
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return s, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO starthubs (name, description, location, team_size, url, email, image_url, created_by)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING id, join_date, version, updated_at
+	`
+
+	err = tx.QueryRow(
+		ctx,
+		query,
+		s.Name,
+		s.Description,
+		s.Location,
+		s.TeamSize,
+		s.URL,
+		s.Email,
+		s.ImageURL,
+		s.CreatedBy,
+	).Scan(&s.ID, &s.JoinDate, &s.Version, &s.UpdatedAt)
+	if err != nil {
+		return s, translateError(err)
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		"INSERT INTO starthub_members (starthub_id, user_id, role) VALUES ($1, $2, $3)",
+		s.ID,
+		s.CreatedBy,
+		models.MemberOwner,
+	)
+	if err != nil {
+		return s, translateError(err)
+	}
+
+	if len(s.Categories) > 0 {
+		if s.Categories, err = setStartHubCategories(ctx, tx, s.ID, s.Categories); err != nil {
+			return s, err
+		}
+	}
+
+	return s, tx.Commit(ctx)
+}
+
+func (r *postgresStartHubs) Update(ctx context.Context, userID string, s models.StartHub, versions Versions) (models.StartHub, error) {
+	// The row and its categories change together
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return s, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Update only if the user may edit and the version matches, and return the updated row
+	query := `
+	UPDATE starthubs s
+	SET name=$1, description=$2, location=$3, team_size=$4, url=$5, email=$6,
+		version = version + 1, updated_at = NOW()
+	WHERE s.id=$7 AND ` + hasMemberRole("$8", "$10") + ` AND ` + versionMatches("$9") + `
+	RETURNING ` + starthubColumns
+
+	// Scan into a fresh starthub, the relations are loaded from what was stored
+	var updated models.StartHub
+	err = scanStartHub(tx.QueryRow(
+		ctx,
+		query,
+		s.Name,
+		s.Description,
+		s.Location,
+		s.TeamSize,
+		s.URL,
+		s.Email,
+		s.ID,
+		userID,
+		[]int(versions),
+		models.EditorRoles,
+	), &updated)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s, r.whyNotWritten(ctx, s.ID, userID, models.EditorRoles)
+	}
+	if err != nil {
+		return s, translateError(err)
+	}
+
+	// Replace categories when they were given, an empty list clears them
+	if s.Categories != nil {
+		if _, err = setStartHubCategories(ctx, tx, s.ID, s.Categories); err != nil {
+			return s, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return s, err
+	}
+
+	starthubs := []models.StartHub{updated}
+	if err := loadStartHubRelations(ctx, r.db, starthubs); err != nil {
+		return s, err
+	}
+
+	return starthubs[0], nil
+}
+
+func (r *postgresStartHubs) Delete(ctx context.Context, id, userID string, versions Versions) error {
+	owners := []string{models.MemberOwner}
+
+	query := "DELETE FROM starthubs s WHERE s.id=$1 AND " + hasMemberRole("$2", "$4") + " AND " + versionMatches("$3")
+	result, err := r.db.Exec(ctx, query, id, userID, []int(versions), owners)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return r.whyNotWritten(ctx, id, userID, owners)
+	}
+	return nil
+}
+
+// hasMemberRole is the condition that the user in param userParam has one of
+// the roles in the text array parameter rolesParam on the team of starthub s
+func hasMemberRole(userParam, rolesParam string) string {
+	return "EXISTS (SELECT 1 FROM starthub_members m WHERE m.starthub_id = s.id AND m.user_id = " +
+		userParam + " AND m.role = ANY(" + rolesParam + "::text[]))"
+}
+
+// versionMatches is the condition that the starthub s is at one of the versions
+// in the int array parameter param, an empty or NULL array matches any version
+func versionMatches(param string) string {
+	return "(COALESCE(cardinality(" + param + "::int[]), 0) = 0 OR s.version = ANY(" + param + "::int[]))"
+}
+
+// whyNotWritten tells apart the reasons a conditional write touched no row: the
+// starthub is missing or the user lacks the roles, or it is at another version
+func (r *postgresStartHubs) whyNotWritten(ctx context.Context, id, userID string, roles []string) error {
+	var exists bool
+	query := "SELECT EXISTS (SELECT 1 FROM starthubs s WHERE s.id=$1 AND " + hasMemberRole("$2", "$3") + ")"
+	err := r.db.QueryRow(ctx, query, id, userID, roles).Scan(&exists)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if exists {
+		return ErrVersionMismatch
+	}
+	return ErrNotFound
+}
+
+// touchStartHubs bumps the version of starthubs whose related rows changed, like
+// their external collaborators, so their ETag changes with what clients see
+func touchStartHubs(ctx context.Context, db execer, ids ...string) error {
+	_, err := db.Exec(ctx, "UPDATE starthubs SET version = version + 1, updated_at = NOW() WHERE id = ANY($1::uuid[])", ids)
+	return err
+}
+
+func (r *postgresStartHubs) SetImageURL(ctx context.Context, id, userID, imageURL string) (string, error) {
+	// The old row is locked so two uploads can't both see the same previous image
+	query := `
+	UPDATE starthubs s
+	SET image_url = $3, version = s.version + 1, updated_at = NOW()
+	FROM (SELECT id, image_url FROM starthubs WHERE id = $1 FOR UPDATE) old
+	WHERE s.id = old.id AND ` + hasMemberRole("$2", "$4") + `
+	RETURNING COALESCE(old.image_url, '')`
+
+	var previous string
+	if err := r.db.QueryRow(ctx, query, id, userID, imageURL, models.EditorRoles).Scan(&previous); err != nil {
+		return "", translateError(err)
+	}
+
+	return previous, nil
+}
+
+func (r *postgresStartHubs) ListExternalCollaborators(ctx context.Context, starthubID string) ([]models.ExternalCollaborator, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM starthubs WHERE id = $1)", starthubID).Scan(&exists)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	// Reuse the relation loader so the list matches the detail view exactly
+	starthubs := []models.StartHub{{ID: starthubID}}
+	if err := loadStartHubRelations(ctx, r.db, starthubs); err != nil {
+		return nil, err
+	}
+
+	collaborators := starthubs[0].ExternalCollaborators
+	if collaborators == nil {
+		collaborators = []models.ExternalCollaborator{}
+	}
+
+	return collaborators, nil
+}
+
+func (r *postgresStartHubs) AddExternalCollaborator(ctx context.Context, starthubID string, collaborator models.ExternalCollaborator) (models.ExternalCollaborator, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return collaborator, err
+	}
+	defer tx.Rollback(ctx)
+
+	// The only reference is the starthub, so a missing one is a 404
+	collaborator, err = insertExternalCollaborator(ctx, tx, starthubID, collaborator)
+	if errors.Is(err, ErrMissingReference) {
+		return collaborator, ErrNotFound
+	}
+	if err != nil {
+		return collaborator, err
+	}
+
+	return collaborator, tx.Commit(ctx)
+}
+
+// insertExternalCollaborator appends a collaborator to the list of a starthub
+// and bumps its version, inside the caller's transaction
+func insertExternalCollaborator(ctx context.Context, tx pgx.Tx, starthubID string, collaborator models.ExternalCollaborator) (models.ExternalCollaborator, error) {
+	query := `
+	INSERT INTO external_collaborators (starthub_id, name, url, role, logo_url, position)
+	VALUES ($1, $2, $3, $4, $5,
+		(SELECT COALESCE(MAX(position), -1) + 1 FROM external_collaborators WHERE starthub_id = $1))
+	RETURNING id, position
+	`
+
+	err := tx.QueryRow(
+		ctx,
+		query,
+		starthubID,
+		collaborator.Name,
+		collaborator.URL,
+		collaborator.Role,
+		collaborator.LogoURL,
+	).Scan(&collaborator.ID, &collaborator.Position)
+	if err != nil {
+		return collaborator, translateError(err)
+	}
+
+	return collaborator, touchStartHubs(ctx, tx, starthubID)
+}
+
+func (r *postgresStartHubs) ReorderExternalCollaborators(ctx context.Context, starthubID string, ids []int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the current list so the id check and the update see the same rows
+	rows, err := tx.Query(ctx, "SELECT id FROM external_collaborators WHERE starthub_id = $1 FOR UPDATE", starthubID)
+	if err != nil {
+		return translateError(err)
+	}
+	current, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return err
+	}
+
+	if !sameIDs(current, ids) {
+		return ErrInvalidOrder
+	}
+
+	query := `
+	UPDATE external_collaborators e
+	SET position = o.ord - 1
+	FROM unnest($2::int[]) WITH ORDINALITY AS o(id, ord)
+	WHERE e.id = o.id AND e.starthub_id = $1
+	`
+	if _, err = tx.Exec(ctx, query, starthubID, ids); err != nil {
+		return translateError(err)
+	}
+	if err = touchStartHubs(ctx, tx, starthubID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *postgresStartHubs) DeleteExternalCollaborator(ctx context.Context, starthubID string, id int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var deletedID int
+	err = tx.QueryRow(
+		ctx,
+		"DELETE FROM external_collaborators WHERE id = $1 AND starthub_id = $2 RETURNING id",
+		id,
+		starthubID,
+	).Scan(&deletedID)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if err = touchStartHubs(ctx, tx, starthubID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// setStartHubCategories replaces the categories of a starthub with the given names
+// and returns the names as stored. Names are matched against existing categories
+// case-insensitively, unknown ones are created, and only the links that actually
+// changed are touched.
+func setStartHubCategories(ctx context.Context, tx pgx.Tx, starthubID string, names []string) ([]string, error) {
+	names = normalizeCategories(names)
+	categoryIDs := make([]int, 0, len(names))
+
+	for i, name := range names {
+		// Reuse the category if it exists in any letter case, otherwise create it.
+		// The no-op update makes the existing row come back with its stored name.
+		var categoryID int
+		categoryQuery := `
+		INSERT INTO categories (name)
+		VALUES ($1)
+		ON CONFLICT (LOWER(name)) DO UPDATE SET name = categories.name
+		RETURNING id, name
+		`
+		if err := tx.QueryRow(ctx, categoryQuery, name).Scan(&categoryID, &names[i]); err != nil {
+			return nil, translateError(err)
+		}
+
+		categoryIDs = append(categoryIDs, categoryID)
+	}
+
+	// Drop links that are no longer wanted
+	_, err := tx.Exec(
+		ctx,
+		"DELETE FROM starthub_categories WHERE starthub_id = $1 AND NOT (category_id = ANY($2))",
+		starthubID,
+		categoryIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Then link the new ones, existing links are left alone
+	linkQuery := `
+	INSERT INTO starthub_categories (starthub_id, category_id)
+	SELECT $1, unnest($2::int[])
+	ON CONFLICT (starthub_id, category_id) DO NOTHING
+	`
+	if _, err = tx.Exec(ctx, linkQuery, starthubID, categoryIDs); err != nil {
+		return nil, translateError(err)
+	}
+
+	return names, nil
+}
@@ -0,0 +1,644 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// userColumns is the column list every user query selects, in scan order
+const userColumns = `u.id, u.email, u.password, u.role, u.display_name, u.bio, u.avatar_url, u.location,
+	u.links, u.public_profile, u.created_at, u.email_verified_at, u.locked_until,
+	COALESCE(u.totp_secret, ''), u.totp_enabled_at`
+
+type postgresUsers struct {
+	db *pgxpool.Pool
+}
+
+func userFields(u *models.User) []any {
+	return []any{
+		&u.ID,
+		&u.Email,
+		&u.Password,
+		&u.Role,
+		&u.Profile.DisplayName,
+		&u.Profile.Bio,
+		&u.Profile.AvatarURL,
+		&u.Profile.Location,
+		&u.Profile.Links,
+		&u.Settings.PublicProfile,
+		&u.CreatedAt,
+		&u.EmailVerifiedAt,
+		&u.LockedUntil,
+		&u.TOTPSecret,
+		&u.TOTPEnabledAt,
+	}
+}
+
+// getUser reads the one user matching a condition on u
+func getUser(ctx context.Context, db *pgxpool.Pool, condition string, arg any) (models.User, error) {
+	var user models.User
+
+	query := "SELECT " + userColumns + " FROM users u WHERE " + condition
+	err := db.QueryRow(ctx, query, arg).Scan(userFields(&user)...)
+
+	return user, translateError(err)
+}
+
+func (r *postgresUsers) Create(ctx context.Context, user models.User) (models.User, error) {
+	query := `
+	INSERT INTO users (email, password, role, public_profile)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(ctx, query, user.Email, user.Password, user.Role, user.Settings.PublicProfile).Scan(&user.ID, &user.CreatedAt)
+	return user, translateError(err)
+}
+
+func (r *postgresUsers) GetByEmail(ctx context.Context, email string) (models.User, error) {
+	return getUser(ctx, r.db, "u.email = $1", email)
+}
+
+func (r *postgresUsers) GetByID(ctx context.Context, id string) (models.User, error) {
+	return getUser(ctx, r.db, "u.id = $1", id)
+}
+
+func (r *postgresUsers) UpdateProfile(ctx context.Context, id string, profile models.Profile, settings models.AccountSettings) (models.User, error) {
+	query := `
+	UPDATE users
+	SET display_name = $2, bio = $3, avatar_url = $4, location = $5, links = $6, public_profile = $7, updated_at = NOW()
+	WHERE id = $1
+	`
+
+	result, err := r.db.Exec(
+		ctx,
+		query,
+		id,
+		profile.DisplayName,
+		profile.Bio,
+		profile.AvatarURL,
+		profile.Location,
+		profile.Links,
+		settings.PublicProfile,
+	)
+	if err != nil {
+		return models.User{}, translateError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return models.User{}, ErrNotFound
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+func (r *postgresUsers) CreateSession(ctx context.Context, session models.Session) (string, error) {
+	query := `
+	INSERT INTO sessions (user_id, refresh_token_hash, user_agent, ip_address, expires_at)
+	VALUES ($1, $2, $3, $4, NOW() + make_interval(secs => $5))
+	RETURNING id
+	`
+
+	var id string
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		session.UserID,
+		session.RefreshTokenHash,
+		session.UserAgent,
+		session.IPAddress,
+		// Relative to the database clock, like the other session timestamps
+		time.Until(session.ExpiresAt).Seconds(),
+	).Scan(&id)
+
+	return id, translateError(err)
+}
+
+func (r *postgresUsers) SessionActive(ctx context.Context, sessionID, userID string) (bool, error) {
+	query := `
+	SELECT EXISTS (
+		SELECT 1 FROM sessions
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()
+	)
+	`
+
+	var active bool
+	err := r.db.QueryRow(ctx, query, sessionID, userID).Scan(&active)
+	return active, err
+}
+
+func (r *postgresUsers) RotateSession(ctx context.Context, sessionID string, matches func(string) bool, newHash string) (models.User, error) {
+	var user models.User
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return user, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the session so two concurrent refreshes can't both win
+	var (
+		tokenHash string
+		revoked   bool
+		expired   bool
+	)
+	query := `
+	SELECT s.refresh_token_hash, s.revoked_at IS NOT NULL, s.expires_at <= NOW(), ` + userColumns + `
+	FROM sessions s
+	JOIN users u ON u.id = s.user_id
+	WHERE s.id = $1
+	FOR UPDATE OF s
+	`
+
+	err = tx.QueryRow(ctx, query, sessionID).Scan(append([]any{&tokenHash, &revoked, &expired}, userFields(&user)...)...)
+	if err != nil {
+		return user, translateError(err)
+	}
+
+	if revoked || expired {
+		return user, ErrSessionInactive
+	}
+
+	if !matches(tokenHash) {
+		// A secret that never belonged to the session proves nothing, only
+		// one that was rotated away is a sign of a leak
+		retired, err := r.retiredTokenMatches(ctx, tx, sessionID, matches)
+		if err != nil {
+			return user, err
+		}
+		if !retired {
+			return user, ErrNotFound
+		}
+
+		_, err = tx.Exec(
+			ctx,
+			"UPDATE sessions SET revoked_at = NOW(), revoked_reason = 'refresh_token_reuse' WHERE id = $1",
+			sessionID,
+		)
+		if err == nil {
+			err = tx.Commit(ctx)
+		}
+		if err != nil {
+			log.Printf("❌ Could not revoke session %s: %v", sessionID, err)
+		}
+
+		return user, ErrTokenReuse
+	}
+
+	// The presented token stops working as soon as this commits, and is
+	// remembered so presenting it again revokes the session
+	_, err = tx.Exec(
+		ctx,
+		"UPDATE sessions SET refresh_token_hash = $1, last_used_at = NOW() WHERE id = $2",
+		newHash,
+		sessionID,
+	)
+	if err != nil {
+		return user, err
+	}
+	_, err = tx.Exec(
+		ctx,
+		"INSERT INTO session_retired_tokens (session_id, token_hash) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+		sessionID,
+		tokenHash,
+	)
+	if err != nil {
+		return user, err
+	}
+
+	return user, tx.Commit(ctx)
+}
+
+// retiredTokenMatches reports whether matches accepts any refresh token hash
+// the session rotated away
+func (r *postgresUsers) retiredTokenMatches(ctx context.Context, tx pgx.Tx, sessionID string, matches func(string) bool) (bool, error) {
+	rows, err := tx.Query(ctx, "SELECT token_hash FROM session_retired_tokens WHERE session_id = $1", sessionID)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	found := false
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return false, err
+		}
+		// Every hash is compared, so the time taken doesn't tell which one matched
+		if matches(hash) {
+			found = true
+		}
+	}
+
+	return found, rows.Err()
+}
+
+func (r *postgresUsers) RevokeSession(ctx context.Context, sessionID, reason string) error {
+	query := "UPDATE sessions SET revoked_at = NOW(), revoked_reason = $2 WHERE id = $1 AND revoked_at IS NULL"
+	_, err := r.db.Exec(ctx, query, sessionID, reason)
+	return err
+}
+
+func (r *postgresUsers) RevokeUserSessions(ctx context.Context, userID, reason string) (int64, error) {
+	query := "UPDATE sessions SET revoked_at = NOW(), revoked_reason = $2 WHERE user_id = $1 AND revoked_at IS NULL"
+	result, err := r.db.Exec(ctx, query, userID, reason)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+func (r *postgresUsers) ChangePassword(ctx context.Context, userID, passwordHash, keepSessionID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, "UPDATE users SET password = $2, updated_at = NOW() WHERE id = $1", userID, passwordHash)
+	if err != nil {
+		return translateError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	// Every other device has to sign in with the new password
+	_, err = tx.Exec(
+		ctx,
+		"UPDATE sessions SET revoked_at = NOW(), revoked_reason = 'password_change' WHERE user_id = $1 AND id::text <> $2 AND revoked_at IS NULL",
+		userID,
+		keepSessionID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *postgresUsers) CreatePasswordReset(ctx context.Context, userID, tokenHash string, expiresAt time.Time, interval time.Duration) error {
+	return insertThrottledToken(ctx, r.db, "password_resets", userID, tokenHash, expiresAt, interval)
+}
+
+func (r *postgresUsers) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (models.User, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return models.User{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Step 1: Use up the token, the row lock makes two resets with it race for one win
+	var userID string
+	query := `
+	UPDATE password_resets SET used_at = NOW()
+	WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+	RETURNING user_id
+	`
+	if err := tx.QueryRow(ctx, query, tokenHash).Scan(&userID); err != nil {
+		return models.User{}, translateError(err)
+	}
+
+	// Step 2: Other links that were sent stop working too
+	_, err = tx.Exec(ctx, "UPDATE password_resets SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL", userID)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	// Step 3: Set the password, which also lifts a lockout, and sign out
+	// everywhere, whoever knew the old one included
+	query = "UPDATE users SET password = $2, failed_logins = 0, locked_until = NULL, updated_at = NOW() WHERE id = $1"
+	if _, err = tx.Exec(ctx, query, userID, passwordHash); err != nil {
+		return models.User{}, err
+	}
+	_, err = tx.Exec(
+		ctx,
+		"UPDATE sessions SET revoked_at = NOW(), revoked_reason = 'password_reset' WHERE user_id = $1 AND revoked_at IS NULL",
+		userID,
+	)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return models.User{}, err
+	}
+
+	return r.GetByID(ctx, userID)
+}
+
+func (r *postgresUsers) CreateEmailVerification(ctx context.Context, userID, tokenHash string, expiresAt time.Time, interval time.Duration) error {
+	return insertThrottledToken(ctx, r.db, "email_verifications", userID, tokenHash, expiresAt, interval)
+}
+
+// insertThrottledToken stores an emailed token of a user in table, unless the
+// previous one was created less than interval ago
+func insertThrottledToken(ctx context.Context, db *pgxpool.Pool, table, userID, tokenHash string, expiresAt time.Time, interval time.Duration) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Step 1: Lock the user so two requests can't both pass the throttle
+	var locked string
+	if err := tx.QueryRow(ctx, "SELECT id FROM users WHERE id = $1 FOR UPDATE", userID).Scan(&locked); err != nil {
+		return translateError(err)
+	}
+
+	// Step 2: Seconds left until the previous token is old enough
+	var wait float64
+	query := `
+	SELECT COALESCE(EXTRACT(EPOCH FROM MAX(created_at) + make_interval(secs => $2) - NOW()), 0)::float8
+	FROM ` + table + `
+	WHERE user_id = $1
+	`
+	if err := tx.QueryRow(ctx, query, userID, interval.Seconds()).Scan(&wait); err != nil {
+		return err
+	}
+	if wait > 0 {
+		return &ThrottledError{RetryAfter: time.Duration(wait * float64(time.Second))}
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		"INSERT INTO "+table+" (user_id, token_hash, expires_at) VALUES ($1, $2, $3)",
+		userID,
+		tokenHash,
+		expiresAt,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *postgresUsers) VerifyEmail(ctx context.Context, tokenHash string) (models.User, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return models.User{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Step 1: Use up the token and the other ones that were sent
+	var userID string
+	query := `
+	UPDATE email_verifications SET used_at = NOW()
+	WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+	RETURNING user_id
+	`
+	if err := tx.QueryRow(ctx, query, tokenHash).Scan(&userID); err != nil {
+		return models.User{}, translateError(err)
+	}
+	_, err = tx.Exec(ctx, "UPDATE email_verifications SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL", userID)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	// Step 2: Keep the first time it was verified
+	_, err = tx.Exec(
+		ctx,
+		"UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW() WHERE id = $1",
+		userID,
+	)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return models.User{}, err
+	}
+
+	return r.GetByID(ctx, userID)
+}
+
+func (r *postgresUsers) AddFailedLogin(ctx context.Context, userID string, lockFor func(int) time.Duration) (*time.Time, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// The row lock makes concurrent failures count one after the other
+	var failures int
+	query := "UPDATE users SET failed_logins = failed_logins + 1 WHERE id = $1 RETURNING failed_logins"
+	if err := tx.QueryRow(ctx, query, userID).Scan(&failures); err != nil {
+		return nil, translateError(err)
+	}
+
+	var lockedUntil *time.Time
+	if lock := lockFor(failures); lock > 0 {
+		query = "UPDATE users SET locked_until = NOW() + make_interval(secs => $2) WHERE id = $1 RETURNING locked_until"
+		if err := tx.QueryRow(ctx, query, userID, lock.Seconds()).Scan(&lockedUntil); err != nil {
+			return nil, err
+		}
+	}
+
+	return lockedUntil, tx.Commit(ctx)
+}
+
+func (r *postgresUsers) ClearFailedLogins(ctx context.Context, userID string) error {
+	query := "UPDATE users SET failed_logins = 0, locked_until = NULL WHERE id = $1 AND (failed_logins > 0 OR locked_until IS NOT NULL)"
+	_, err := r.db.Exec(ctx, query, userID)
+	return err
+}
+
+func (r *postgresUsers) RecordLoginAttempt(ctx context.Context, attempt models.LoginAttempt) error {
+	query := `
+	INSERT INTO login_attempts (user_id, reason, ip_address, user_agent)
+	VALUES (NULLIF($1, '')::uuid, $2, $3, $4)
+	`
+
+	_, err := r.db.Exec(ctx, query, attempt.UserID, attempt.Reason, attempt.IPAddress, attempt.UserAgent)
+	return translateError(err)
+}
+
+func (r *postgresUsers) LoginAttempts(ctx context.Context, userID string, limit int) ([]models.LoginAttempt, error) {
+	query := `
+	SELECT id, user_id::text, reason, ip_address, user_agent, created_at
+	FROM login_attempts
+	WHERE user_id = $1
+	ORDER BY created_at DESC, id DESC
+	LIMIT $2
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	attempts := []models.LoginAttempt{}
+	for rows.Next() {
+		var a models.LoginAttempt
+		if err := rows.Scan(&a.ID, &a.UserID, &a.Reason, &a.IPAddress, &a.UserAgent, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, a)
+	}
+
+	return attempts, rows.Err()
+}
+
+func (r *postgresUsers) StartTOTPEnrollment(ctx context.Context, userID, secret string) error {
+	query := "UPDATE users SET totp_secret = $2, totp_last_step = NULL WHERE id = $1 AND totp_enabled_at IS NULL"
+
+	result, err := r.db.Exec(ctx, query, userID, secret)
+	if err != nil {
+		return translateError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// insertRecoveryCodes replaces the recovery codes of a user inside a transaction
+func insertRecoveryCodes(ctx context.Context, tx pgx.Tx, userID string, codeHashes []string) error {
+	if _, err := tx.Exec(ctx, "DELETE FROM recovery_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO recovery_codes (user_id, code_hash)
+	SELECT $1, hash FROM unnest($2::text[]) AS hash
+	`
+	_, err := tx.Exec(ctx, query, userID, codeHashes)
+	return translateError(err)
+}
+
+func (r *postgresUsers) EnableTOTP(ctx context.Context, userID string, step int64, recoveryHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	UPDATE users SET totp_enabled_at = NOW(), totp_last_step = $2, updated_at = NOW()
+	WHERE id = $1 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL
+	`
+	result, err := tx.Exec(ctx, query, userID, step)
+	if err != nil {
+		return translateError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	if err := insertRecoveryCodes(ctx, tx, userID, recoveryHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *postgresUsers) UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	query := `
+	UPDATE users SET totp_last_step = $2
+	WHERE id = $1 AND (totp_last_step IS NULL OR totp_last_step < $2)
+	`
+
+	result, err := r.db.Exec(ctx, query, userID, step)
+	if err != nil {
+		return false, translateError(err)
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+func (r *postgresUsers) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	query := "UPDATE recovery_codes SET used_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL"
+
+	result, err := r.db.Exec(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, translateError(err)
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+func (r *postgresUsers) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := insertRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *postgresUsers) DisableTOTP(ctx context.Context, userID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := "UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL, updated_at = NOW() WHERE id = $1"
+	result, err := tx.Exec(ctx, query, userID)
+	if err != nil {
+		return translateError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	// Sign-ins that were waiting for a code can't finish anymore
+	if _, err := tx.Exec(ctx, "DELETE FROM recovery_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, "UPDATE mfa_challenges SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL", userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *postgresUsers) CreateMFAChallenge(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error {
+	_, err := r.db.Exec(
+		ctx,
+		"INSERT INTO mfa_challenges (user_id, token_hash, expires_at) VALUES ($1, $2, $3)",
+		userID,
+		tokenHash,
+		expiresAt,
+	)
+	return translateError(err)
+}
+
+func (r *postgresUsers) MFAChallengeUser(ctx context.Context, tokenHash string, maxAttempts int) (models.User, error) {
+	var user models.User
+
+	query := `
+	SELECT ` + userColumns + `
+	FROM mfa_challenges c
+	JOIN users u ON u.id = c.user_id
+	WHERE c.token_hash = $1 AND c.used_at IS NULL AND c.expires_at > NOW() AND c.attempts < $2
+	`
+	err := r.db.QueryRow(ctx, query, tokenHash, maxAttempts).Scan(userFields(&user)...)
+
+	return user, translateError(err)
+}
+
+func (r *postgresUsers) FailMFAChallenge(ctx context.Context, tokenHash string) error {
+	_, err := r.db.Exec(ctx, "UPDATE mfa_challenges SET attempts = attempts + 1 WHERE token_hash = $1", tokenHash)
+	return err
+}
+
+func (r *postgresUsers) UseMFAChallenge(ctx context.Context, tokenHash string) error {
+	result, err := r.db.Exec(ctx, "UPDATE mfa_challenges SET used_at = NOW() WHERE token_hash = $1 AND used_at IS NULL", tokenHash)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,328 @@
+// Package repository is the data access layer. Handlers depend on the
+// interfaces defined here, which have a Postgres implementation for the
+// running app and an in-memory one for tests.
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+)
+
+var (
+	// ErrNotFound means the record does not exist, or isn't visible to the caller
+	ErrNotFound = errors.New("record not found")
+
+	// ErrInvalidCursor means a cursor does not fit the sort order it was used with
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// ErrInvalidOrder means a reorder did not list every item exactly once
+	ErrInvalidOrder = errors.New("ids do not match the current items")
+
+	// ErrSessionInactive means the session was revoked or has expired
+	ErrSessionInactive = errors.New("session has expired or was revoked")
+
+	// ErrVersionMismatch means a write expected the record at another version,
+	// someone else changed it in the meantime
+	ErrVersionMismatch = errors.New("record was changed by someone else")
+
+	// ErrLastOwner means a team change would leave a starthub without an owner
+	ErrLastOwner = errors.New("starthub needs at least one owner")
+
+	// ErrMissingReference means a write points at a record that does not exist
+	ErrMissingReference = errors.New("referenced record does not exist")
+
+	// ErrNotAllowed means a write has a value the schema doesn't allow
+	ErrNotAllowed = errors.New("value not allowed")
+
+	// ErrTokenReuse means a refresh token that was already rotated away was
+	// presented again. The session has been revoked by the time it is returned.
+	ErrTokenReuse = errors.New("refresh token reuse")
+)
+
+// Versions lists the versions a write expects a record to be at, usually taken
+// from an If-Match header. The write fails with ErrVersionMismatch when the
+// record is at another one. An empty list accepts any version.
+type Versions []int
+
+// Allow reports whether a record at version may be written
+func (v Versions) Allow(version int) bool {
+	return len(v) == 0 || slices.Contains(v, version)
+}
+
+// ThrottledError is returned when something was done too recently to be done
+// again. RetryAfter is how long until it is allowed.
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("throttled, retry after %s", e.RetryAfter)
+}
+
+// ConflictError is returned when a write hits a unique constraint.
+// Constraint uses the Postgres constraint name in every implementation.
+type ConflictError struct {
+	Constraint string
+	Err        error
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("unique constraint %q violated", e.Constraint)
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// NameTakenError is returned when a category would get the name of another one
+type NameTakenError struct {
+	CategoryID int
+}
+
+func (e *NameTakenError) Error() string {
+	return fmt.Sprintf("category %d already has this name", e.CategoryID)
+}
+
+// StartHubRepository stores starthubs along with their categories and
+// external collaborators
+type StartHubRepository interface {
+	// List returns one page of starthubs and the number of starthubs matching the filter
+	List(ctx context.Context, opts ListOptions) ([]models.StartHub, int, error)
+	// Search returns full-text matches, best first, and the number of matches
+	Search(ctx context.Context, opts SearchOptions) ([]models.StartHubSearchResult, int, error)
+	Get(ctx context.Context, id string) (models.StartHub, error)
+	Summary(ctx context.Context, id string) (models.StartHubSummary, error)
+	// MemberRole returns the team role of the user, or "" if they aren't on the team
+	MemberRole(ctx context.Context, id, userID string) (string, error)
+
+	// Create stores s with its categories and makes s.CreatedBy its owner
+	Create(ctx context.Context, s models.StartHub) (models.StartHub, error)
+	// Update overwrites the fields of a starthub userID is an owner or editor of.
+	// Categories are replaced when s.Categories is not nil.
+	Update(ctx context.Context, userID string, s models.StartHub, versions Versions) (models.StartHub, error)
+	// Delete removes a starthub userID is an owner of
+	Delete(ctx context.Context, id, userID string, versions Versions) error
+	// SetImageURL changes the image of a starthub userID is an owner or editor
+	// of and returns the URL it replaced
+	SetImageURL(ctx context.Context, id, userID, imageURL string) (string, error)
+
+	ListExternalCollaborators(ctx context.Context, starthubID string) ([]models.ExternalCollaborator, error)
+	// AddExternalCollaborator appends the collaborator at the end of the list
+	AddExternalCollaborator(ctx context.Context, starthubID string, collaborator models.ExternalCollaborator) (models.ExternalCollaborator, error)
+	ReorderExternalCollaborators(ctx context.Context, starthubID string, ids []int) error
+	DeleteExternalCollaborator(ctx context.Context, starthubID string, id int) error
+}
+
+// MemberRepository stores the teams of starthubs and the invitations to join
+// them. Writes that would leave a starthub without an owner fail with ErrLastOwner.
+type MemberRepository interface {
+	List(ctx context.Context, starthubID string) ([]models.Member, error)
+	SetRole(ctx context.Context, starthubID, userID, role string) (models.Member, error)
+	Remove(ctx context.Context, starthubID, userID string) error
+	// TransferOwnership makes toUserID, who is already on the team, an owner and
+	// fromUserID an editor
+	TransferOwnership(ctx context.Context, starthubID, fromUserID, toUserID string) error
+
+	// Invite stores a pending invitation with the hash of its token. Inviting
+	// someone who is on the team or already has a pending invitation is a conflict.
+	Invite(ctx context.Context, invitation models.Invitation, tokenHash string) (models.Invitation, error)
+	// ListInvitations returns the pending invitations of a starthub, newest first
+	ListInvitations(ctx context.Context, starthubID string) ([]models.Invitation, error)
+	RevokeInvitation(ctx context.Context, starthubID, id string) error
+	// InvitationByToken returns the pending invitation with the token hash, expired or not
+	InvitationByToken(ctx context.Context, tokenHash string) (models.Invitation, error)
+	// AcceptInvitation adds userID to the team with the role of a pending invitation
+	AcceptInvitation(ctx context.Context, id, userID string) (models.Member, error)
+	// StartHubsOf returns the starthubs a user is on the team of, by name.
+	// An empty roles list returns every team role.
+	StartHubsOf(ctx context.Context, userID string, roles []string) ([]models.TeamStartHub, error)
+}
+
+// PipelineRepository stores the starthubs investors track and where each deal stands
+type PipelineRepository interface {
+	// List returns the pipeline of an investor, most recently changed first.
+	// An empty stages list returns every stage.
+	List(ctx context.Context, investorID string, stages []string) ([]models.PipelineEntry, error)
+	// Save creates or updates the entry of a starthub and reports whether it was
+	// created. A nil stage or nil notes keep what the entry already has, a new
+	// entry without a stage is watching.
+	Save(ctx context.Context, investorID, starthubID string, stage, notes *string) (models.PipelineEntry, bool, error)
+	Delete(ctx context.Context, investorID, starthubID string) error
+	// InterestCount returns how many investors track a starthub and haven't passed on it
+	InterestCount(ctx context.Context, starthubID string) (int, error)
+}
+
+// FundingRepository stores the funding goals of starthubs and the pledges
+// donators make to them. Only paid pledges count towards what was raised.
+type FundingRepository interface {
+	// CreatePledge stores a pending pledge before it is charged. A goal, when
+	// set, has to belong to the starthub.
+	CreatePledge(ctx context.Context, p models.Pledge) (models.Pledge, error)
+	// SetPledgeStatus records the outcome of charging a pledge
+	SetPledgeStatus(ctx context.Context, id, status, paymentID string) (models.Pledge, error)
+	// ListPledges returns the pledges of a donator, newest first
+	ListPledges(ctx context.Context, donatorID string) ([]models.Pledge, error)
+
+	CreateGoal(ctx context.Context, goal models.FundingGoal) (models.FundingGoal, error)
+	GetGoal(ctx context.Context, starthubID string, id int) (models.FundingGoal, error)
+	DeleteGoal(ctx context.Context, starthubID string, id int) error
+	// Progress returns the totals per currency of a starthub and its goals
+	Progress(ctx context.Context, starthubID string) (models.FundingProgress, error)
+}
+
+// OpenRoleRepository stores the roles starthubs are looking to fill and the
+// applications collaborators send to them
+type OpenRoleRepository interface {
+	CreateRole(ctx context.Context, role models.OpenRole) (models.OpenRole, error)
+	GetRole(ctx context.Context, id string) (models.OpenRole, error)
+	// ListRoles returns the open roles matching the filter, newest first
+	ListRoles(ctx context.Context, filter models.OpenRoleFilter) ([]models.OpenRole, error)
+	// CloseRole stops a role of starthubID from taking new applications
+	CloseRole(ctx context.Context, starthubID, id string) error
+
+	// Apply stores a pending application to a role that is still open
+	Apply(ctx context.Context, application models.Application) (models.Application, error)
+	// ListApplications returns the applications to the roles of a starthub,
+	// newest first. An empty statuses list returns every status.
+	ListApplications(ctx context.Context, starthubID string, statuses []string) ([]models.Application, error)
+	// ListMyApplications returns the applications of a user, newest first
+	ListMyApplications(ctx context.Context, applicantID string) ([]models.Application, error)
+	// Decide accepts or rejects a pending application to a role of starthubID.
+	// Accepting adds the applicant as an external collaborator of the starthub.
+	Decide(ctx context.Context, starthubID, id, status string) (models.Application, error)
+	// Withdraw withdraws a pending application of applicantID
+	Withdraw(ctx context.Context, applicantID, id string) error
+}
+
+// CategoryRepository stores the shared category list
+type CategoryRepository interface {
+	List(ctx context.Context) ([]models.Category, error)
+	Rename(ctx context.Context, id int, name string) (models.Category, error)
+	// Merge moves every starthub of the sources onto the target and deletes the sources
+	Merge(ctx context.Context, sourceIDs []int, targetID int) (models.Category, error)
+}
+
+// CollaborationRepository stores collaborations between starthubs.
+// Each pair of starthubs has at most one collaboration, whoever proposed it.
+type CollaborationRepository interface {
+	Propose(ctx context.Context, proposal CollaborationProposal) (models.Collaboration, error)
+	// List returns the collaborations of a starthub seen from its side
+	List(ctx context.Context, starthubID string, statuses []string) ([]models.Collaboration, error)
+	// Respond accepts or declines a pending request partnerID sent to starthubID
+	Respond(ctx context.Context, starthubID, partnerID, status string) error
+	// End ends an accepted collaboration or withdraws a pending proposal of starthubID
+	End(ctx context.Context, starthubID, partnerID string) error
+}
+
+// CollaborationProposal is a new collaboration request
+type CollaborationProposal struct {
+	StartHubID  string
+	PartnerID   string
+	Message     string
+	RequestedBy string
+}
+
+// UserRepository stores users and their sign-in sessions
+type UserRepository interface {
+	Create(ctx context.Context, user models.User) (models.User, error)
+	GetByEmail(ctx context.Context, email string) (models.User, error)
+	GetByID(ctx context.Context, id string) (models.User, error)
+	// UpdateProfile replaces the profile and settings of a user
+	UpdateProfile(ctx context.Context, id string, profile models.Profile, settings models.AccountSettings) (models.User, error)
+
+	CreateSession(ctx context.Context, session models.Session) (string, error)
+	// SessionActive reports whether the session belongs to the user and can still be used
+	SessionActive(ctx context.Context, sessionID, userID string) (bool, error)
+	// RotateSession replaces the refresh token hash of a session. matches is
+	// called with the stored hash and, when that fails, with every hash the
+	// session rotated away. Matching one of those means a rotated token was
+	// used again: the session is revoked and ErrTokenReuse returned. Matching
+	// none returns ErrNotFound and leaves the session alone.
+	RotateSession(ctx context.Context, sessionID string, matches func(storedHash string) bool, newHash string) (models.User, error)
+	RevokeSession(ctx context.Context, sessionID, reason string) error
+	RevokeUserSessions(ctx context.Context, userID, reason string) (int64, error)
+
+	// ChangePassword replaces the password hash of a user and revokes every
+	// session but keepSessionID
+	ChangePassword(ctx context.Context, userID, passwordHash, keepSessionID string) error
+	// CreatePasswordReset stores the hash of a reset token for a user. A
+	// *ThrottledError is returned when the previous token of the user was
+	// created less than interval ago.
+	CreatePasswordReset(ctx context.Context, userID, tokenHash string, expiresAt time.Time, interval time.Duration) error
+	// ResetPassword sets the password of the user a reset token belongs to.
+	// Every outstanding token of the user is used up and every session revoked.
+	// Unknown, used and expired tokens return ErrNotFound.
+	ResetPassword(ctx context.Context, tokenHash, passwordHash string) (models.User, error)
+
+	// CreateEmailVerification stores the hash of a verification token for a
+	// user. A *ThrottledError is returned when the previous token of the user
+	// was created less than interval ago.
+	CreateEmailVerification(ctx context.Context, userID, tokenHash string, expiresAt time.Time, interval time.Duration) error
+	// VerifyEmail marks the email of the user a verification token belongs to
+	// as verified and uses up every outstanding token of the user. Unknown,
+	// used and expired tokens return ErrNotFound.
+	VerifyEmail(ctx context.Context, tokenHash string) (models.User, error)
+
+	// AddFailedLogin counts a failed sign-in of a user. lockFor is called with
+	// the failures in a row so far and returns how long to lock the account
+	// for, zero to leave it unlocked. The end of the lock is returned.
+	AddFailedLogin(ctx context.Context, userID string, lockFor func(failures int) time.Duration) (*time.Time, error)
+	// ClearFailedLogins forgets the failed sign-ins of a user and unlocks them
+	ClearFailedLogins(ctx context.Context, userID string) error
+	// RecordLoginAttempt adds a failed sign-in to the audit log
+	RecordLoginAttempt(ctx context.Context, attempt models.LoginAttempt) error
+	// LoginAttempts lists the latest failed sign-ins of a user, newest first
+	LoginAttempts(ctx context.Context, userID string, limit int) ([]models.LoginAttempt, error)
+
+	// StartTOTPEnrollment stores a new TOTP secret for a user without two-factor
+	// authentication, replacing one that was never confirmed. Users who have it
+	// enabled return ErrNotFound.
+	StartTOTPEnrollment(ctx context.Context, userID, secret string) error
+	// EnableTOTP turns two-factor authentication on after a code from the secret
+	// was confirmed at step, and replaces the recovery codes of the user
+	EnableTOTP(ctx context.Context, userID string, step int64, recoveryHashes []string) error
+	// UseTOTPStep records that the code of step was used. It returns false when
+	// that step or a later one was used already, so every code works once.
+	UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error)
+	// UseRecoveryCode uses up a recovery code of a user. It returns false when
+	// the user has no unused code with the hash.
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error)
+	// ReplaceRecoveryCodes drops every recovery code of a user for new ones
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+	// DisableTOTP turns two-factor authentication off, dropping the secret and
+	// the recovery codes
+	DisableTOTP(ctx context.Context, userID string) error
+
+	// CreateMFAChallenge stores the hash of a challenge token for a user who
+	// signed in with the right password and still has to give a second factor
+	CreateMFAChallenge(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error
+	// MFAChallengeUser returns the user of a challenge that is unused, unexpired
+	// and has seen fewer than maxAttempts wrong codes, ErrNotFound otherwise
+	MFAChallengeUser(ctx context.Context, tokenHash string, maxAttempts int) (models.User, error)
+	// FailMFAChallenge counts a wrong code given for a challenge
+	FailMFAChallenge(ctx context.Context, tokenHash string) error
+	// UseMFAChallenge uses up a challenge, ErrNotFound means it was used already
+	UseMFAChallenge(ctx context.Context, tokenHash string) error
+}
+
+// Repositories groups every repository the app needs
+type Repositories struct {
+	StartHubs      StartHubRepository
+	Categories     CategoryRepository
+	Collaborations CollaborationRepository
+	Members        MemberRepository
+	Pipeline       PipelineRepository
+	Funding        FundingRepository
+	OpenRoles      OpenRoleRepository
+	Users          UserRepository
+}
+
+// now is the clock of the in-memory store, truncated like Postgres timestamps
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
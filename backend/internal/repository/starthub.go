@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"cmp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+)
+
+// StartHubFilter holds the optional filters of the list and search endpoints
+type StartHubFilter struct {
+	Categories  []string // lower case, a starthub matches if it has any of them
+	Location    string   // case-insensitive substring
+	MinTeamSize *int
+	MaxTeamSize *int
+}
+
+// ListOptions selects one page of starthubs
+type ListOptions struct {
+	Filter StartHubFilter
+	Sort   string  // one of the Sort constants
+	After  *Cursor // only return starthubs after this one
+	Limit  int
+}
+
+// Cursor is the position of a starthub in a sort order
+type Cursor struct {
+	Value string // the sort key of the starthub, see SortValue
+	ID    string
+}
+
+// SearchOptions selects one page of full-text search results
+type SearchOptions struct {
+	Terms  []string // see SearchTerms, every term has to match as a prefix
+	Filter StartHubFilter
+	Limit  int
+	Offset int
+}
+
+// Sort orders of the starthub list
+const (
+	SortNewest   = "newest"
+	SortName     = "name"
+	SortTeamSize = "team_size"
+)
+
+// starthubSort describes one sort order along with the keyset it pages on.
+// Every order ends on the id so ties between equal keys stay stable across pages.
+type starthubSort struct {
+	column  string                       // SQL expression of the primary sort key
+	desc    bool                         // sort direction of both key and id
+	value   func(models.StartHub) string // the key of a row, as stored in a cursor
+	decode  func(string) (any, error)    // turns a cursor value back into a query argument
+	compare func(a, b models.StartHub) int
+}
+
+var starthubSorts = map[string]starthubSort{
+	SortNewest: {
+		column:  "s.join_date",
+		desc:    true,
+		value:   func(s models.StartHub) string { return s.JoinDate.Format(time.RFC3339Nano) },
+		decode:  func(v string) (any, error) { return time.Parse(time.RFC3339Nano, v) },
+		compare: func(a, b models.StartHub) int { return a.JoinDate.Compare(b.JoinDate) },
+	},
+	SortName: {
+		column:  "s.name",
+		value:   func(s models.StartHub) string { return s.Name },
+		decode:  func(v string) (any, error) { return v, nil },
+		compare: func(a, b models.StartHub) int { return strings.Compare(a.Name, b.Name) },
+	},
+	SortTeamSize: {
+		column:  "COALESCE(s.team_size, 0)",
+		desc:    true,
+		value:   func(s models.StartHub) string { return strconv.Itoa(s.TeamSize) },
+		decode:  func(v string) (any, error) { return strconv.Atoi(v) },
+		compare: func(a, b models.StartHub) int { return cmp.Compare(a.TeamSize, b.TeamSize) },
+	},
+}
+
+// ValidSort reports whether name is a known sort order
+func ValidSort(name string) bool {
+	_, ok := starthubSorts[name]
+	return ok
+}
+
+// SortValue returns the sort key of s, as stored in a Cursor
+func SortValue(sort string, s models.StartHub) string {
+	return starthubSorts[sort].value(s)
+}
+
+// SearchTerms splits free text into lower case search terms.
+// Everything but letters and digits is dropped, so no query syntax can be injected.
+func SearchTerms(input string) []string {
+	return strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// normalizeCategories trims names and drops empty and case-insensitive duplicates,
+// keeping the first spelling that was sent
+func normalizeCategories(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	normalized := make([]string, 0, len(names))
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, name)
+	}
+
+	return normalized
+}
+
+// normalizeIDs drops duplicate ids
+func normalizeIDs(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// sameIDs reports whether want contains exactly the ids in have, each once
+func sameIDs(have, want []int) bool {
+	if len(have) != len(want) {
+		return false
+	}
+
+	remaining := make(map[int]bool, len(have))
+	for _, id := range have {
+		remaining[id] = true
+	}
+	for _, id := range want {
+		if !remaining[id] {
+			return false
+		}
+		delete(remaining, id)
+	}
+
+	return true
+}
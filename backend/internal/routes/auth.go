@@ -1,45 +1,87 @@
 package routes
 
 import (
+	"errors"
 	"log"
+	"math"
+	"strconv"
+	"time"
 
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
 	"github.com/ecetinerdem/starthub-backend/internal/models"
-	"github.com/ecetinerdem/starthub-backend/pkg/utils"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
 	"github.com/gofiber/fiber/v2"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func LoginUser(db *pgxpool.Pool) fiber.Handler {
+// Lockout after failed sign-ins in a row. From the threshold on every failure
+// locks the account, for twice as long as the one before up to the maximum.
+const (
+	lockoutThreshold = 5
+	lockoutBase      = time.Minute
+	lockoutMax       = time.Hour
+)
+
+// lockFor is how long an account is locked after failures sign-ins in a row failed
+func lockFor(failures int) time.Duration {
+	if failures < lockoutThreshold {
+		return 0
+	}
+	if doublings := failures - lockoutThreshold; doublings < 6 {
+		return min(lockoutBase<<doublings, lockoutMax)
+	}
+	return lockoutMax
+}
+
+// lockedOut answers a sign-in to a locked account with when to try again
+func lockedOut(c *fiber.Ctx, until time.Time) error {
+	seconds := int(math.Ceil(time.Until(until).Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	return apperrors.TooManyRequests("Too many failed sign-ins, the account is locked for now. Try again later or reset your password.").
+		With("retry_after", seconds)
+}
+
+// recordLoginAttempt adds a failed sign-in to the audit log, failures are only logged
+func recordLoginAttempt(c *fiber.Ctx, users repository.UserRepository, userID, reason string) {
+	err := users.RecordLoginAttempt(c.Context(), models.LoginAttempt{
+		UserID:    userID,
+		Reason:    reason,
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	})
+	if err != nil {
+		log.Printf("❌ Could not record failed sign-in: %v", err)
+	}
+}
+
+// LoginUser - Signs a user in with their email and password. Failed attempts go
+// to the audit log and too many in a row lock the account for a while.
+func LoginUser(users repository.UserRepository) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		var request models.LoginRequest
 
 		if err := c.BodyParser(&request); err != nil {
 			log.Printf("❌ Could not parse login request: %v", err)
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid login data",
-			})
+			return apperrors.BadRequest("Invalid login data")
 		}
 
 		//Query user from db
 
-		var user models.User
+		user, err := users.GetByEmail(c.Context(), request.Email)
 
-		query := `SELECT id, email, password, role, created_at FROM users WHERE email = $1`
+		if errors.Is(err, repository.ErrNotFound) {
+			recordLoginAttempt(c, users, "", models.LoginUnknownAccount)
+			return apperrors.Unauthorized("Invalid email or password")
+		}
+		if err != nil {
+			return apperrors.Internal("Could not sign in", err)
+		}
 
-		err := db.QueryRow(c.Context(), query, request.Email).Scan(
-			&user.ID,
-			&user.Email,
-			&user.Password,
-			&user.Role,
-			&user.CreatedAt,
-		)
+		//A locked account doesn't get to try its password
 
-		if err != nil {
-			log.Printf("❌ User not found: %v", err)
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid email or password",
-			})
+		if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+			recordLoginAttempt(c, users, user.ID, models.LoginLocked)
+			return lockedOut(c, *user.LockedUntil)
 		}
 
 		//Check password
@@ -47,32 +89,60 @@ func LoginUser(db *pgxpool.Pool) fiber.Handler {
 		err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password))
 
 		if err != nil {
-			log.Printf("❌ Invalid password for user %s", user.Email)
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid email or password",
-			})
+			log.Printf("❌ Invalid password for user %s", user.ID)
+			recordLoginAttempt(c, users, user.ID, models.LoginWrongPassword)
+
+			lockedUntil, err := users.AddFailedLogin(c.Context(), user.ID, lockFor)
+			if err != nil {
+				return apperrors.Internal("Could not sign in", err)
+			}
+			if lockedUntil != nil {
+				log.Printf("⚠️ Locked user %s until %s after failed sign-ins", user.ID, lockedUntil.Format(time.RFC3339))
+			}
+
+			return apperrors.Unauthorized("Invalid email or password")
+		}
+
+		//With two-factor authentication the password only earns a challenge,
+		//failed sign-ins are cleared once the code is right too
+
+		if user.TOTPEnabledAt != nil {
+			return startMFAChallenge(c, users, user)
+		}
+
+		if err := users.ClearFailedLogins(c.Context(), user.ID); err != nil {
+			return apperrors.Internal("Could not sign in", err)
+		}
+
+		//Start a session and generate the token pair
+		response, err := startSession(c, users, user)
+
+		if err != nil {
+			return apperrors.Internal("Could not generate authentication token", err)
 		}
 
-		//Generate JWT
-		token, err := utils.GenerateJWT(user)
+		//Return user data and tokens
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
+
+// GetMyLoginAttempts - The latest failed sign-ins to the account of the signed-in
+// user, newest first, as many as ?limit= asks for
+func GetMyLoginAttempts(users repository.UserRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("user_id").(string)
+
+		limit, err := parseLimit(c)
+		if err != nil {
+			return apperrors.BadRequest(err.Error())
+		}
 
+		attempts, err := users.LoginAttempts(c.Context(), userID, limit)
 		if err != nil {
-			log.Printf("❌ Could not generate JWT: %v", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Could not generate authentication token",
-			})
+			return apperrors.Internal("Could not get sign-in attempts", err)
 		}
 
-		//Return user data and token
-
-		return c.Status(fiber.StatusOK).JSON(models.AuthResponse{
-			User: models.UserResponse{
-				ID:        user.ID,
-				Email:     user.Email,
-				Role:      user.Role,
-				CreatedAt: user.CreatedAt,
-			},
-			Token: token,
-		})
+		return c.JSON(attempts)
 	}
 }
@@ -0,0 +1,83 @@
+package routes
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetCategories - Lists all categories with the number of starthubs using each
+func GetCategories(categories repository.CategoryRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		list, err := categories.List(c.Context())
+		if err != nil {
+			return apperrors.Internal("Could not get categories from database", err)
+		}
+
+		return c.JSON(list)
+	}
+}
+
+// RenameCategory - Admin only, renames a category in place
+func RenameCategory(categories repository.CategoryRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		categoryID, err := c.ParamsInt("id")
+		if err != nil {
+			return apperrors.BadRequest("Invalid category ID")
+		}
+
+		var req models.RenameCategoryRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+
+		req.Name = strings.TrimSpace(req.Name)
+		if req.Name == "" {
+			return apperrors.BadRequest("Name is required")
+		}
+
+		category, err := categories.Rename(c.Context(), categoryID, req.Name)
+		if err != nil {
+			var taken *repository.NameTakenError
+			if errors.As(err, &taken) {
+				return apperrors.Conflict("Another category already has this name, merge them instead").
+					With("category_id", taken.CategoryID)
+			}
+			return dbError(err, "Category not found")
+		}
+
+		return c.JSON(category)
+	}
+}
+
+// MergeCategories - Admin only, moves every starthub of the source categories onto
+// the target and deletes the sources, all in one transaction
+func MergeCategories(categories repository.CategoryRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req models.MergeCategoriesRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+
+		if req.TargetID == 0 || len(req.SourceIDs) == 0 {
+			return apperrors.BadRequest("target_id and source_ids are required")
+		}
+		for _, id := range req.SourceIDs {
+			if id == req.TargetID {
+				return apperrors.BadRequest("target_id cannot also be a source")
+			}
+		}
+
+		// Starthubs of the sources move to the target, then the sources are deleted
+		category, err := categories.Merge(c.Context(), req.SourceIDs, req.TargetID)
+		if err != nil {
+			return dbError(err, "One or more categories not found")
+		}
+
+		return c.JSON(category)
+	}
+}
@@ -0,0 +1,142 @@
+package routes
+
+import (
+	"errors"
+
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ProposeCollaboration - Starthub :id asks another starthub to collaborate
+func ProposeCollaboration(starthubs repository.StartHubRepository, collaborations repository.CollaborationRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+		userID := c.Locals("user_id").(string)
+
+		var req models.CollaborationRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+
+		if req.StartHubID == "" {
+			return apperrors.BadRequest("starthub_id is required")
+		}
+		if req.StartHubID == starthubID {
+			return apperrors.BadRequest("A starthub cannot collaborate with itself")
+		}
+
+		if err := requireTeamRole(c, starthubs, starthubID, models.EditorRoles...); err != nil {
+			return err
+		}
+
+		collaboration, err := collaborations.Propose(c.Context(), repository.CollaborationProposal{
+			StartHubID:  starthubID,
+			PartnerID:   req.StartHubID,
+			Message:     req.Message,
+			RequestedBy: userID,
+		})
+		if err != nil {
+			// A missing partner is a 404, an existing collaboration between the two a 409
+			return dbError(err, "Starthub to collaborate with not found")
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(collaboration)
+	}
+}
+
+// GetCollaborations - Lists the collaborations of starthub :id, pending and accepted by default
+func GetCollaborations(starthubs repository.StartHubRepository, collaborations repository.CollaborationRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+
+		// Filter by status, "all" includes declined and ended ones too
+		var statuses []string
+		switch status := c.Query("status"); status {
+		case "":
+			statuses = []string{models.CollaborationPending, models.CollaborationAccepted}
+		case "all":
+			statuses = []string{
+				models.CollaborationPending,
+				models.CollaborationAccepted,
+				models.CollaborationDeclined,
+				models.CollaborationEnded,
+			}
+		case models.CollaborationPending, models.CollaborationAccepted, models.CollaborationDeclined, models.CollaborationEnded:
+			statuses = []string{status}
+		default:
+			return apperrors.BadRequest("Status must be one of 'pending', 'accepted', 'declined', 'ended' or 'all'")
+		}
+
+		if err := requireTeamRole(c, starthubs, starthubID, models.MemberRoles...); err != nil {
+			return err
+		}
+
+		list, err := collaborations.List(c.Context(), starthubID, statuses)
+		if err != nil {
+			return apperrors.Internal("Could not get collaborations", err)
+		}
+
+		return c.JSON(list)
+	}
+}
+
+// AcceptCollaboration - The team of :id accepts a request made by :partnerId
+func AcceptCollaboration(starthubs repository.StartHubRepository, collaborations repository.CollaborationRepository) fiber.Handler {
+	return respondToCollaboration(starthubs, collaborations, models.CollaborationAccepted)
+}
+
+// DeclineCollaboration - The team of :id declines a request made by :partnerId
+func DeclineCollaboration(starthubs repository.StartHubRepository, collaborations repository.CollaborationRepository) fiber.Handler {
+	return respondToCollaboration(starthubs, collaborations, models.CollaborationDeclined)
+}
+
+func respondToCollaboration(starthubs repository.StartHubRepository, collaborations repository.CollaborationRepository, status string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+		partnerID := c.Params("partnerId")
+
+		if err := requireTeamRole(c, starthubs, starthubID, models.EditorRoles...); err != nil {
+			return err
+		}
+
+		// Only the starthub that received the request can answer it
+		err := collaborations.Respond(c.Context(), starthubID, partnerID, status)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperrors.NotFound("No pending collaboration request from this starthub")
+		}
+		if err != nil {
+			return apperrors.Internal("Could not update collaboration", err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Collaboration " + status,
+		})
+	}
+}
+
+// EndCollaboration - Ends an accepted collaboration, or withdraws a pending one proposed by :id
+func EndCollaboration(starthubs repository.StartHubRepository, collaborations repository.CollaborationRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+		partnerID := c.Params("partnerId")
+
+		if err := requireTeamRole(c, starthubs, starthubID, models.EditorRoles...); err != nil {
+			return err
+		}
+
+		// Accepted collaborations are kept as history, withdrawn proposals are removed
+		err := collaborations.End(c.Context(), starthubID, partnerID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperrors.NotFound("No active collaboration or pending proposal with this starthub")
+		}
+		if err != nil {
+			return apperrors.Internal("Could not end collaboration", err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Collaboration ended",
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package routes
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ecetinerdem/starthub-backend/internal/mail"
+)
+
+// appLink is the address of a page of the web app, or "" when APP_URL doesn't
+// say where it runs
+func appLink(path string, query url.Values) string {
+	base := strings.TrimSuffix(os.Getenv("APP_URL"), "/")
+	if base == "" {
+		return ""
+	}
+	return base + path + "?" + query.Encode()
+}
+
+// passwordResetEmail carries a reset token, as a link when the web app is known
+func passwordResetEmail(to, token string, ttl time.Duration) mail.Message {
+	var body strings.Builder
+	body.WriteString("Someone asked to reset the password of your StartHub account.\n\n")
+	if link := appLink("/reset-password", url.Values{"token": {token}}); link != "" {
+		fmt.Fprintf(&body, "Choose a new password here: %s\n\n", link)
+	}
+	fmt.Fprintf(&body, "Reset code: %s\n\n", token)
+	fmt.Fprintf(&body, "It works once within the next %s. If it wasn't you, ignore this email, your password stays the same.\n", ttl)
+
+	return mail.Message{To: to, Subject: "Reset your StartHub password", Body: body.String()}
+}
+
+// passwordChangedEmail tells the owner of an account its password was changed,
+// so a takeover doesn't go unnoticed
+func passwordChangedEmail(to string) mail.Message {
+	return mail.Message{
+		To:      to,
+		Subject: "Your StartHub password was changed",
+		Body: "The password of your StartHub account was just changed and your other devices were signed out.\n\n" +
+			"If it wasn't you, reset your password right away.\n",
+	}
+}
+
+// verificationEmail carries the token that proves the address belongs to the
+// person who signed up with it
+func verificationEmail(to, token string, ttl time.Duration) mail.Message {
+	var body strings.Builder
+	body.WriteString("Welcome to StartHub! Confirm this is your email address to finish setting up your account.\n\n")
+	if link := appLink("/verify-email", url.Values{"token": {token}}); link != "" {
+		fmt.Fprintf(&body, "Confirm it here: %s\n\n", link)
+	}
+	fmt.Fprintf(&body, "Verification code: %s\n\n", token)
+	fmt.Fprintf(&body, "It works within the next %s. If you didn't sign up, ignore this email.\n", ttl)
+
+	return mail.Message{To: to, Subject: "Verify your StartHub email", Body: body.String()}
+}
+
+// twoFactorChangedEmail tells the owner of an account two-factor authentication
+// was turned on or off
+func twoFactorChangedEmail(to string, enabled bool) mail.Message {
+	state := "off"
+	if enabled {
+		state = "on"
+	}
+
+	return mail.Message{
+		To:      to,
+		Subject: "Two-factor authentication was turned " + state,
+		Body: "Two-factor authentication was just turned " + state + " for your StartHub account.\n\n" +
+			"If it wasn't you, reset your password right away.\n",
+	}
+}
+
+// invitationEmail asks the invited address to join the team of a starthub
+func invitationEmail(to, starthubName, role, token string, ttl time.Duration) mail.Message {
+	var body strings.Builder
+	fmt.Fprintf(&body, "You were invited to join the team of %s on StartHub as %s.\n\n", starthubName, articleFor(role))
+	if link := appLink("/invitations/accept", url.Values{"token": {token}}); link != "" {
+		fmt.Fprintf(&body, "Join the team here: %s\n\n", link)
+	}
+	fmt.Fprintf(&body, "Invitation code: %s\n\n", token)
+	fmt.Fprintf(&body, "Sign in or sign up with this email address to accept it within the next %s. If you don't know %s, ignore this email.\n", ttl, starthubName)
+
+	return mail.Message{To: to, Subject: "Join " + starthubName + " on StartHub", Body: body.String()}
+}
+
+// articleFor puts "a" or "an" before a team role
+func articleFor(role string) string {
+	if strings.ContainsAny(role[:1], "aeiou") {
+		return "an " + role
+	}
+	return "a " + role
+}
@@ -0,0 +1,62 @@
+package routes
+
+import (
+	"errors"
+
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+)
+
+// uniqueViolations maps unique constraints to the message shown when they are hit
+var uniqueViolations = map[string]string{
+	"users_email_key":                  "An account with this email already exists",
+	"starthubs_email_key":              "A starthub with this email already exists",
+	"idx_starthub_collaborations_pair": "These starthubs already have a pending or active collaboration",
+	"starthub_members_pkey":            "This user is already on the team",
+	"idx_starthub_invitations_pending": "This email already has a pending invitation",
+	"idx_role_applications_active":     "You already applied to this role",
+}
+
+// dbError turns a repository error into an API error. A missing record becomes
+// a 404 with notFound as its message, constraint violations become client
+// errors, and anything else is an internal error.
+func dbError(err error, notFound string) *apperrors.Error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperrors.NotFound(notFound)
+	}
+	if errors.Is(err, repository.ErrVersionMismatch) {
+		return apperrors.PreconditionFailed("It was changed since you last fetched it, fetch it again and retry")
+	}
+	if errors.Is(err, repository.ErrLastOwner) {
+		return apperrors.Conflict("A starthub needs at least one owner, make someone else an owner first")
+	}
+
+	var conflict *repository.ConflictError
+	if errors.As(err, &conflict) {
+		return uniqueViolation(conflict.Constraint, err)
+	}
+
+	if errors.Is(err, repository.ErrMissingReference) {
+		e := apperrors.BadRequest("A referenced record does not exist")
+		e.Err = err
+		return e
+	}
+	if errors.Is(err, repository.ErrNotAllowed) {
+		e := apperrors.BadRequest("A value is not allowed")
+		e.Err = err
+		return e
+	}
+
+	return apperrors.Internal("Database error", err)
+}
+
+func uniqueViolation(constraint string, err error) *apperrors.Error {
+	message, ok := uniqueViolations[constraint]
+	if !ok {
+		message = "This record already exists"
+	}
+
+	e := apperrors.Conflict(message)
+	e.Err = err
+	return e
+}
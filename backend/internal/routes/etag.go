@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ETags are the quoted version of a record. Versions change on every write, so
+// a client that sends back the ETag it read in If-Match only overwrites what it
+// has seen.
+
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// setETag sends the ETag of a record at version
+func setETag(c *fiber.Ctx, version int) {
+	c.Set(fiber.HeaderETag, etag(version))
+}
+
+// ifMatch reads the If-Match header into the versions a write may apply to.
+// No header or "*" allow any version. Weak tags never match, as RFC 9110 asks,
+// so a header without a usable tag fails with a 412 straight away.
+func ifMatch(c *fiber.Ctx) (repository.Versions, error) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	var versions repository.Versions
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") || len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		if version, err := strconv.Atoi(tag[1 : len(tag)-1]); err == nil {
+			versions = append(versions, version)
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, apperrors.PreconditionFailed("If-Match has no ETag that could match")
+	}
+	return versions, nil
+}
+
+// notModified reports whether If-None-Match names the record at version, in
+// which case the client's copy is current. Weak and strong tags both count.
+func notModified(c *fiber.Ctx, version int) bool {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfNoneMatch))
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+
+	current := etag(version)
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == current {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package routes
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// isValidURL accepts absolute http(s) URLs only
+func isValidURL(raw string) bool {
+	u, err := url.ParseRequestURI(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// GetExternalCollaborators - Lists the external collaborators of a starthub in display order
+func GetExternalCollaborators(starthubs repository.StartHubRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+
+		collaborators, err := starthubs.ListExternalCollaborators(c.Context(), starthubID)
+		if err != nil {
+			return dbError(err, "Starthub not found")
+		}
+
+		return c.JSON(collaborators)
+	}
+}
+
+// AddExternalCollaborator - Adds an external collaborator at the end of the list
+func AddExternalCollaborator(starthubs repository.StartHubRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+
+		var req models.ExternalCollaboratorRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+
+		req.Name = strings.TrimSpace(req.Name)
+		if req.Name == "" {
+			return apperrors.BadRequest("Name is required")
+		}
+		if req.URL != "" && !isValidURL(req.URL) {
+			return apperrors.BadRequest("URL must be a valid http or https address")
+		}
+		if req.LogoURL != "" && !isValidURL(req.LogoURL) {
+			return apperrors.BadRequest("Logo URL must be a valid http or https address")
+		}
+
+		if err := requireTeamRole(c, starthubs, starthubID, models.EditorRoles...); err != nil {
+			return err
+		}
+
+		collaborator, err := starthubs.AddExternalCollaborator(c.Context(), starthubID, models.ExternalCollaborator{
+			Name:    req.Name,
+			URL:     req.URL,
+			Role:    req.Role,
+			LogoURL: req.LogoURL,
+		})
+		if err != nil {
+			return dbError(err, "Starthub not found")
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(collaborator)
+	}
+}
+
+// ReorderExternalCollaborators - Sets the display order, the ids must list every collaborator once
+func ReorderExternalCollaborators(starthubs repository.StartHubRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+
+		var req models.ReorderRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+
+		if err := requireTeamRole(c, starthubs, starthubID, models.EditorRoles...); err != nil {
+			return err
+		}
+
+		err := starthubs.ReorderExternalCollaborators(c.Context(), starthubID, req.IDs)
+		if errors.Is(err, repository.ErrInvalidOrder) {
+			return apperrors.BadRequest("ids must list every external collaborator of this starthub exactly once")
+		}
+		if err != nil {
+			return apperrors.Internal("Could not reorder external collaborators", err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "External collaborators reordered",
+		})
+	}
+}
+
+// DeleteExternalCollaborator - Removes one external collaborator from a starthub
+func DeleteExternalCollaborator(starthubs repository.StartHubRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+
+		collaboratorID, err := c.ParamsInt("collaboratorId")
+		if err != nil {
+			return apperrors.BadRequest("Invalid external collaborator ID")
+		}
+
+		if err := requireTeamRole(c, starthubs, starthubID, models.EditorRoles...); err != nil {
+			return err
+		}
+
+		err = starthubs.DeleteExternalCollaborator(c.Context(), starthubID, collaboratorID)
+		if err != nil {
+			return dbError(err, "External collaborator not found")
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "External collaborator deleted",
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/internal/payments"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Limits of pledges and goals. Amounts are in minor units, like cents.
+const (
+	maxFundingAmount   = 1_000_000_000
+	maxPledgeMessage   = 500
+	maxGoalTitle       = 200
+	chargeTimeout      = 15 * time.Second
+	goalDeadlineFormat = "2006-01-02"
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// validMoney checks an amount in minor units and normalizes its ISO 4217 currency
+func validMoney(amount int64, currency *string) []apperrors.FieldError {
+	var fields []apperrors.FieldError
+
+	if amount <= 0 || amount > maxFundingAmount {
+		fields = append(fields, apperrors.FieldError{
+			Field:   "amount",
+			Message: fmt.Sprintf("Amount must be between 1 and %d, in minor units like cents", maxFundingAmount),
+		})
+	}
+
+	*currency = strings.ToUpper(strings.TrimSpace(*currency))
+	if !currencyPattern.MatchString(*currency) {
+		fields = append(fields, apperrors.FieldError{Field: "currency", Message: "Currency must be a three letter ISO 4217 code like EUR"})
+	}
+
+	return fields
+}
+
+// CreatePledge - A donator pledges money to starthub :id and pays it right away.
+// A declined payment is a 402 and the pledge is kept as failed.
+func CreatePledge(funding repository.FundingRepository, provider payments.Provider) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+		donatorID := c.Locals("user_id").(string)
+
+		var req models.PledgeRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+
+		// Step 1: Validate
+		req.Message = strings.TrimSpace(req.Message)
+		fields := validMoney(req.Amount, &req.Currency)
+		if utf8.RuneCountInString(req.Message) > maxPledgeMessage {
+			fields = append(fields, apperrors.FieldError{Field: "message", Message: fmt.Sprintf("Message can be at most %d characters", maxPledgeMessage)})
+		}
+		if req.PaymentMethod == "" {
+			fields = append(fields, apperrors.FieldError{Field: "payment_method", Message: "A payment method is required"})
+		}
+		if len(fields) > 0 {
+			return apperrors.Validation(fields...)
+		}
+
+		// Step 2: A pledge to a goal is in the currency of the goal
+		if req.GoalID != nil {
+			goal, err := funding.GetGoal(c.Context(), starthubID, *req.GoalID)
+			if err != nil {
+				return dbError(err, "Funding goal not found")
+			}
+			if goal.Currency != req.Currency {
+				return apperrors.Field("currency", "Pledges to this goal must be in "+goal.Currency)
+			}
+		}
+
+		// Step 3: Record the pledge before charging, so no charge goes unrecorded
+		pledge, err := funding.CreatePledge(c.Context(), models.Pledge{
+			StartHub:  models.StartHubSummary{ID: starthubID},
+			GoalID:    req.GoalID,
+			Amount:    req.Amount,
+			Currency:  req.Currency,
+			Message:   req.Message,
+			Provider:  provider.Name(),
+			DonatorID: donatorID,
+		})
+		if err != nil {
+			return dbError(err, "Starthub not found")
+		}
+
+		// Step 4: Charge it, the pledge id makes retries safe
+		ctx, cancel := context.WithTimeout(c.UserContext(), chargeTimeout)
+		defer cancel()
+
+		paymentID, err := provider.Charge(ctx, payments.Charge{
+			Amount:         pledge.Amount,
+			Currency:       pledge.Currency,
+			PaymentMethod:  req.PaymentMethod,
+			Description:    "Pledge to " + pledge.StartHub.Name,
+			IdempotencyKey: pledge.ID,
+		})
+		if errors.Is(err, payments.ErrDeclined) || errors.Is(err, payments.ErrUnavailable) {
+			if _, saveErr := funding.SetPledgeStatus(c.Context(), pledge.ID, models.PledgeFailed, ""); saveErr != nil {
+				log.Printf("❌ Could not mark pledge %s as failed: %v", pledge.ID, saveErr)
+			}
+			if errors.Is(err, payments.ErrUnavailable) {
+				return apperrors.ServiceUnavailable("Pledges can't be paid right now, try again later")
+			}
+			return apperrors.PaymentRequired("The payment was declined, try another payment method").
+				With("pledge_id", pledge.ID)
+		}
+		if err != nil {
+			// The charge may still have gone through, so the pledge stays pending
+			// until someone checks it with the provider
+			return apperrors.Internal("Could not charge pledge", err)
+		}
+
+		// Step 5: It counts towards the totals from now on
+		pledge, err = funding.SetPledgeStatus(c.Context(), pledge.ID, models.PledgePaid, paymentID)
+		if err != nil {
+			log.Printf("❌ Pledge %s was paid as %s but could not be marked paid: %v", pledge.ID, paymentID, err)
+			return apperrors.Internal("Could not save pledge", err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(pledge)
+	}
+}
+
+// GetMyPledges - Lists the pledges of the signed-in donator, newest first
+func GetMyPledges(funding repository.FundingRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		donatorID := c.Locals("user_id").(string)
+
+		pledges, err := funding.ListPledges(c.Context(), donatorID)
+		if err != nil {
+			return apperrors.Internal("Could not get pledges", err)
+		}
+
+		return c.JSON(pledges)
+	}
+}
+
+// GetFundingProgress - What starthub :id has raised per currency and for each goal
+func GetFundingProgress(funding repository.FundingRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		progress, err := funding.Progress(c.Context(), c.Params("id"))
+		if err != nil {
+			return dbError(err, "Starthub not found")
+		}
+
+		return c.JSON(progress)
+	}
+}
+
+// CreateFundingGoal - Owners and editors publish an amount starthub :id wants to raise
+func CreateFundingGoal(starthubs repository.StartHubRepository, funding repository.FundingRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+
+		var req models.FundingGoalRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+
+		req.Title = strings.TrimSpace(req.Title)
+		fields := validMoney(req.Amount, &req.Currency)
+		if req.Title == "" || utf8.RuneCountInString(req.Title) > maxGoalTitle {
+			fields = append(fields, apperrors.FieldError{Field: "title", Message: fmt.Sprintf("Title is required and can be at most %d characters", maxGoalTitle)})
+		}
+
+		var deadline *time.Time
+		if req.Deadline != "" {
+			date, err := time.Parse(goalDeadlineFormat, req.Deadline)
+			if err != nil {
+				fields = append(fields, apperrors.FieldError{Field: "deadline", Message: "Deadline must be a date like 2025-12-31"})
+			} else if date.Before(time.Now().UTC().Truncate(24 * time.Hour)) {
+				fields = append(fields, apperrors.FieldError{Field: "deadline", Message: "Deadline can't be in the past"})
+			}
+			deadline = &date
+		}
+		if len(fields) > 0 {
+			return apperrors.Validation(fields...)
+		}
+
+		if err := requireTeamRole(c, starthubs, starthubID, models.EditorRoles...); err != nil {
+			return err
+		}
+
+		goal, err := funding.CreateGoal(c.Context(), models.FundingGoal{
+			StartHubID: starthubID,
+			Title:      req.Title,
+			Amount:     req.Amount,
+			Currency:   req.Currency,
+			Deadline:   deadline,
+		})
+		if err != nil {
+			return dbError(err, "Starthub not found")
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(goal)
+	}
+}
+
+// DeleteFundingGoal - Takes a goal down, pledges made to it still count for the starthub
+func DeleteFundingGoal(starthubs repository.StartHubRepository, funding repository.FundingRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+
+		goalID, err := c.ParamsInt("goalId")
+		if err != nil {
+			return apperrors.BadRequest("Invalid funding goal ID")
+		}
+
+		if err := requireTeamRole(c, starthubs, starthubID, models.EditorRoles...); err != nil {
+			return err
+		}
+
+		if err := funding.DeleteGoal(c.Context(), starthubID, goalID); err != nil {
+			return dbError(err, "Funding goal not found")
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Funding goal deleted",
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package routes
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/mail"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+	"github.com/ecetinerdem/starthub-backend/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// invitationTTL is how long an invitation can be accepted
+const invitationTTL = 7 * 24 * time.Hour
+
+// InviteMember - An owner invites an email to the team of starthub :id. The
+// token is only emailed to the address, only the account with the invited and
+// verified email can use it.
+func InviteMember(starthubs repository.StartHubRepository, members repository.MemberRepository, mailer mail.Mailer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+		userID := c.Locals("user_id").(string)
+
+		var req models.InvitationRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+
+		// Step 1: Validate, new members are editors unless asked otherwise
+		req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+		if !emailPattern.MatchString(req.Email) {
+			return apperrors.Field("email", "Invalid email format")
+		}
+		if req.Role == "" {
+			req.Role = models.MemberEditor
+		}
+		if err := validMemberRole(req.Role); err != nil {
+			return err
+		}
+
+		if err := requireTeamRole(c, starthubs, starthubID, models.MemberOwner); err != nil {
+			return err
+		}
+
+		// Step 2: Store the invitation with the hash of a new token
+		token, hash, err := utils.GenerateSecret()
+		if err != nil {
+			return apperrors.Internal("Could not create invitation", err)
+		}
+
+		invitation, err := members.Invite(c.Context(), models.Invitation{
+			StartHubID: starthubID,
+			Email:      req.Email,
+			Role:       req.Role,
+			InvitedBy:  userID,
+			ExpiresAt:  time.Now().Add(invitationTTL),
+		}, hash)
+		if err != nil {
+			// Members and emails with an open invitation are a 409
+			return dbError(err, "Starthub not found")
+		}
+
+		// Step 3: Email the token to the invited address
+		starthub, err := starthubs.Get(c.Context(), starthubID)
+		if err != nil {
+			return dbError(err, "Starthub not found")
+		}
+		sendMail(c, mailer, invitationEmail(invitation.Email, starthub.Name, invitation.Role, token, invitationTTL))
+
+		return c.Status(fiber.StatusCreated).JSON(invitation)
+	}
+}
+
+// GetInvitations - Lists the pending invitations of starthub :id
+func GetInvitations(starthubs repository.StartHubRepository, members repository.MemberRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+
+		if err := requireTeamRole(c, starthubs, starthubID, models.MemberOwner); err != nil {
+			return err
+		}
+
+		invitations, err := members.ListInvitations(c.Context(), starthubID)
+		if err != nil {
+			return apperrors.Internal("Could not get invitations", err)
+		}
+
+		return c.JSON(invitations)
+	}
+}
+
+// RevokeInvitation - An owner withdraws a pending invitation
+func RevokeInvitation(starthubs repository.StartHubRepository, members repository.MemberRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+		invitationID := c.Params("invitationId")
+
+		if err := requireTeamRole(c, starthubs, starthubID, models.MemberOwner); err != nil {
+			return err
+		}
+
+		if err := members.RevokeInvitation(c.Context(), starthubID, invitationID); err != nil {
+			return dbError(err, "Invitation not found")
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Invitation revoked",
+		})
+	}
+}
+
+// AcceptInvitation - The signed-in user joins a team with an invitation token
+// sent to their email. It runs after RequireVerifiedEmail, so the account is
+// known to own the address.
+func AcceptInvitation(members repository.MemberRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("user_id").(string)
+		email := c.Locals("user_email").(string)
+
+		var req models.AcceptInvitationRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+		if req.Token == "" {
+			return apperrors.Field("token", "token is required")
+		}
+
+		// Step 1: Find the invitation, used and unknown tokens look the same
+		invitation, err := members.InvitationByToken(c.Context(), utils.HashToken(req.Token))
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperrors.NotFound("Invitation not found or already used")
+		}
+		if err != nil {
+			return apperrors.Internal("Could not accept invitation", err)
+		}
+
+		// Step 2: It only works for the account it was sent to
+		if !strings.EqualFold(invitation.Email, email) {
+			return apperrors.Forbidden("This invitation was sent to another email address")
+		}
+		if !time.Now().Before(invitation.ExpiresAt) {
+			return apperrors.NotFound("This invitation has expired, ask for a new one")
+		}
+
+		// Step 3: Join the team, people already on it keep their role
+		member, err := members.AcceptInvitation(c.Context(), invitation.ID, userID)
+		if err != nil {
+			return dbError(err, "Invitation not found or already used")
+		}
+
+		return c.JSON(fiber.Map{
+			"starthub_id": invitation.StartHubID,
+			"member":      member,
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package routes
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// requireTeamRole returns a 403 unless the signed-in user has one of roles on
+// the team of the starthub. A missing starthub looks the same, so ids of other
+// teams' starthubs can't be probed.
+func requireTeamRole(c *fiber.Ctx, starthubs repository.StartHubRepository, starthubID string, roles ...string) error {
+	userID := c.Locals("user_id").(string)
+
+	role, err := starthubs.MemberRole(c.Context(), starthubID, userID)
+	if err != nil {
+		return apperrors.Internal("Could not check your team role", err)
+	}
+	if !slices.Contains(roles, role) {
+		return apperrors.Forbidden("Starthub not found or your team role doesn't allow this").
+			With("required_team_roles", roles)
+	}
+
+	return nil
+}
+
+// validMemberRole checks a team role from a request body
+func validMemberRole(role string) error {
+	if !slices.Contains(models.MemberRoles, role) {
+		return apperrors.Field("role", "Role must be one of 'owner', 'editor' or 'viewer'")
+	}
+	return nil
+}
+
+// GetMembers - Lists the team of starthub :id, owners first
+func GetMembers(starthubs repository.StartHubRepository, members repository.MemberRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+
+		if err := requireTeamRole(c, starthubs, starthubID, models.MemberRoles...); err != nil {
+			return err
+		}
+
+		list, err := members.List(c.Context(), starthubID)
+		if err != nil {
+			return apperrors.Internal("Could not get team members", err)
+		}
+
+		return c.JSON(list)
+	}
+}
+
+// UpdateMemberRole - An owner changes the role of member :userId
+func UpdateMemberRole(starthubs repository.StartHubRepository, members repository.MemberRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+		memberID := c.Params("userId")
+
+		var req models.MemberRoleRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+		if err := validMemberRole(req.Role); err != nil {
+			return err
+		}
+
+		if err := requireTeamRole(c, starthubs, starthubID, models.MemberOwner); err != nil {
+			return err
+		}
+
+		// Demoting the last owner is a 409
+		member, err := members.SetRole(c.Context(), starthubID, memberID, req.Role)
+		if err != nil {
+			return dbError(err, "Member not found")
+		}
+
+		return c.JSON(member)
+	}
+}
+
+// RemoveMember - An owner removes member :userId, or any member leaves the team
+func RemoveMember(starthubs repository.StartHubRepository, members repository.MemberRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+		memberID := c.Params("userId")
+		userID := c.Locals("user_id").(string)
+
+		roles := []string{models.MemberOwner}
+		if memberID == userID {
+			roles = models.MemberRoles
+		}
+		if err := requireTeamRole(c, starthubs, starthubID, roles...); err != nil {
+			return err
+		}
+
+		// The last owner can't leave, they hand the starthub over or delete it
+		err := members.Remove(c.Context(), starthubID, memberID)
+		if err != nil {
+			return dbError(err, "Member not found")
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Member removed",
+		})
+	}
+}
+
+// TransferOwnership - An owner makes another member an owner and becomes an editor
+func TransferOwnership(starthubs repository.StartHubRepository, members repository.MemberRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+		userID := c.Locals("user_id").(string)
+
+		var req models.TransferOwnershipRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+		if req.UserID == "" {
+			return apperrors.Field("user_id", "user_id is required")
+		}
+		if req.UserID == userID {
+			return apperrors.Field("user_id", "You already own this starthub")
+		}
+
+		if err := requireTeamRole(c, starthubs, starthubID, models.MemberOwner); err != nil {
+			return err
+		}
+
+		// Only someone already on the team can take over, invite them first
+		err := members.TransferOwnership(c.Context(), starthubID, userID, req.UserID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperrors.Field("user_id", "The new owner has to be on the team")
+		}
+		if err != nil {
+			return apperrors.Internal("Could not transfer ownership", err)
+		}
+
+		list, err := members.List(c.Context(), starthubID)
+		if err != nil {
+			return apperrors.Internal("Could not get team members", err)
+		}
+
+		return c.JSON(list)
+	}
+}
@@ -0,0 +1,309 @@
+package routes
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Limits of open roles and applications, in characters
+const (
+	maxRoleTitle          = 200
+	maxRoleDescription    = 5000
+	maxRoleLocation       = 200
+	maxRoleSkills         = 20
+	maxSkillLength        = 50
+	maxApplicantName      = 200
+	maxApplicationMessage = 2000
+)
+
+// normalizeSkills trims and lowercases skills so they match exactly, dropping
+// empty and repeated ones
+func normalizeSkills(skills []string) []string {
+	normalized := []string{}
+	for _, skill := range skills {
+		skill = strings.ToLower(strings.TrimSpace(skill))
+		if skill != "" && !slices.Contains(normalized, skill) {
+			normalized = append(normalized, skill)
+		}
+	}
+	return normalized
+}
+
+// validateOpenRole normalizes an open role request and returns what is wrong with it
+func validateOpenRole(req *models.OpenRoleRequest) []apperrors.FieldError {
+	var fields []apperrors.FieldError
+
+	req.Title = strings.TrimSpace(req.Title)
+	req.Description = strings.TrimSpace(req.Description)
+	req.Location = strings.TrimSpace(req.Location)
+	req.Skills = normalizeSkills(req.Skills)
+
+	if req.Title == "" || utf8.RuneCountInString(req.Title) > maxRoleTitle {
+		fields = append(fields, apperrors.FieldError{Field: "title", Message: fmt.Sprintf("Title is required and can be at most %d characters", maxRoleTitle)})
+	}
+	if utf8.RuneCountInString(req.Description) > maxRoleDescription {
+		fields = append(fields, apperrors.FieldError{Field: "description", Message: fmt.Sprintf("Description can be at most %d characters", maxRoleDescription)})
+	}
+	if len(req.Skills) > maxRoleSkills || slices.ContainsFunc(req.Skills, func(s string) bool { return utf8.RuneCountInString(s) > maxSkillLength }) {
+		fields = append(fields, apperrors.FieldError{Field: "skills", Message: fmt.Sprintf("Up to %d skills of at most %d characters each", maxRoleSkills, maxSkillLength)})
+	}
+	if !req.Remote && req.Location == "" {
+		fields = append(fields, apperrors.FieldError{Field: "location", Message: "Location is required unless the role is remote"})
+	}
+	if utf8.RuneCountInString(req.Location) > maxRoleLocation {
+		fields = append(fields, apperrors.FieldError{Field: "location", Message: fmt.Sprintf("Location can be at most %d characters", maxRoleLocation)})
+	}
+	if !slices.Contains(models.Commitments, req.Commitment) {
+		fields = append(fields, apperrors.FieldError{Field: "commitment", Message: "Commitment must be one of " + strings.Join(models.Commitments, ", ")})
+	}
+
+	return fields
+}
+
+// GetOpenRoles - Lists open roles newest first, optionally filtered like
+// ?skill=go&remote=true&commitment=part_time
+func GetOpenRoles(openRoles repository.OpenRoleRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		filter := models.OpenRoleFilter{
+			Skill:      strings.ToLower(strings.TrimSpace(c.Query("skill"))),
+			Commitment: c.Query("commitment"),
+		}
+
+		if raw := c.Query("remote"); raw != "" {
+			remote, err := strconv.ParseBool(raw)
+			if err != nil {
+				return apperrors.BadRequest("remote must be true or false")
+			}
+			filter.Remote = &remote
+		}
+		if filter.Commitment != "" && !slices.Contains(models.Commitments, filter.Commitment) {
+			return apperrors.BadRequest("Commitment must be one of " + strings.Join(models.Commitments, ", "))
+		}
+
+		roles, err := openRoles.ListRoles(c.Context(), filter)
+		if err != nil {
+			return apperrors.Internal("Could not get open roles", err)
+		}
+
+		return c.JSON(roles)
+	}
+}
+
+// GetStartHubOpenRoles - Lists the open roles of starthub :id newest first
+func GetStartHubOpenRoles(openRoles repository.OpenRoleRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		roles, err := openRoles.ListRoles(c.Context(), models.OpenRoleFilter{StartHubID: c.Params("id")})
+		if err != nil {
+			return dbError(err, "Starthub not found")
+		}
+
+		return c.JSON(roles)
+	}
+}
+
+// GetOpenRole - Gets open role :roleId, closed ones included
+func GetOpenRole(openRoles repository.OpenRoleRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, err := openRoles.GetRole(c.Context(), c.Params("roleId"))
+		if err != nil {
+			return dbError(err, "Open role not found")
+		}
+
+		return c.JSON(role)
+	}
+}
+
+// CreateOpenRole - Owners and editors post a role starthub :id is looking to fill
+func CreateOpenRole(starthubs repository.StartHubRepository, openRoles repository.OpenRoleRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+
+		var req models.OpenRoleRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+
+		if fields := validateOpenRole(&req); len(fields) > 0 {
+			return apperrors.Validation(fields...)
+		}
+
+		if err := requireTeamRole(c, starthubs, starthubID, models.EditorRoles...); err != nil {
+			return err
+		}
+
+		role, err := openRoles.CreateRole(c.Context(), models.OpenRole{
+			StartHub:    models.StartHubSummary{ID: starthubID},
+			Title:       req.Title,
+			Description: req.Description,
+			Skills:      req.Skills,
+			Remote:      req.Remote,
+			Location:    req.Location,
+			Commitment:  req.Commitment,
+		})
+		if err != nil {
+			return dbError(err, "Starthub not found")
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(role)
+	}
+}
+
+// CloseOpenRole - Stops open role :roleId from taking applications, the ones
+// already sent can still be decided
+func CloseOpenRole(starthubs repository.StartHubRepository, openRoles repository.OpenRoleRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+
+		if err := requireTeamRole(c, starthubs, starthubID, models.EditorRoles...); err != nil {
+			return err
+		}
+
+		if err := openRoles.CloseRole(c.Context(), starthubID, c.Params("roleId")); err != nil {
+			return dbError(err, "Open role not found")
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Open role closed",
+		})
+	}
+}
+
+// ApplyToOpenRole - A collaborator applies to open role :roleId
+func ApplyToOpenRole(openRoles repository.OpenRoleRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		applicantID := c.Locals("user_id").(string)
+
+		var req models.ApplicationRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+
+		req.Name = strings.TrimSpace(req.Name)
+		req.URL = strings.TrimSpace(req.URL)
+		req.Message = strings.TrimSpace(req.Message)
+
+		var fields []apperrors.FieldError
+		if req.Name == "" || utf8.RuneCountInString(req.Name) > maxApplicantName {
+			fields = append(fields, apperrors.FieldError{Field: "name", Message: fmt.Sprintf("Name is required and can be at most %d characters", maxApplicantName)})
+		}
+		if req.URL != "" && !isValidURL(req.URL) {
+			fields = append(fields, apperrors.FieldError{Field: "url", Message: "URL must be a valid http or https address"})
+		}
+		if utf8.RuneCountInString(req.Message) > maxApplicationMessage {
+			fields = append(fields, apperrors.FieldError{Field: "message", Message: fmt.Sprintf("Message can be at most %d characters", maxApplicationMessage)})
+		}
+		if len(fields) > 0 {
+			return apperrors.Validation(fields...)
+		}
+
+		application, err := openRoles.Apply(c.Context(), models.Application{
+			Role:        models.OpenRole{ID: c.Params("roleId")},
+			ApplicantID: applicantID,
+			Name:        req.Name,
+			URL:         req.URL,
+			Message:     req.Message,
+		})
+		if err != nil {
+			return dbError(err, "Open role not found or no longer taking applications")
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(application)
+	}
+}
+
+// GetMyApplications - Lists the applications of the signed-in collaborator, newest first
+func GetMyApplications(openRoles repository.OpenRoleRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		applicantID := c.Locals("user_id").(string)
+
+		applications, err := openRoles.ListMyApplications(c.Context(), applicantID)
+		if err != nil {
+			return apperrors.Internal("Could not get applications", err)
+		}
+
+		return c.JSON(applications)
+	}
+}
+
+// WithdrawApplication - The applicant withdraws pending application :applicationId
+func WithdrawApplication(openRoles repository.OpenRoleRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		applicantID := c.Locals("user_id").(string)
+
+		if err := openRoles.Withdraw(c.Context(), applicantID, c.Params("applicationId")); err != nil {
+			return dbError(err, "No pending application found")
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Application withdrawn",
+		})
+	}
+}
+
+// GetApplications - Lists the applications to the roles of starthub :id,
+// optionally only some comma-separated statuses like ?status=pending
+func GetApplications(starthubs repository.StartHubRepository, openRoles repository.OpenRoleRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+
+		statuses := []string{}
+		allowed := []string{models.ApplicationPending, models.ApplicationAccepted, models.ApplicationRejected, models.ApplicationWithdrawn}
+		for _, status := range strings.Split(c.Query("status"), ",") {
+			status = strings.TrimSpace(status)
+			if status == "" {
+				continue
+			}
+			if !slices.Contains(allowed, status) {
+				return apperrors.BadRequest("Status must be one of " + strings.Join(allowed, ", "))
+			}
+			statuses = append(statuses, status)
+		}
+
+		if err := requireTeamRole(c, starthubs, starthubID, models.EditorRoles...); err != nil {
+			return err
+		}
+
+		applications, err := openRoles.ListApplications(c.Context(), starthubID, statuses)
+		if err != nil {
+			return apperrors.Internal("Could not get applications", err)
+		}
+
+		return c.JSON(applications)
+	}
+}
+
+// AcceptApplication - The team of :id accepts an application, which adds the
+// applicant as an external collaborator
+func AcceptApplication(starthubs repository.StartHubRepository, openRoles repository.OpenRoleRepository) fiber.Handler {
+	return decideApplication(starthubs, openRoles, models.ApplicationAccepted)
+}
+
+// RejectApplication - The team of :id rejects an application
+func RejectApplication(starthubs repository.StartHubRepository, openRoles repository.OpenRoleRepository) fiber.Handler {
+	return decideApplication(starthubs, openRoles, models.ApplicationRejected)
+}
+
+func decideApplication(starthubs repository.StartHubRepository, openRoles repository.OpenRoleRepository, status string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+
+		if err := requireTeamRole(c, starthubs, starthubID, models.EditorRoles...); err != nil {
+			return err
+		}
+
+		application, err := openRoles.Decide(c.Context(), starthubID, c.Params("applicationId"), status)
+		if err != nil {
+			return dbError(err, "No pending application found")
+		}
+
+		return c.JSON(application)
+	}
+}
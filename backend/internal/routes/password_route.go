@@ -0,0 +1,186 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/mail"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+	"github.com/ecetinerdem/starthub-backend/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password resets
+const (
+	passwordResetTTL      = time.Hour
+	passwordResetInterval = time.Minute // between two reset emails to one user
+	mailTimeout           = 15 * time.Second
+)
+
+// validPassword checks a new password the way sign-up does
+func validPassword(field, password string) error {
+	if len(password) < models.MinPasswordLength {
+		return apperrors.Field(field, fmt.Sprintf("Password must be at least %d characters long", models.MinPasswordLength))
+	}
+	if len(password) > models.MaxPasswordBytes {
+		return apperrors.Field(field, fmt.Sprintf("Password can be at most %d bytes long", models.MaxPasswordBytes))
+	}
+	return nil
+}
+
+// sendMail sends an email the request doesn't depend on, failures are only logged
+func sendMail(c *fiber.Ctx, mailer mail.Mailer, msg mail.Message) {
+	ctx, cancel := context.WithTimeout(c.UserContext(), mailTimeout)
+	defer cancel()
+
+	if err := mailer.Send(ctx, msg); err != nil {
+		log.Printf("❌ Could not send %q email: %v", msg.Subject, err)
+	}
+}
+
+// ChangePassword - The signed-in user sets a new password, which signs out
+// every other device
+func ChangePassword(users repository.UserRepository, mailer mail.Mailer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("user_id").(string)
+		sessionID := c.Locals("session_id").(string)
+
+		var req models.ChangePasswordRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+		if err := validPassword("new_password", req.NewPassword); err != nil {
+			return err
+		}
+
+		// Step 1: Whoever holds the access token must also know the password
+		user, err := users.GetByID(c.Context(), userID)
+		if err != nil {
+			return dbError(err, "User not found")
+		}
+		if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)) != nil {
+			return apperrors.Field("current_password", "Current password is incorrect")
+		}
+
+		// Step 2: Store the new one and sign out the other devices
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+		if err != nil {
+			return apperrors.Internal("Could not process password", err)
+		}
+		if err := users.ChangePassword(c.Context(), userID, string(hashedPassword), sessionID); err != nil {
+			return dbError(err, "User not found")
+		}
+
+		sendMail(c, mailer, passwordChangedEmail(user.Email))
+
+		return c.JSON(fiber.Map{
+			"message": "Password changed, your other devices were signed out",
+		})
+	}
+}
+
+// ForgotPassword - Emails a single-use reset token. The answer is the same
+// whether the email has an account or not, and it is sent before the account
+// is looked up, so neither it nor how long it takes can be used to find accounts.
+func ForgotPassword(users repository.UserRepository, mailer mail.Mailer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req models.ForgotPasswordRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+
+		req.Email = strings.TrimSpace(req.Email)
+		if req.Email == "" {
+			return apperrors.Field("email", "Email is required")
+		}
+
+		go sendPasswordReset(users, mailer, strings.Clone(req.Email))
+
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"message": "If an account exists for this email, a reset link is on its way",
+		})
+	}
+}
+
+// sendPasswordReset emails a reset token when the email has an account and no
+// reset was emailed to it in the last passwordResetInterval. It runs after the
+// request was answered, so failures are only logged.
+func sendPasswordReset(users repository.UserRepository, mailer mail.Mailer, email string) {
+	ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+	defer cancel()
+
+	user, err := users.GetByEmail(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Could not start password reset: %v", err)
+		return
+	}
+
+	token, hash, err := utils.GenerateSecret()
+	if err != nil {
+		log.Printf("❌ Could not start password reset: %v", err)
+		return
+	}
+
+	err = users.CreatePasswordReset(ctx, user.ID, hash, time.Now().Add(passwordResetTTL), passwordResetInterval)
+	var throttled *repository.ThrottledError
+	if errors.As(err, &throttled) {
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Could not start password reset: %v", err)
+		return
+	}
+
+	msg := passwordResetEmail(user.Email, token, passwordResetTTL)
+	if err := mailer.Send(ctx, msg); err != nil {
+		log.Printf("❌ Could not send %q email: %v", msg.Subject, err)
+	}
+}
+
+// ResetPassword - Sets a new password with an emailed reset token. The token
+// works once and every session of the account is signed out.
+func ResetPassword(users repository.UserRepository, mailer mail.Mailer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req models.ResetPasswordRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+
+		req.Token = strings.TrimSpace(req.Token)
+		if req.Token == "" {
+			return apperrors.Field("token", "Reset token is required")
+		}
+		if err := validPassword("password", req.Password); err != nil {
+			return err
+		}
+
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return apperrors.Internal("Could not process password", err)
+		}
+
+		user, err := users.ResetPassword(c.Context(), utils.HashToken(req.Token), string(hashedPassword))
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperrors.Field("token", "This reset link is invalid, was already used or has expired")
+		}
+		if err != nil {
+			return apperrors.Internal("Could not reset password", err)
+		}
+
+		sendMail(c, mailer, passwordChangedEmail(user.Email))
+
+		return c.JSON(fiber.Map{
+			"message": "Password reset, sign in with your new password",
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package routes
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxPipelineNotes is the longest note an investor can keep on a starthub, in characters
+const maxPipelineNotes = 5000
+
+// GetPipeline - Lists the starthubs the investor tracks, optionally only some
+// comma-separated stages like ?stage=contacted,due_diligence
+func GetPipeline(pipeline repository.PipelineRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		investorID := c.Locals("user_id").(string)
+
+		var stages []string
+		for _, stage := range strings.Split(c.Query("stage"), ",") {
+			stage = strings.TrimSpace(stage)
+			if stage == "" {
+				continue
+			}
+			if !slices.Contains(models.PipelineStages, stage) {
+				return apperrors.BadRequest("Stage must be one of " + strings.Join(models.PipelineStages, ", "))
+			}
+			stages = append(stages, stage)
+		}
+
+		entries, err := pipeline.List(c.Context(), investorID, stages)
+		if err != nil {
+			return apperrors.Internal("Could not get pipeline", err)
+		}
+
+		return c.JSON(entries)
+	}
+}
+
+// SavePipelineEntry - Tracks starthub :starthubId or moves it to another stage.
+// New entries start out as watching, a stage or notes left out of the body stay
+// as they are.
+func SavePipelineEntry(pipeline repository.PipelineRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		investorID := c.Locals("user_id").(string)
+		starthubID := c.Params("starthubId")
+
+		var req models.PipelineRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+
+		if req.Notes != nil {
+			notes := strings.TrimSpace(*req.Notes)
+			req.Notes = &notes
+		}
+		if req.Stage != nil && !slices.Contains(models.PipelineStages, *req.Stage) {
+			return apperrors.Field("stage", "Stage must be one of "+strings.Join(models.PipelineStages, ", "))
+		}
+		if req.Notes != nil && utf8.RuneCountInString(*req.Notes) > maxPipelineNotes {
+			return apperrors.Field("notes", fmt.Sprintf("Notes can be at most %d characters", maxPipelineNotes))
+		}
+
+		entry, created, err := pipeline.Save(c.Context(), investorID, starthubID, req.Stage, req.Notes)
+		if err != nil {
+			return dbError(err, "Starthub not found")
+		}
+
+		if created {
+			c.Status(fiber.StatusCreated)
+		}
+		return c.JSON(entry)
+	}
+}
+
+// DeletePipelineEntry - Stops tracking starthub :starthubId
+func DeletePipelineEntry(pipeline repository.PipelineRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		investorID := c.Locals("user_id").(string)
+		starthubID := c.Params("starthubId")
+
+		if err := pipeline.Delete(c.Context(), investorID, starthubID); err != nil {
+			return dbError(err, "Starthub is not in your pipeline")
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Removed from pipeline",
+		})
+	}
+}
+
+// GetInterestSummary - Shows the team of starthub :id how many investors are
+// interested, without revealing who they are or their stage
+func GetInterestSummary(starthubs repository.StartHubRepository, pipeline repository.PipelineRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+
+		if err := requireTeamRole(c, starthubs, starthubID, models.MemberRoles...); err != nil {
+			return err
+		}
+
+		count, err := pipeline.InterestCount(c.Context(), starthubID)
+		if err != nil {
+			return apperrors.Internal("Could not get investor interest", err)
+		}
+
+		return c.JSON(models.InterestSummary{
+			StartHubID:          starthubID,
+			InterestedInvestors: count,
+		})
+	}
+}
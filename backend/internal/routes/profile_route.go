@@ -0,0 +1,204 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Limits of profile fields, in characters
+const (
+	maxDisplayName     = 100
+	maxBio             = 2000
+	maxProfileLocation = 200
+	maxProfileLinks    = 5
+)
+
+// profilePatch is a JSON Merge Patch (RFC 7396) of the signed-in user. A field
+// that is left out keeps its value and a null resets it.
+type profilePatch map[string]json.RawMessage
+
+// apply merges the patch into the profile and settings
+func (p profilePatch) apply(profile *models.Profile, settings *models.AccountSettings) error {
+	var fields []apperrors.FieldError
+	invalid := func(field, message string) {
+		fields = append(fields, apperrors.FieldError{Field: field, Message: message})
+	}
+
+	// Sorted so field errors come back in a stable order
+	for _, field := range slices.Sorted(maps.Keys(p)) {
+		raw := p[field]
+		var err error
+		switch field {
+		case "display_name":
+			err = setField(raw, &profile.DisplayName)
+		case "bio":
+			err = setField(raw, &profile.Bio)
+		case "avatar_url":
+			err = setField(raw, &profile.AvatarURL)
+		case "location":
+			err = setField(raw, &profile.Location)
+		case "links":
+			err = setField(raw, &profile.Links)
+		case "public_profile":
+			err = setField(raw, &settings.PublicProfile)
+		case "id", "email", "role", "created_at":
+			invalid(field, "Field can't be changed")
+			continue
+		default:
+			invalid(field, "Unknown field")
+			continue
+		}
+
+		if err != nil {
+			invalid(field, "Invalid value")
+		}
+	}
+
+	if len(fields) > 0 {
+		return apperrors.Validation(fields...)
+	}
+	return nil
+}
+
+// validateProfile trims a profile and checks it the way it will be stored
+func validateProfile(profile *models.Profile) error {
+	profile.DisplayName = strings.TrimSpace(profile.DisplayName)
+	profile.Bio = strings.TrimSpace(profile.Bio)
+	profile.AvatarURL = strings.TrimSpace(profile.AvatarURL)
+	profile.Location = strings.TrimSpace(profile.Location)
+
+	links := []string{}
+	for _, link := range profile.Links {
+		if link = strings.TrimSpace(link); link != "" && !slices.Contains(links, link) {
+			links = append(links, link)
+		}
+	}
+	profile.Links = links
+
+	var fields []apperrors.FieldError
+	invalid := func(field, message string) {
+		fields = append(fields, apperrors.FieldError{Field: field, Message: message})
+	}
+
+	if utf8.RuneCountInString(profile.DisplayName) > maxDisplayName {
+		invalid("display_name", fmt.Sprintf("Display name can be at most %d characters", maxDisplayName))
+	}
+	if utf8.RuneCountInString(profile.Bio) > maxBio {
+		invalid("bio", fmt.Sprintf("Bio can be at most %d characters", maxBio))
+	}
+	if profile.AvatarURL != "" && !isValidURL(profile.AvatarURL) {
+		invalid("avatar_url", "Avatar URL must be a valid http or https address")
+	}
+	if utf8.RuneCountInString(profile.Location) > maxProfileLocation {
+		invalid("location", fmt.Sprintf("Location can be at most %d characters", maxProfileLocation))
+	}
+	if len(links) > maxProfileLinks || slices.ContainsFunc(links, func(link string) bool { return !isValidURL(link) }) {
+		invalid("links", fmt.Sprintf("Up to %d links, each a valid http or https address", maxProfileLinks))
+	}
+
+	if len(fields) > 0 {
+		return apperrors.Validation(fields...)
+	}
+	return nil
+}
+
+// GetMe - The account of the signed-in user with their profile and settings
+func GetMe(users repository.UserRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("user_id").(string)
+
+		user, err := users.GetByID(c.Context(), userID)
+		if err != nil {
+			return dbError(err, "User not found")
+		}
+
+		return c.JSON(models.NewUserResponse(user))
+	}
+}
+
+// PatchMe - Changes the profile and settings of the signed-in user, following
+// JSON Merge Patch like PatchStartHub
+func PatchMe(users repository.UserRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("user_id").(string)
+
+		patch, err := readMergePatch(c)
+		if err != nil {
+			return err
+		}
+
+		user, err := users.GetByID(c.Context(), userID)
+		if err != nil {
+			return dbError(err, "User not found")
+		}
+
+		if err := profilePatch(patch).apply(&user.Profile, &user.Settings); err != nil {
+			return err
+		}
+		if err := validateProfile(&user.Profile); err != nil {
+			return err
+		}
+
+		user, err = users.UpdateProfile(c.Context(), userID, user.Profile, user.Settings)
+		if err != nil {
+			return dbError(err, "User not found")
+		}
+
+		return c.JSON(models.NewUserResponse(user))
+	}
+}
+
+// GetMyStartHubs - The starthubs the signed-in user is on the team of, with their role
+func GetMyStartHubs(members repository.MemberRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("user_id").(string)
+
+		starthubs, err := members.StartHubsOf(c.Context(), userID, nil)
+		if err != nil {
+			return apperrors.Internal("Could not get your starthubs", err)
+		}
+
+		return c.JSON(starthubs)
+	}
+}
+
+// GetPublicProfile - The public profile of user :id and the starthubs they own.
+// Hidden profiles are reported as not found.
+func GetPublicProfile(users repository.UserRepository, members repository.MemberRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, err := users.GetByID(c.Context(), c.Params("id"))
+		if err != nil {
+			return dbError(err, "User not found")
+		}
+		if !user.Settings.PublicProfile {
+			return apperrors.NotFound("User not found")
+		}
+
+		owned, err := members.StartHubsOf(c.Context(), user.ID, []string{models.MemberOwner})
+		if err != nil {
+			return apperrors.Internal("Could not get starthubs", err)
+		}
+
+		profile := models.PublicProfile{
+			ID:        user.ID,
+			Role:      user.Role,
+			Profile:   models.NewUserResponse(user).Profile,
+			StartHubs: []models.StartHubSummary{},
+			CreatedAt: user.CreatedAt,
+		}
+		for _, t := range owned {
+			profile.StartHubs = append(profile.StartHubs, t.StartHub)
+		}
+
+		return c.JSON(profile)
+	}
+}
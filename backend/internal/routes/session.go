@@ -0,0 +1,130 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+	"github.com/ecetinerdem/starthub-backend/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// startSession stores a new session for the user and returns the token pair for it
+func startSession(c *fiber.Ctx, users repository.UserRepository, user models.User) (models.AuthResponse, error) {
+	secret, hash, err := utils.GenerateSecret()
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+
+	sessionID, err := users.CreateSession(c.Context(), models.Session{
+		UserID:           user.ID,
+		RefreshTokenHash: hash,
+		UserAgent:        c.Get(fiber.HeaderUserAgent),
+		IPAddress:        c.IP(),
+		ExpiresAt:        time.Now().Add(utils.REFRESH_TOKEN_DURATION),
+	})
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+
+	token, err := utils.GenerateJWT(user, sessionID)
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+
+	return models.AuthResponse{
+		User:         models.NewUserResponse(user),
+		Token:        token,
+		RefreshToken: utils.FormatRefreshToken(sessionID, secret),
+		ExpiresIn:    int64(utils.TOKEN_DURATION.Seconds()),
+	}, nil
+}
+
+// RefreshSession rotates a refresh token and issues a new access token.
+// Presenting a refresh token that was already rotated away means it leaked,
+// so the whole session is revoked. Any other wrong secret is only refused, as
+// the session id alone can be read from an access token.
+func RefreshSession(users repository.UserRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var request models.RefreshRequest
+
+		if err := c.BodyParser(&request); err != nil || request.RefreshToken == "" {
+			return apperrors.BadRequest("Refresh token is required")
+		}
+
+		sessionID, secret, ok := utils.ParseRefreshToken(request.RefreshToken)
+		if !ok {
+			return apperrors.Unauthorized("Invalid refresh token")
+		}
+
+		// Rotate: the presented token stops working as soon as this returns
+		newSecret, newHash, err := utils.GenerateSecret()
+		if err != nil {
+			return apperrors.Internal("Could not refresh session", err)
+		}
+
+		matches := func(storedHash string) bool {
+			return utils.TokenHashMatches(secret, storedHash)
+		}
+
+		user, err := users.RotateSession(c.Context(), sessionID, matches, newHash)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return apperrors.Unauthorized("Invalid refresh token")
+		case errors.Is(err, repository.ErrSessionInactive):
+			return apperrors.Unauthorized("Session has expired or was revoked")
+		case errors.Is(err, repository.ErrTokenReuse):
+			log.Printf("⚠️  Refresh token reuse detected for session %s, revoked it", sessionID)
+			return apperrors.Unauthorized("Invalid refresh token")
+		case err != nil:
+			return apperrors.Internal("Could not refresh session", err)
+		}
+
+		token, err := utils.GenerateJWT(user, sessionID)
+		if err != nil {
+			return apperrors.Internal("Could not generate authentication token", err)
+		}
+
+		return c.JSON(models.AuthResponse{
+			User:         models.NewUserResponse(user),
+			Token:        token,
+			RefreshToken: utils.FormatRefreshToken(sessionID, newSecret),
+			ExpiresIn:    int64(utils.TOKEN_DURATION.Seconds()),
+		})
+	}
+}
+
+// Logout revokes the session the current access token belongs to
+func Logout(users repository.UserRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		sessionID := c.Locals("session_id").(string)
+
+		if err := users.RevokeSession(c.Context(), sessionID, "logout"); err != nil {
+			return apperrors.Internal("Could not log out", err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Logged out",
+		})
+	}
+}
+
+// LogoutAll revokes every active session of the current user, on every device
+func LogoutAll(users repository.UserRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("user_id").(string)
+
+		revoked, err := users.RevokeUserSessions(c.Context(), userID, "logout_all")
+		if err != nil {
+			return apperrors.Internal("Could not log out", err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message":          "Logged out of all sessions",
+			"revoked_sessions": revoked,
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package routes
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/images"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+	"github.com/ecetinerdem/starthub-backend/internal/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+// uploadImageSize is the thumbnail size image_url points at
+const uploadImageSize = "medium"
+
+// UploadStartHubImage - Stores an uploaded logo or cover from the "image" form
+// field in every thumbnail size and points image_url at the medium one
+func UploadStartHubImage(starthubs repository.StartHubRepository, blobs storage.BlobStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+		userID := c.Locals("user_id").(string)
+		ctx := c.Context()
+
+		// Step 1: Only owners and editors can change the image
+		if err := requireTeamRole(c, starthubs, starthubID, models.EditorRoles...); err != nil {
+			return err
+		}
+
+		// Step 2: Read the uploaded file
+		file, err := c.FormFile("image")
+		if err != nil {
+			return apperrors.Field("image", "An image file is required")
+		}
+		if file.Size > images.MaxUploadBytes {
+			return apperrors.PayloadTooLarge(fmt.Sprintf("Images can be at most %d MB", images.MaxUploadBytes>>20))
+		}
+
+		f, err := file.Open()
+		if err != nil {
+			return apperrors.Internal("Could not read image", err)
+		}
+		data, err := io.ReadAll(io.LimitReader(f, images.MaxUploadBytes))
+		f.Close()
+		if err != nil {
+			return apperrors.Internal("Could not read image", err)
+		}
+
+		// Step 3: Check the type and make the thumbnails
+		thumbnails, err := images.Thumbnails(data)
+		if errors.Is(err, images.ErrUnsupportedType) {
+			return apperrors.UnsupportedMediaType(err.Error())
+		}
+		if errors.Is(err, images.ErrTooLarge) {
+			return apperrors.Field("image", err.Error())
+		}
+		if err != nil {
+			return apperrors.Internal("Could not process image", err)
+		}
+
+		// Step 4: Store every size under a new folder, so cached copies of an
+		// older image are never served for the new one
+		folder := make([]byte, 8)
+		if _, err := rand.Read(folder); err != nil {
+			return apperrors.Internal("Could not upload image", err)
+		}
+		prefix := fmt.Sprintf("starthubs/%s/%s", starthubID, hex.EncodeToString(folder))
+
+		urls := make(map[string]string, len(thumbnails))
+		var stored []string
+		for _, thumb := range thumbnails {
+			key := prefix + "/" + thumb.Size + "." + thumb.Ext
+			if err := blobs.Put(ctx, key, thumb.ContentType, thumb.Data); err != nil {
+				deleteBlobs(ctx, blobs, stored)
+				return apperrors.Internal("Could not store image", err)
+			}
+			stored = append(stored, key)
+			urls[thumb.Size] = blobs.URL(key)
+		}
+
+		// Step 5: Point the starthub at the new image
+		previous, err := starthubs.SetImageURL(ctx, starthubID, userID, urls[uploadImageSize])
+		if err != nil {
+			deleteBlobs(ctx, blobs, stored)
+			return dbError(err, "Starthub not found")
+		}
+
+		// Step 6: Remove the upload it replaced, stock photos and placeholders aren't ours
+		if key, ok := blobs.Key(previous); ok {
+			dir, ext := path.Dir(key), path.Ext(key)
+			var old []string
+			for _, size := range images.Sizes {
+				old = append(old, dir+"/"+size.Name+ext)
+			}
+			deleteBlobs(ctx, blobs, old)
+		}
+
+		return c.JSON(fiber.Map{
+			"image_url": urls[uploadImageSize],
+			"images":    urls,
+		})
+	}
+}
+
+// deleteBlobs removes files that are no longer used. Failures only leave an
+// orphaned file behind, so they are logged instead of failing the request.
+func deleteBlobs(ctx context.Context, blobs storage.BlobStore, keys []string) {
+	for _, key := range keys {
+		if err := blobs.Delete(ctx, key); err != nil {
+			log.Printf("❌ Could not delete %s: %v", key, err)
+		}
+	}
+}
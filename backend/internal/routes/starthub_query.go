@@ -0,0 +1,115 @@
+package routes
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// parseStartHubFilter reads ?category=a,b&location=&min_team_size=&max_team_size=
+func parseStartHubFilter(c *fiber.Ctx) (repository.StartHubFilter, error) {
+	var f repository.StartHubFilter
+
+	for _, name := range strings.Split(c.Query("category"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			f.Categories = append(f.Categories, strings.ToLower(name))
+		}
+	}
+
+	f.Location = strings.TrimSpace(c.Query("location"))
+
+	for _, bound := range []struct {
+		param  string
+		target **int
+	}{
+		{"min_team_size", &f.MinTeamSize},
+		{"max_team_size", &f.MaxTeamSize},
+	} {
+		raw := c.Query(bound.param)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			return f, fmt.Errorf("%s must be a non-negative number", bound.param)
+		}
+		*bound.target = &value
+	}
+
+	if f.MinTeamSize != nil && f.MaxTeamSize != nil && *f.MinTeamSize > *f.MaxTeamSize {
+		return f, errors.New("min_team_size cannot be greater than max_team_size")
+	}
+
+	return f, nil
+}
+
+// pageCursor is the position of the last row of a page. It is sent to clients
+// base64 encoded so they treat it as opaque.
+type pageCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+func (p pageCursor) encode() string {
+	data, _ := json.Marshal(p)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(raw string) (pageCursor, error) {
+	var p pageCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return p, err
+	}
+	if p.ID == "" {
+		return p, errors.New("cursor has no id")
+	}
+
+	return p, nil
+}
+
+// parseLimit reads ?limit= and clamps it to the allowed page size
+func parseLimit(c *fiber.Ctx) (int, error) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return defaultPageSize, nil
+	}
+
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 {
+		return 0, errors.New("limit must be a positive number")
+	}
+
+	return min(limit, maxPageSize), nil
+}
+
+// parseOffset reads ?offset= for endpoints that page by position
+func parseOffset(c *fiber.Ctx) (int, error) {
+	raw := c.Query("offset")
+	if raw == "" {
+		return 0, nil
+	}
+
+	offset, err := strconv.Atoi(raw)
+	if err != nil || offset < 0 {
+		return 0, errors.New("offset must be a non-negative number")
+	}
+
+	return offset, nil
+}
@@ -2,184 +2,104 @@ package routes
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
+	"errors"
 	"log"
-	"net/http"
-	"os"
 	"strings"
+	"time"
 
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/images"
 	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
 	"github.com/gofiber/fiber/v2"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// getImageFromPexels fetches an image URL from Pexels based on category
-func getImageFromPexels(category string) string {
-	// Get API key from environment
-	apiKey := os.Getenv("PEXELS_API_KEY")
-	if apiKey == "" {
-		log.Printf("⚠️  PEXELS_API_KEY not found in environment")
-		return "" // Return empty string if no API key
-	}
-
-	// Clean up the category for search (remove spaces, make lowercase)
-	searchTerm := strings.ToLower(strings.TrimSpace(category))
-	if searchTerm == "" {
-		searchTerm = "startup" // Default fallback
-	}
-
-	// Build the API URL
-	url := fmt.Sprintf("https://api.pexels.com/v1/search?query=%s&per_page=1", searchTerm)
-
-	// Create the request
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		log.Printf("❌ Could not create Pexels request: %v", err)
-		return ""
-	}
-
-	// Add the authorization header
-	req.Header.Add("Authorization", apiKey)
-
-	// Make the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Printf("❌ Pexels API request failed: %v", err)
-		return ""
-	}
-	defer resp.Body.Close()
-
-	// Check if request was successful
-	if resp.StatusCode != 200 {
-		log.Printf("❌ Pexels API returned status %d", resp.StatusCode)
-		return ""
-	}
+// imageLookupTimeout bounds how long creating a starthub waits for its image
+const imageLookupTimeout = 3 * time.Second
 
-	// Read the response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("❌ Could not read Pexels response: %v", err)
-		return ""
-	}
+// GetAllStarthubs - Gets one page of starthubs with images, sorted and filtered.
+// Pages are keyset based: pass the next_cursor of a response to get the page after it.
+func GetAllStarthubs(starthubs repository.StartHubRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Step 1: Read paging, sorting and filter parameters
+		limit, err := parseLimit(c)
+		if err != nil {
+			return apperrors.BadRequest(err.Error())
+		}
 
-	// Parse the JSON response
-	var pexelsResp models.PexelsResponse
-	err = json.Unmarshal(body, &pexelsResp)
-	if err != nil {
-		log.Printf("❌ Could not parse Pexels response: %v", err)
-		return ""
-	}
+		sortName := c.Query("sort", repository.SortNewest)
+		if !repository.ValidSort(sortName) {
+			return apperrors.BadRequest("Sort must be one of 'newest', 'name' or 'team_size'")
+		}
 
-	// Check if we got any photos
-	if len(pexelsResp.Photos) == 0 {
-		log.Printf("⚠️  No photos found for category: %s", category)
-		return ""
-	}
+		filter, err := parseStartHubFilter(c)
+		if err != nil {
+			return apperrors.BadRequest(err.Error())
+		}
 
-	// Return the medium image URL
-	imageURL := pexelsResp.Photos[0].Src.Medium
-	log.Printf("✅ Got image from Pexels for '%s': %s", category, imageURL)
-	return imageURL
-}
+		opts := repository.ListOptions{
+			Filter: filter,
+			Sort:   sortName,
+			Limit:  limit + 1, // One extra row tells whether another page exists
+		}
 
-// GetAllStarthubs - Gets all starthubs from database with images
-func GetAllStarthubs(db *pgxpool.Pool) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		// Updated query to include image_url
-		query := "SELECT id, name, description, location, team_size, url, email, join_date, image_url FROM starthubs"
+		// Step 2: Continue after the cursor if one was sent
+		if raw := c.Query("cursor"); raw != "" {
+			cursor, err := decodeCursor(raw)
+			if err != nil || cursor.Sort != sortName {
+				return apperrors.BadRequest("Invalid cursor")
+			}
+			opts.After = &repository.Cursor{Value: cursor.Value, ID: cursor.ID}
+		}
 
-		// Execute the query
-		rows, err := db.Query(context.Background(), query)
+		// Step 3: Get the page along with the number of all matches
+		page, total, err := starthubs.List(c.Context(), opts)
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			return apperrors.BadRequest("Invalid cursor")
+		}
 		if err != nil {
-			log.Printf("❌ Database error: %v", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Could not get starthubs from database",
-			})
-		}
-		defer rows.Close()
-
-		// Create a slice to hold our results
-		var starthubs []models.StartHub
-
-		// Loop through each row and scan the data
-		for rows.Next() {
-			var s models.StartHub
-
-			err := rows.Scan(
-				&s.ID,
-				&s.Name,
-				&s.Description,
-				&s.Location,
-				&s.TeamSize,
-				&s.URL,
-				&s.Email,
-				&s.JoinDate,
-				&s.ImageURL, // Added image_url field
-			)
-
-			if err != nil {
-				log.Printf("❌ Could not read row: %v", err)
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Could not read data from database",
-				})
-			}
+			return apperrors.Internal("Could not get starthubs from database", err)
+		}
 
-			// Add this starthub to our list
-			starthubs = append(starthubs, s)
+		// Step 4: Trim the extra row and point the cursor at the last returned one
+		var nextCursor *string
+		if len(page) > limit {
+			page = page[:limit]
+			last := page[limit-1]
+			cursor := pageCursor{Sort: sortName, Value: repository.SortValue(sortName, last), ID: last.ID}.encode()
+			nextCursor = &cursor
 		}
 
-		// Return the results as JSON
-		return c.JSON(starthubs)
+		// Return the page with its paging info
+		return c.JSON(fiber.Map{
+			"data":        page,
+			"next_cursor": nextCursor,
+			"total":       total,
+		})
 	}
 }
 
 // GetStartHubByID - Gets one starthub with ID including image
-func GetStartHubByID(db *pgxpool.Pool) fiber.Handler {
+func GetStartHubByID(starthubs repository.StartHubRepository) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Get the ID from context parameters
 		id := c.Params("id")
 
 		// Basic ID check
 		if id == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "ID is required",
-			})
-		}
-
-		query := "SELECT id, name, description, location, team_size, url, email, join_date, image_url FROM starthubs WHERE id = $1"
-
-		// Initialize a starthub model to variable
-		var s models.StartHub
-
-		// Execute query and scan results
-		err := db.QueryRow(context.Background(), query, id).Scan(
-			&s.ID,
-			&s.Name,
-			&s.Description,
-			&s.Location,
-			&s.TeamSize,
-			&s.URL,
-			&s.Email,
-			&s.JoinDate,
-			&s.ImageURL, // Added image_url field
-		)
-
-		// Handle errors
-		if err != nil {
-			log.Printf("❌ Database error for ID %s: %v", id, err)
+			return apperrors.BadRequest("ID is required")
+		}
 
-			if err.Error() == "no rows in result set" {
-				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-					"error": "Starthub not found",
-				})
-			}
+		// Get the starthub with its categories and collaborators, a missing one becomes a 404
+		s, err := starthubs.Get(c.Context(), id)
+		if err != nil {
+			return dbError(err, "Starthub not found")
+		}
 
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Could not get starthub from database",
-			})
+		// Clients that already have this version get an empty 304
+		setETag(c, s.Version)
+		if notModified(c, s.Version) {
+			return c.SendStatus(fiber.StatusNotModified)
 		}
 
 		// Return the result as JSON
@@ -187,63 +107,56 @@ func GetStartHubByID(db *pgxpool.Pool) fiber.Handler {
 	}
 }
 
-func GetStartHubsBySearchTerm(db *pgxpool.Pool) fiber.Handler {
+// GetStartHubsBySearchTerm - Full-text search over name, categories, description and
+// location, best matches first. Accepts the same filters as GetAllStarthubs.
+func GetStartHubsBySearchTerm(starthubs repository.StartHubRepository) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		searchTerm := c.Query("name")
+		// "name" is the original parameter, kept working for existing clients
+		searchTerm := c.Query("q", c.Query("name"))
 
-		if searchTerm == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Search term 'name' is required.",
-			})
+		if strings.TrimSpace(searchTerm) == "" {
+			return apperrors.BadRequest("Search term 'q' is required.")
 		}
 
-		// Updated query to include image_url
-		query := "SELECT id, name, description, location, team_size, url, email, join_date, image_url FROM starthubs WHERE name ILIKE $1"
-		searchPattern := "%" + searchTerm + "%"
+		terms := repository.SearchTerms(searchTerm)
+		if len(terms) == 0 {
+			return apperrors.BadRequest("Search term must contain letters or numbers")
+		}
 
-		rows, err := db.Query(context.Background(), query, searchPattern)
+		limit, err := parseLimit(c)
 		if err != nil {
-			log.Printf("❌ Database error for search '%s': %v", searchTerm, err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Could not search starthubs",
-			})
-		}
-		defer rows.Close()
-
-		var starthubs []models.StartHub
-
-		for rows.Next() {
-			var s models.StartHub
-			err := rows.Scan(
-				&s.ID,
-				&s.Name,
-				&s.Description,
-				&s.Location,
-				&s.TeamSize,
-				&s.URL,
-				&s.Email,
-				&s.JoinDate,
-				&s.ImageURL, // Added image_url field
-			)
-			if err != nil {
-				log.Printf("❌ Row scan error: %v", err)
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Could not process results",
-				})
-			}
-			starthubs = append(starthubs, s)
+			return apperrors.BadRequest(err.Error())
+		}
+		offset, err := parseOffset(c)
+		if err != nil {
+			return apperrors.BadRequest(err.Error())
+		}
+		filter, err := parseStartHubFilter(c)
+		if err != nil {
+			return apperrors.BadRequest(err.Error())
+		}
+
+		results, total, err := starthubs.Search(c.Context(), repository.SearchOptions{
+			Terms:  terms,
+			Filter: filter,
+			Limit:  limit,
+			Offset: offset,
+		})
+		if err != nil {
+			return apperrors.Internal("Could not search starthubs", err)
 		}
 
 		// Always return 200 with consistent structure
 		return c.JSON(fiber.Map{
 			"search_term": searchTerm,
-			"found":       len(starthubs),
-			"results":     starthubs,
+			"found":       len(results),
+			"total":       total,
+			"results":     results,
 		})
 	}
 }
 
-func CreateStartHub(db *pgxpool.Pool) fiber.Handler {
+func CreateStartHub(starthubs repository.StartHubRepository, imageProvider images.Provider) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 
 		userID := c.Locals("user_id").(string)
@@ -253,227 +166,197 @@ func CreateStartHub(db *pgxpool.Pool) fiber.Handler {
 		// Step 2: Parse the JSON from the request body
 		if err := c.BodyParser(&req); err != nil {
 			log.Printf("❌ Could not parse request: %v", err)
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid data in request",
-			})
+			return apperrors.BadRequest("Invalid data in request")
 		}
 
-		// Step 3: Basic validation
-		if req.Name == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Name is required",
-			})
-		}
-		if req.Email == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Email is required",
-			})
+		// Step 3: Validate with the rules PUT and PATCH use too
+		if err := validateStartHub(&req); err != nil {
+			return err
 		}
 
-		// Step 4: Get image from Pexels if categories are provided
-		var imageURL string
+		// Step 4: Find an image for the first category, the provider always falls back to a placeholder
+		query := images.DefaultQuery
 		if len(req.Categories) > 0 && req.Categories[0] != "" {
-			imageURL = getImageFromPexels(req.Categories[0])
+			query = req.Categories[0]
 		}
-		// If no image found or no categories, use a default search
-		if imageURL == "" {
-			imageURL = getImageFromPexels("startup")
-		}
-
-		// Step 5: Start a transaction for multiple table operations
-		tx, err := db.Begin(context.Background())
-		if err != nil {
-			log.Printf("❌ Could not start transaction: %v", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Database transaction error",
-			})
-		}
-		defer tx.Rollback(context.Background()) // Rollback if we don't commit
-
-		// Step 6: Insert the starthub first (now including image_url)
-		var s models.StartHub
-		query := `
-		INSERT INTO starthubs (name, description, location, team_size, url, email, image_url, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, join_date
-		`
-
-		err = tx.QueryRow(
-			context.Background(),
-			query,
-			req.Name,
-			req.Description,
-			req.Location,
-			req.TeamSize,
-			req.URL,
-			req.Email,
-			imageURL,
-			userID, // Add created_by
-		).Scan(&s.ID, &s.JoinDate)
 
+		ctx, cancel := context.WithTimeout(c.UserContext(), imageLookupTimeout)
+		imageURL, err := imageProvider.Image(ctx, query)
+		cancel()
 		if err != nil {
-			log.Printf("❌ Could not create starthub: %v", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Could not save starthub to database",
-			})
-		}
-
-		// Copy the request data to our response struct
-		s.Name = req.Name
-		s.Description = req.Description
-		s.Location = req.Location
-		s.TeamSize = req.TeamSize
-		s.URL = req.URL
-		s.Email = req.Email
-		s.ImageURL = imageURL // Include the image URL in response
-
-		// Step 7: Handle categories if provided (same as before)
-		if len(req.Categories) > 0 {
-			for _, categoryName := range req.Categories {
-				if categoryName == "" {
-					continue // Skip empty category names
-				}
-
-				// First, ensure the category exists (insert if not exists)
-				var categoryID int
-				categoryQuery := `
-				INSERT INTO categories (name) 
-				VALUES ($1) 
-				ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
-				RETURNING id
-				`
-
-				err = tx.QueryRow(context.Background(), categoryQuery, categoryName).Scan(&categoryID)
-				if err != nil {
-					log.Printf("❌ Could not create/get category '%s': %v", categoryName, err)
-					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-						"error": "Could not process categories",
-					})
-				}
-
-				// Then, link the starthub to the category
-				linkQuery := `
-				INSERT INTO starthub_categories (starthub_id, category_id)
-				VALUES ($1, $2)
-				ON CONFLICT (starthub_id, category_id) DO NOTHING
-				`
-
-				_, err = tx.Exec(context.Background(), linkQuery, s.ID, categoryID)
-				if err != nil {
-					log.Printf("❌ Could not link starthub to category: %v", err)
-					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-						"error": "Could not link categories",
-					})
-				}
-			}
-
-			// Add categories to response
-			s.Categories = req.Categories
+			log.Printf("⚠️  No image for '%s': %v", query, err)
 		}
 
-		// Step 8: Commit the transaction
-		err = tx.Commit(context.Background())
+		// Step 5: Save the starthub together with its categories
+		s, err := starthubs.Create(c.Context(), models.StartHub{
+			Name:        req.Name,
+			Description: req.Description,
+			Location:    req.Location,
+			TeamSize:    req.TeamSize,
+			URL:         req.URL,
+			Email:       req.Email,
+			ImageURL:    imageURL,
+			Categories:  req.Categories,
+			CreatedBy:   userID,
+		})
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperrors.Internal("Could not create starthub", err)
+		}
 		if err != nil {
-			log.Printf("❌ Could not commit transaction: %v", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Could not complete starthub creation",
-			})
+			// Duplicate emails become a 409
+			return dbError(err, "Could not create starthub")
 		}
 
-		// Step 9: Return the created starthub with categories and image
+		// Step 6: Return the created starthub with categories and image
+		setETag(c, s.Version)
 		return c.Status(fiber.StatusCreated).JSON(s)
 	}
 }
 
-func UpdateStartHub(db *pgxpool.Pool) fiber.Handler {
+// UpdateStartHub - Replaces every field of a starthub, fields left out are cleared
+// and so are the categories. Use PATCH to change only some fields.
+func UpdateStartHub(starthubs repository.StartHubRepository) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Get starthub ID and user ID
 		starthubID := c.Params("id")
 		userID := c.Locals("user_id").(string)
 
+		// Only overwrite the version the client has seen, when it says which
+		versions, err := ifMatch(c)
+		if err != nil {
+			return err
+		}
+
 		// Parse request
 		var req models.CreateStartHubRequest
 		if err := c.BodyParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
-		}
-
-		// Update only if user is owner and return the updated row
-		query := `
-			UPDATE starthubs 
-			SET name=$1, description=$2, location=$3, team_size=$4, url=$5, email=$6 
-			WHERE id=$7 AND created_by=$8
-			RETURNING id, name, description, location, team_size, url, email, join_date, image_url
-		`
-
-		var s models.StartHub
-		err := db.QueryRow(
-			context.Background(),
-			query,
-			req.Name,
-			req.Description,
-			req.Location,
-			req.TeamSize,
-			req.URL,
-			req.Email,
-			starthubID,
-			userID,
-		).Scan(
-			&s.ID,
-			&s.Name,
-			&s.Description,
-			&s.Location,
-			&s.TeamSize,
-			&s.URL,
-			&s.Email,
-			&s.JoinDate,
-			&s.ImageURL,
-		)
+			return apperrors.BadRequest("Invalid request")
+		}
 
+		// A full replacement has to be a valid starthub on its own
+		if err := validateStartHub(&req); err != nil {
+			return err
+		}
+		if req.Categories == nil {
+			req.Categories = []string{}
+		}
+
+		return saveStartHub(c, starthubs, userID, starthubID, req, versions)
+	}
+}
+
+// PatchStartHub - Changes only the fields in the body, following JSON Merge Patch:
+// fields left out keep their value and null clears a field
+func PatchStartHub(starthubs repository.StartHubRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		starthubID := c.Params("id")
+		userID := c.Locals("user_id").(string)
+
+		// Step 1: The body is a merge patch, sent as such or as plain JSON
+		patch, err := readMergePatch(c)
 		if err != nil {
-			// Handle no rows found (either doesn't exist or user isn't owner)
-			if err.Error() == "no rows in result set" {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-					"error": "Starthub not found or you're not the owner",
-				})
-			}
+			return err
+		}
 
-			log.Printf("❌ Database error during update: %v", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Could not update starthub",
-			})
+		versions, err := ifMatch(c)
+		if err != nil {
+			return err
 		}
 
-		// Return the updated starthub
-		return c.JSON(s)
+		// Step 2: Only the team can see the current state through this route
+		if err := requireTeamRole(c, starthubs, starthubID, models.EditorRoles...); err != nil {
+			return err
+		}
+
+		current, err := starthubs.Get(c.Context(), starthubID)
+		if err != nil {
+			return dbError(err, "Starthub not found")
+		}
+
+		// The patch is merged into this version, so it is the only one it may be
+		// saved over. That also covers clients that sent no If-Match.
+		if !versions.Allow(current.Version) {
+			return dbError(repository.ErrVersionMismatch, "Starthub not found")
+		}
+		versions = repository.Versions{current.Version}
+
+		// Step 3: Merge the patch into the current starthub and validate the result
+		req := models.CreateStartHubRequest{
+			Name:        current.Name,
+			Description: current.Description,
+			Location:    current.Location,
+			TeamSize:    current.TeamSize,
+			URL:         current.URL,
+			Email:       current.Email,
+		}
+		changesCategories, err := startHubPatch(patch).apply(&req)
+		if err != nil {
+			return err
+		}
+		if err := validateStartHub(&req); err != nil {
+			return err
+		}
+
+		// Categories are only touched when the patch has them
+		if !changesCategories {
+			req.Categories = nil
+		}
+
+		return saveStartHub(c, starthubs, userID, starthubID, req, versions)
+	}
+}
+
+// saveStartHub stores the fields of req on a starthub userID may edit, if it is
+// at one of versions, and responds with the result. Nil categories are kept as they are.
+func saveStartHub(c *fiber.Ctx, starthubs repository.StartHubRepository, userID, starthubID string, req models.CreateStartHubRequest, versions repository.Versions) error {
+	s, err := starthubs.Update(c.Context(), userID, models.StartHub{
+		ID:          starthubID,
+		Name:        req.Name,
+		Description: req.Description,
+		Location:    req.Location,
+		TeamSize:    req.TeamSize,
+		URL:         req.URL,
+		Email:       req.Email,
+		Categories:  req.Categories,
+	}, versions)
+	if err != nil {
+		// Handle no rows found (either doesn't exist or user isn't an owner or editor)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperrors.Forbidden("Starthub not found or your team role doesn't allow this").
+				With("required_team_roles", models.EditorRoles)
+		}
+
+		// Duplicate emails become a 409, a changed version a 412
+		return dbError(err, "Starthub not found")
 	}
+
+	// Return the updated starthub with its relations and new ETag
+	setETag(c, s.Version)
+	return c.JSON(s)
 }
 
-func DeleteStartHub(db *pgxpool.Pool) fiber.Handler {
+func DeleteStartHub(starthubs repository.StartHubRepository) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Get starthub ID and user ID
 		starthubID := c.Params("id")
 		userID := c.Locals("user_id").(string)
 
-		// Delete only if user is owner
-		query := "DELETE FROM starthubs WHERE id=$1 AND created_by=$2"
-		result, err := db.Exec(
-			context.Background(),
-			query,
-			starthubID,
-			userID,
-		)
-
+		versions, err := ifMatch(c)
 		if err != nil {
-			log.Printf("❌ Database error during delete: %v", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Could not delete starthub",
-			})
+			return err
 		}
 
-		if result.RowsAffected() == 0 {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Starthub not found or you're not the owner",
-			})
+		// Delete only if user is an owner and, with If-Match, nobody changed it since
+		err = starthubs.Delete(c.Context(), starthubID, userID, versions)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperrors.Forbidden("Starthub not found or your team role doesn't allow this").
+				With("required_team_roles", []string{models.MemberOwner})
+		}
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			return dbError(err, "Starthub not found")
+		}
+		if err != nil {
+			return apperrors.Internal("Could not delete starthub", err)
 		}
 
 		// Return simple success message
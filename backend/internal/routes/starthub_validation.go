@@ -0,0 +1,130 @@
+package routes
+
+import (
+	"encoding/json"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+var emailPattern = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+
+// validateStartHub trims req and checks it the way it will be stored. Create,
+// PUT and PATCH all go through it, so a starthub can't be edited into a state
+// it couldn't be created in.
+func validateStartHub(req *models.CreateStartHubRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Description = strings.TrimSpace(req.Description)
+	req.Location = strings.TrimSpace(req.Location)
+	req.URL = strings.TrimSpace(req.URL)
+	req.Email = strings.TrimSpace(req.Email)
+
+	var fields []apperrors.FieldError
+	invalid := func(field, message string) {
+		fields = append(fields, apperrors.FieldError{Field: field, Message: message})
+	}
+
+	if req.Name == "" {
+		invalid("name", "Name is required")
+	}
+	if req.Email == "" {
+		invalid("email", "Email is required")
+	} else if !emailPattern.MatchString(req.Email) {
+		invalid("email", "Invalid email format")
+	}
+	if req.TeamSize < 0 {
+		invalid("team_size", "Team size can't be negative")
+	}
+	if req.URL != "" && !isValidURL(req.URL) {
+		invalid("url", "URL must be a valid http or https address")
+	}
+
+	if len(fields) > 0 {
+		return apperrors.Validation(fields...)
+	}
+	return nil
+}
+
+// startHubPatch is a JSON Merge Patch (RFC 7396) of a starthub. A field that is
+// left out keeps its value and a null resets it.
+type startHubPatch map[string]json.RawMessage
+
+// apply merges the patch into req and reports whether it changed the categories
+func (p startHubPatch) apply(req *models.CreateStartHubRequest) (bool, error) {
+	var fields []apperrors.FieldError
+	invalid := func(field, message string) {
+		fields = append(fields, apperrors.FieldError{Field: field, Message: message})
+	}
+
+	// Sorted so field errors come back in a stable order
+	for _, field := range slices.Sorted(maps.Keys(p)) {
+		raw := p[field]
+		var err error
+		switch field {
+		case "name":
+			err = setField(raw, &req.Name)
+		case "description":
+			err = setField(raw, &req.Description)
+		case "location":
+			err = setField(raw, &req.Location)
+		case "team_size":
+			err = setField(raw, &req.TeamSize)
+		case "url":
+			err = setField(raw, &req.URL)
+		case "email":
+			err = setField(raw, &req.Email)
+		case "categories":
+			err = setField(raw, &req.Categories)
+			if req.Categories == nil {
+				req.Categories = []string{}
+			}
+		case "id", "join_date", "image_url", "collaborating_starthubs", "external_collaborators":
+			invalid(field, "Field can't be changed")
+			continue
+		default:
+			invalid(field, "Unknown field")
+			continue
+		}
+
+		if err != nil {
+			invalid(field, "Invalid value")
+		}
+	}
+
+	if len(fields) > 0 {
+		return false, apperrors.Validation(fields...)
+	}
+
+	_, categories := p["categories"]
+	return categories, nil
+}
+
+// readMergePatch reads a JSON Merge Patch body, sent as such or as plain JSON
+func readMergePatch(c *fiber.Ctx) (map[string]json.RawMessage, error) {
+	contentType := strings.ToLower(strings.TrimSpace(strings.Split(c.Get(fiber.HeaderContentType), ";")[0]))
+	if contentType != "application/merge-patch+json" && contentType != fiber.MIMEApplicationJSON {
+		return nil, apperrors.UnsupportedMediaType("Send the patch as application/merge-patch+json")
+	}
+
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(c.Body(), &patch); err != nil || patch == nil {
+		return nil, apperrors.BadRequest("The patch must be a JSON object")
+	}
+
+	return patch, nil
+}
+
+// setField decodes a patch value into dst, null becomes the zero value
+func setField[T any](raw json.RawMessage, dst *T) error {
+	var value T
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return err
+	}
+	*dst = value
+	return nil
+}
@@ -0,0 +1,316 @@
+package routes
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/mail"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+	"github.com/ecetinerdem/starthub-backend/internal/totp"
+	"github.com/ecetinerdem/starthub-backend/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Two-factor authentication
+const (
+	totpIssuer       = "StartHub" // what authenticator apps list the codes under
+	mfaChallengeTTL  = 5 * time.Minute
+	maxMFAAttempts   = 5  // wrong codes one challenge takes before a new sign-in is needed
+	recoveryCodeSize = 10 // random bytes in a recovery code
+)
+
+// newRecoveryCodes returns fresh recovery codes, like "abcd-efgh-ijkl-mnop",
+// along with the hashes to store
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, models.RecoveryCodeCount)
+	hashes := make([]string, 0, models.RecoveryCodeCount)
+
+	for range models.RecoveryCodeCount {
+		raw := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw))
+
+		var groups []string
+		for i := 0; i < len(code); i += 4 {
+			groups = append(groups, code[i:min(i+4, len(code))])
+		}
+
+		codes = append(codes, strings.Join(groups, "-"))
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode hashes a recovery code however it was typed
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return utils.HashToken(code)
+}
+
+// checkSecondFactor reports whether code is a current code of the user's
+// authenticator or one of their recovery codes. Either works only once.
+func checkSecondFactor(c *fiber.Ctx, users repository.UserRepository, user models.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if code == "" || user.TOTPSecret == "" {
+		return false, nil
+	}
+
+	if step, ok := totp.Validate(user.TOTPSecret, code, time.Now()); ok {
+		return users.UseTOTPStep(c.Context(), user.ID, step)
+	}
+
+	return users.UseRecoveryCode(c.Context(), user.ID, hashRecoveryCode(code))
+}
+
+// startMFAChallenge answers a sign-in with the right password to an account
+// with two-factor authentication. No session exists until a code is given.
+func startMFAChallenge(c *fiber.Ctx, users repository.UserRepository, user models.User) error {
+	token, hash, err := utils.GenerateSecret()
+	if err != nil {
+		return apperrors.Internal("Could not start sign-in", err)
+	}
+
+	if err := users.CreateMFAChallenge(c.Context(), user.ID, hash, time.Now().Add(mfaChallengeTTL)); err != nil {
+		return apperrors.Internal("Could not start sign-in", err)
+	}
+
+	return c.JSON(models.MFAChallengeResponse{
+		MFARequired:    true,
+		ChallengeToken: token,
+		ExpiresIn:      int64(mfaChallengeTTL.Seconds()),
+	})
+}
+
+// CompleteMFA - Finishes a sign-in that is waiting for a second factor. Wrong
+// codes count as failed sign-ins, so guessing them ends in a lockout.
+func CompleteMFA(users repository.UserRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req models.MFARequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+
+		req.ChallengeToken = strings.TrimSpace(req.ChallengeToken)
+		if req.ChallengeToken == "" {
+			return apperrors.Field("challenge_token", "Challenge token is required")
+		}
+		if strings.TrimSpace(req.Code) == "" {
+			return apperrors.Field("code", "Code is required")
+		}
+
+		// Step 1: The challenge has to be live
+		challenge := utils.HashToken(req.ChallengeToken)
+		user, err := users.MFAChallengeUser(c.Context(), challenge, maxMFAAttempts)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperrors.Unauthorized("This sign-in has expired, sign in again")
+		}
+		if err != nil {
+			return apperrors.Internal("Could not sign in", err)
+		}
+
+		if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+			recordLoginAttempt(c, users, user.ID, models.LoginLocked)
+			return lockedOut(c, *user.LockedUntil)
+		}
+
+		// Step 2: Check the code, a wrong one counts against the challenge and the account
+		ok, err := checkSecondFactor(c, users, user, req.Code)
+		if err != nil {
+			return apperrors.Internal("Could not sign in", err)
+		}
+		if !ok {
+			recordLoginAttempt(c, users, user.ID, models.LoginWrongMFACode)
+			if err := users.FailMFAChallenge(c.Context(), challenge); err != nil {
+				return apperrors.Internal("Could not sign in", err)
+			}
+			if _, err := users.AddFailedLogin(c.Context(), user.ID, lockFor); err != nil {
+				return apperrors.Internal("Could not sign in", err)
+			}
+			return apperrors.Unauthorized("Invalid code")
+		}
+
+		// Step 3: Use up the challenge and start the session
+		if err := users.UseMFAChallenge(c.Context(), challenge); err != nil {
+			return dbError(err, "This sign-in was already completed")
+		}
+		if err := users.ClearFailedLogins(c.Context(), user.ID); err != nil {
+			return apperrors.Internal("Could not sign in", err)
+		}
+
+		response, err := startSession(c, users, user)
+		if err != nil {
+			return apperrors.Internal("Could not generate authentication token", err)
+		}
+
+		return c.JSON(response)
+	}
+}
+
+// SetupTwoFactor - Starts enrolling an authenticator for the signed-in user.
+// Nothing changes at sign-in until a code from it is confirmed.
+func SetupTwoFactor(users repository.UserRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("user_id").(string)
+
+		var req models.TwoFactorSetupRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+
+		user, err := users.GetByID(c.Context(), userID)
+		if err != nil {
+			return dbError(err, "User not found")
+		}
+		if user.TOTPEnabledAt != nil {
+			return apperrors.Conflict("Two-factor authentication is already on, turn it off first to use another authenticator")
+		}
+		if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)) != nil {
+			return apperrors.Field("password", "Password is incorrect")
+		}
+
+		secret, err := totp.NewSecret()
+		if err != nil {
+			return apperrors.Internal("Could not set up two-factor authentication", err)
+		}
+		if err := users.StartTOTPEnrollment(c.Context(), userID, secret); err != nil {
+			return dbError(err, "User not found")
+		}
+
+		return c.JSON(models.TwoFactorSetupResponse{
+			Secret:     secret,
+			OTPAuthURI: totp.URI(totpIssuer, user.Email, secret),
+		})
+	}
+}
+
+// ConfirmTwoFactor - Turns two-factor authentication on with a code from the
+// authenticator being enrolled, and hands out the recovery codes
+func ConfirmTwoFactor(users repository.UserRepository, mailer mail.Mailer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("user_id").(string)
+
+		var req models.TwoFactorCodeRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+
+		user, err := users.GetByID(c.Context(), userID)
+		if err != nil {
+			return dbError(err, "User not found")
+		}
+		if user.TOTPEnabledAt != nil {
+			return apperrors.Conflict("Two-factor authentication is already on")
+		}
+		if user.TOTPSecret == "" {
+			return apperrors.Conflict("Set up an authenticator first")
+		}
+
+		step, ok := totp.Validate(user.TOTPSecret, req.Code, time.Now())
+		if !ok {
+			return apperrors.Field("code", "Code is incorrect, check the clock of your device")
+		}
+
+		codes, hashes, err := newRecoveryCodes()
+		if err != nil {
+			return apperrors.Internal("Could not turn on two-factor authentication", err)
+		}
+		if err := users.EnableTOTP(c.Context(), userID, step, hashes); err != nil {
+			return dbError(err, "Set up an authenticator first")
+		}
+
+		sendMail(c, mailer, twoFactorChangedEmail(user.Email, true))
+
+		return c.JSON(models.RecoveryCodesResponse{RecoveryCodes: codes})
+	}
+}
+
+// RegenerateRecoveryCodes - Replaces every recovery code of the signed-in user,
+// after a code from their authenticator or a recovery code
+func RegenerateRecoveryCodes(users repository.UserRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("user_id").(string)
+
+		var req models.TwoFactorCodeRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+
+		user, err := users.GetByID(c.Context(), userID)
+		if err != nil {
+			return dbError(err, "User not found")
+		}
+		if user.TOTPEnabledAt == nil {
+			return apperrors.Conflict("Two-factor authentication is off")
+		}
+
+		ok, err := checkSecondFactor(c, users, user, req.Code)
+		if err != nil {
+			return apperrors.Internal("Could not create recovery codes", err)
+		}
+		if !ok {
+			return apperrors.Field("code", "Code is incorrect")
+		}
+
+		codes, hashes, err := newRecoveryCodes()
+		if err != nil {
+			return apperrors.Internal("Could not create recovery codes", err)
+		}
+		if err := users.ReplaceRecoveryCodes(c.Context(), userID, hashes); err != nil {
+			return dbError(err, "User not found")
+		}
+
+		return c.JSON(models.RecoveryCodesResponse{RecoveryCodes: codes})
+	}
+}
+
+// DisableTwoFactor - Turns two-factor authentication off for the signed-in
+// user, which takes both their password and a second factor
+func DisableTwoFactor(users repository.UserRepository, mailer mail.Mailer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("user_id").(string)
+
+		var req models.DisableTwoFactorRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+
+		user, err := users.GetByID(c.Context(), userID)
+		if err != nil {
+			return dbError(err, "User not found")
+		}
+		if user.TOTPEnabledAt == nil {
+			return apperrors.Conflict("Two-factor authentication is already off")
+		}
+		if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)) != nil {
+			return apperrors.Field("password", "Password is incorrect")
+		}
+
+		ok, err := checkSecondFactor(c, users, user, req.Code)
+		if err != nil {
+			return apperrors.Internal("Could not turn off two-factor authentication", err)
+		}
+		if !ok {
+			return apperrors.Field("code", "Code is incorrect")
+		}
+
+		if err := users.DisableTOTP(c.Context(), userID); err != nil {
+			return dbError(err, "User not found")
+		}
+
+		sendMail(c, mailer, twoFactorChangedEmail(user.Email, false))
+
+		return c.JSON(fiber.Map{
+			"message": "Two-factor authentication turned off",
+		})
+	}
+}
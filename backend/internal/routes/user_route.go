@@ -3,71 +3,56 @@ package routes
 import (
 	"log"
 
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/mail"
 	"github.com/ecetinerdem/starthub-backend/internal/models"
-	"github.com/ecetinerdem/starthub-backend/pkg/utils"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
 	"github.com/gofiber/fiber/v2"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func RegisterUser(db *pgxpool.Pool) fiber.Handler {
+// RegisterUser - Creates an account and signs it in. The email has to be
+// verified with the token sent to it before some actions are allowed.
+func RegisterUser(users repository.UserRepository, mailer mail.Mailer) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		var request models.RegisterUserRequest
 
 		if err := c.BodyParser(&request); err != nil {
 			log.Printf("❌ Could not parse registration request: %v", err)
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid registration data",
-			})
+			return apperrors.BadRequest("Invalid registration data")
 		}
 
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
 
 		if err != nil {
-			log.Printf("❌ Password hashing error: %v", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Could not process password",
-			})
+			return apperrors.Internal("Could not process password", err)
 		}
 
 		user := models.User{
 			Email:    request.Email,
 			Password: string(hashedPassword),
 			Role:     request.Role,
+			Settings: models.AccountSettings{PublicProfile: true},
 		}
 
-		query := `
-		INSERT INTO users (email, password, role)
-		VALUES ($1, $2, $3)
-		RETURNING id, created_at
-		`
-
-		err = db.QueryRow(c.Context(), query, user.Email, user.Password, user.Role).Scan(&user.ID, &user.CreatedAt)
+		user, err = users.Create(c.Context(), user)
 		if err != nil {
-			log.Printf("❌ Database error: %v", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Could not create user - email might already exist",
-			})
+			// An existing email becomes a 409
+			return dbError(err, "User not found")
+		}
+
+		// The account works without it, a failed email can be resent later
+		if err := sendVerification(c, users, mailer, user); err != nil {
+			log.Printf("⚠️ Could not send verification email to user %s: %v", user.ID, err)
 		}
 
-		//Generate JWT here
+		//Start a session and generate the token pair
 
-		token, err := utils.GenerateJWT(user)
+		response, err := startSession(c, users, user)
 		if err != nil {
-			log.Printf("❌ Could not generate JWT: %v", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "User created but could not generate authentication token",
-			})
+			return apperrors.Internal("User created but could not generate authentication token", err)
 		}
 
-		return c.Status(fiber.StatusCreated).JSON(models.AuthResponse{
-			User: models.UserResponse{
-				ID:        user.ID,
-				Email:     user.Email,
-				Role:      user.Role,
-				CreatedAt: user.CreatedAt,
-			},
-			Token: token,
-		})
+		return c.Status(fiber.StatusCreated).JSON(response)
 	}
 }
@@ -0,0 +1,102 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ecetinerdem/starthub-backend/internal/apperrors"
+	"github.com/ecetinerdem/starthub-backend/internal/mail"
+	"github.com/ecetinerdem/starthub-backend/internal/models"
+	"github.com/ecetinerdem/starthub-backend/internal/repository"
+	"github.com/ecetinerdem/starthub-backend/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Email verification
+const (
+	emailVerificationTTL = 48 * time.Hour
+	verificationInterval = time.Minute // between two verification emails to one user
+)
+
+// sendVerification emails a new verification token to the user. It returns a
+// *repository.ThrottledError when the previous one was sent too recently.
+func sendVerification(c *fiber.Ctx, users repository.UserRepository, mailer mail.Mailer, user models.User) error {
+	token, hash, err := utils.GenerateSecret()
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(emailVerificationTTL)
+	if err := users.CreateEmailVerification(c.Context(), user.ID, hash, expiresAt, verificationInterval); err != nil {
+		return err
+	}
+
+	sendMail(c, mailer, verificationEmail(user.Email, token, emailVerificationTTL))
+	return nil
+}
+
+// VerifyEmail - Confirms the email of an account with the token emailed to it
+func VerifyEmail(users repository.UserRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req models.VerifyEmailRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request")
+		}
+
+		req.Token = strings.TrimSpace(req.Token)
+		if req.Token == "" {
+			return apperrors.Field("token", "Verification token is required")
+		}
+
+		user, err := users.VerifyEmail(c.Context(), utils.HashToken(req.Token))
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperrors.Field("token", "This verification link is invalid, was already used or has expired")
+		}
+		if err != nil {
+			return apperrors.Internal("Could not verify email", err)
+		}
+
+		log.Printf("✅ Email of user %s verified", user.ID)
+
+		return c.JSON(fiber.Map{
+			"message": "Email verified",
+			"user":    models.NewUserResponse(user),
+		})
+	}
+}
+
+// ResendVerification - Emails the signed-in user a new verification token.
+// Earlier tokens keep working until one of them is used.
+func ResendVerification(users repository.UserRepository, mailer mail.Mailer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("user_id").(string)
+
+		user, err := users.GetByID(c.Context(), userID)
+		if err != nil {
+			return dbError(err, "User not found")
+		}
+		if user.EmailVerifiedAt != nil {
+			return apperrors.Conflict("Your email is already verified")
+		}
+
+		err = sendVerification(c, users, mailer, user)
+		var throttled *repository.ThrottledError
+		if errors.As(err, &throttled) {
+			seconds := int(math.Ceil(throttled.RetryAfter.Seconds()))
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+			return apperrors.TooManyRequests("A verification email was sent moments ago, check your inbox or try again later").
+				With("retry_after", seconds)
+		}
+		if err != nil {
+			return apperrors.Internal("Could not send verification email", err)
+		}
+
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"message": "A new verification link is on its way",
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// S3 stores files in a bucket of an S3-compatible service (AWS, MinIO, R2...).
+// Requests use path-style addresses and are signed with Signature Version 4.
+type S3 struct {
+	Endpoint        string // like https://s3.eu-central-1.amazonaws.com
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// PublicURL is where the bucket is readable, it defaults to Endpoint/Bucket
+	PublicURL string
+
+	Client *http.Client
+}
+
+func (s *S3) Put(ctx context.Context, key, contentType string, data []byte) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	header := http.Header{"Content-Type": {contentType}}
+	return s.do(ctx, http.MethodPut, key, header, data)
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	// S3 answers 204 whether or not the key existed, so this never fails on a missing key
+	return s.do(ctx, http.MethodDelete, key, nil, nil)
+}
+
+func (s *S3) URL(key string) string {
+	return s.publicURL() + "/" + escapeKey(key)
+}
+
+func (s *S3) Key(rawURL string) (string, bool) {
+	escaped, ok := strings.CutPrefix(rawURL, s.publicURL()+"/")
+	if !ok {
+		return "", false
+	}
+	key, err := url.PathUnescape(escaped)
+	return key, err == nil && validKey(key) == nil
+}
+
+func (s *S3) publicURL() string {
+	if s.PublicURL != "" {
+		return s.PublicURL
+	}
+	return s.Endpoint + "/" + s.Bucket
+}
+
+// do sends a signed request for the object under key
+func (s *S3) do(ctx context.Context, method, key string, header http.Header, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, s.Endpoint+"/"+s.Bucket+"/"+escapeKey(key), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	payloadHash := sha256.Sum256(body)
+	signV4(req, hex.EncodeToString(payloadHash[:]), s.AccessKeyID, s.SecretAccessKey, s.Region, time.Now())
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("s3 %s %s: status %d: %s", method, key, resp.StatusCode, message)
+	}
+
+	return nil
+}
+
+// escapeKey escapes each segment of a key for use in a URL path
+func escapeKey(key string) string {
+	parts := strings.Split(key, "/")
+	for i, part := range parts {
+		parts[i] = uriEncode(part)
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,78 @@
+// Package storage keeps uploaded files in a blob store, either on the local
+// disk or in an S3-compatible bucket.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// BlobStore stores files under slash separated keys and serves them publicly
+type BlobStore interface {
+	// Put stores data under key, replacing what was there
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// Delete removes the file under key, deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+	// URL is the public address of the file under key
+	URL(key string) string
+	// Key reverses URL, it reports false for URLs that aren't from this store
+	Key(url string) (string, bool)
+}
+
+// LocalRoute is where the app serves files of a Local store
+const LocalRoute = "/uploads"
+
+// FromEnv builds the blob store used by the server. STORAGE_DRIVER=s3 stores in
+// the S3_BUCKET bucket, anything else stores under UPLOAD_DIR on local disk.
+func FromEnv() (BlobStore, error) {
+	if strings.EqualFold(os.Getenv("STORAGE_DRIVER"), "s3") {
+		store := &S3{
+			Endpoint:        strings.TrimSuffix(os.Getenv("S3_ENDPOINT"), "/"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PublicURL:       strings.TrimSuffix(os.Getenv("S3_PUBLIC_URL"), "/"),
+		}
+		if store.Endpoint == "" || store.Bucket == "" || store.AccessKeyID == "" || store.SecretAccessKey == "" {
+			return nil, errors.New("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY must be set")
+		}
+		if store.Region == "" {
+			store.Region = "us-east-1"
+		}
+
+		log.Printf("✅ Storing uploads in bucket %s", store.Bucket)
+		return store, nil
+	}
+
+	dir := os.Getenv("UPLOAD_DIR")
+	if dir == "" {
+		dir = "uploads"
+	}
+
+	// Files are served by this app, so their URLs point back at it
+	baseURL := strings.TrimSuffix(os.Getenv("PUBLIC_URL"), "/")
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("http://localhost:%s", os.Getenv("PORT"))
+	}
+
+	log.Printf("✅ Storing uploads in %s", dir)
+	return &Local{Dir: dir, BaseURL: baseURL + LocalRoute}, nil
+}
+
+// validKey rejects keys that could escape the store
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid key %q", key)
+		}
+	}
+	return nil
+}
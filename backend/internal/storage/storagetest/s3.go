@@ -0,0 +1,103 @@
+// Package storagetest runs a local stand-in for an S3-compatible service, like
+// a tiny MinIO, so the S3 blob store can be tested without the network.
+package storagetest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Object is a file stored in the fake
+type Object struct {
+	ContentType string
+	Data        []byte
+}
+
+// S3 serves one bucket with path-style PUT, GET and DELETE. Requests must
+// carry a V4 Authorization header for AccessKeyID and a matching payload hash,
+// the signature itself isn't checked.
+type S3 struct {
+	*httptest.Server
+	Bucket      string
+	AccessKeyID string
+
+	mu      sync.Mutex
+	objects map[string]Object
+}
+
+// NewS3 starts a fake S3 service that is closed when the test ends
+func NewS3(t testing.TB, bucket, accessKeyID string) *S3 {
+	s := &S3{Bucket: bucket, AccessKeyID: accessKeyID, objects: map[string]Object{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Object returns the object stored under key
+func (s *S3) Object(key string) (Object, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.objects[key]
+	return o, ok
+}
+
+// Len returns the number of stored objects
+func (s *S3) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.objects)
+}
+
+func (s *S3) handle(w http.ResponseWriter, r *http.Request) {
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+s.Bucket+"/")
+	if !ok || key == "" {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+
+	// Reads are public, like a bucket behind a CDN
+	if r.Method == http.MethodGet {
+		s.mu.Lock()
+		o, ok := s.objects[key]
+		s.mu.Unlock()
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", o.ContentType)
+		w.Write(o.Data)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	hash := sha256.Sum256(body)
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential="+s.AccessKeyID+"/") ||
+		r.Header.Get("X-Amz-Date") == "" {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+		return
+	}
+	if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(hash[:]) {
+		http.Error(w, "XAmzContentSHA256Mismatch", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		s.objects[key] = Object{ContentType: r.Header.Get("Content-Type"), Data: body}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "MethodNotAllowed", http.StatusMethodNotAllowed)
+	}
+}
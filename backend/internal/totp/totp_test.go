@@ -0,0 +1,87 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ecetinerdem/starthub-backend/internal/totp"
+)
+
+// rfcSecret is the SHA-1 key of the RFC 6238 test vectors
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// The last 6 digits of the 8-digit codes in RFC 6238 appendix B
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := totp.Code(rfcSecret, totp.Step(time.Unix(tt.unix, 0)))
+		if err != nil || got != tt.code {
+			t.Errorf("code at %d: got %q, %v, want %q", tt.unix, got, err, tt.code)
+		}
+	}
+
+	if _, err := totp.Code("not base32!", 1); err == nil {
+		t.Error("an invalid secret gave a code")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := totp.NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 1, 1, 12, 0, 10, 0, time.UTC)
+	step := totp.Step(now)
+	code := func(step int64) string {
+		c, _ := totp.Code(secret, step)
+		return c
+	}
+
+	tests := []struct {
+		name string
+		code string
+		ok   bool
+		step int64
+	}{
+		{"current", code(step), true, step},
+		{"with spaces", code(step)[:3] + " " + code(step)[3:], true, step},
+		{"previous period", code(step - 1), true, step - 1},
+		{"next period", code(step + 1), true, step + 1},
+		{"too old", code(step - 2), false, 0},
+		{"too short", code(step)[:5], false, 0},
+		{"empty", "", false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := totp.Validate(secret, tt.code, now)
+			if ok != tt.ok || got != tt.step {
+				t.Errorf("got step %d, %v, want %d, %v", got, ok, tt.step, tt.ok)
+			}
+		})
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri, err := url.Parse(totp.URI("StartHub", "ada@example.com", "JBSWY3DPEHPK3PXP"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := uri.Query()
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/StartHub:ada@example.com" ||
+		query.Get("secret") != "JBSWY3DPEHPK3PXP" || query.Get("issuer") != "StartHub" || query.Get("digits") != "6" {
+		t.Errorf("got %s", uri)
+	}
+}
@@ -9,15 +9,18 @@ import (
 )
 
 var (
-	JWT_SECRET     = os.Getenv("JWT_SECRET")
-	TOKEN_DURATION = time.Hour * 24
+	JWT_SECRET             = os.Getenv("JWT_SECRET")
+	TOKEN_DURATION         = time.Minute * 15
+	REFRESH_TOKEN_DURATION = time.Hour * 24 * 30
 )
 
-func GenerateJWT(user models.User) (string, error) {
+// GenerateJWT issues a short-lived access token bound to the given session
+func GenerateJWT(user models.User, sessionID string) (string, error) {
 	claims := models.JWTClaims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   user.Role,
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(TOKEN_DURATION)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
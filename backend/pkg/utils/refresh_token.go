@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// Refresh tokens look like "<session id>.<secret>". Only the SHA-256 of the
+// secret is stored, so a database leak does not hand out usable tokens.
+
+// GenerateSecret returns a new random secret and the hash to store for it.
+// It backs refresh tokens and one-time tokens like team invitations.
+func GenerateSecret() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	secret := base64.RawURLEncoding.EncodeToString(buf)
+	return secret, HashToken(secret), nil
+}
+
+// FormatRefreshToken builds the opaque token handed to the client
+func FormatRefreshToken(sessionID, secret string) string {
+	return sessionID + "." + secret
+}
+
+// ParseRefreshToken splits a refresh token into its session id and secret
+func ParseRefreshToken(token string) (string, string, bool) {
+	sessionID, secret, found := strings.Cut(token, ".")
+	if !found || sessionID == "" || secret == "" {
+		return "", "", false
+	}
+	return sessionID, secret, true
+}
+
+// HashToken returns the hex encoded SHA-256 of a token secret
+func HashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// TokenHashMatches compares a secret against a stored hash in constant time
+func TokenHashMatches(secret, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashToken(secret)), []byte(hash)) == 1
+}